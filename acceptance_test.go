@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +23,10 @@ import (
 
 func TestAcceptance(t *testing.T) {
 	baseUrl := os.Getenv("BASE_URL")
+	apiKey := os.Getenv("API_KEY")
+	if baseUrl != "" && apiKey == "" {
+		t.Fatal("API_KEY must hold an admin:* key when BASE_URL is set")
+	}
 	if baseUrl == "" {
 		baseUrl = "http://localhost:5000"
 
@@ -31,6 +36,13 @@ func TestAcceptance(t *testing.T) {
 		defer testServer.Stop()
 		assertutil.NotError(t, err)
 
+		createAPIKey := exec.Command("bin/hashbang", "apikey", "create", "acceptance", "admin:*")
+		createAPIKey.Env = append(createAPIKey.Env, fmt.Sprintf("DATABASE_URL=%s", testServer.PGURL().String()))
+		output, err := createAPIKey.Output()
+		assertutil.NotError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		apiKey = lines[len(lines)-1]
+
 		command := exec.Command("bin/hashbang")
 		command.Env = append(command.Env, fmt.Sprintf("DATABASE_URL=%s", testServer.PGURL().String()))
 		stdout, err := command.StdoutPipe()
@@ -54,6 +66,8 @@ func TestAcceptance(t *testing.T) {
 		)
 	}
 
+	http.DefaultClient.Transport = &bearerTransport{apiKey, http.DefaultTransport}
+
 	t.Run("named tag list life cycle", func(t *testing.T) {
 		buckets := []string{"acceptance"}
 		t.Run("get named tag lists is empty", func(t *testing.T) {
@@ -330,6 +344,34 @@ func TestAcceptance(t *testing.T) {
 		}
 	})
 
+	t.Run("related tags follow named tag list changes", func(t *testing.T) {
+		buckets := []string{"suggest"}
+		namedTagList, err := createNamedTagList(
+			baseUrl,
+			buckets,
+			NamedTagList{Name: "beach", Tags: []string{"#windy", "#beach"}},
+		)
+		assertutil.NotError(t, err)
+
+		gotTagSuggestions, err := getRelatedTags(baseUrl, buckets, "#windy")
+		assertutil.NotError(t, err)
+		wantTagSuggestions := []TagSuggestion{{Tag: "#beach", Score: 1}}
+
+		if !reflect.DeepEqual(gotTagSuggestions, wantTagSuggestions) {
+			t.Errorf("got tag suggestions %+v want %+v", gotTagSuggestions, wantTagSuggestions)
+		}
+
+		assertutil.NotError(t, deleteNamedTagList(baseUrl, namedTagList.Id))
+
+		gotTagSuggestions, err = getRelatedTags(baseUrl, buckets, "#windy")
+		assertutil.NotError(t, err)
+		wantTagSuggestions = []TagSuggestion{}
+
+		if !reflect.DeepEqual(gotTagSuggestions, wantTagSuggestions) {
+			t.Errorf("got tag suggestions %+v want %+v", gotTagSuggestions, wantTagSuggestions)
+		}
+	})
+
 	t.Run("GET /version returns sha1 and version", func(t *testing.T) {
 		build, err := getVersion(baseUrl)
 		assertutil.NotError(t, err)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/arctair/hashbang/v1"
+)
+
+const usage = `usage:
+  hashbang apikey create NAME SCOPE...
+  hashbang apikey list
+  hashbang apikey revoke ID...
+
+scopes look like read:BUCKET, write:BUCKET or admin:BUCKET where BUCKET may be
+a glob such as blog-* or *`
+
+// runCommand handles the administrative subcommands, which act on the
+// database directly so the first admin key can be created without one.
+func runCommand(args []string, out io.Writer) error {
+	if len(args) < 2 || args[0] != "apikey" {
+		return errors.New(usage)
+	}
+
+	pool, err := connect(newLogger())
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	apiKeyRepository := v1.NewAPIKeyRepository(pool, v1.Timeouts{})
+	apiKeyService := v1.NewAPIKeyService(
+		apiKeyRepository,
+		v1.NewUUIDGenerator(),
+	)
+
+	switch args[1] {
+	case "create":
+		if len(args) < 4 {
+			return errors.New(usage)
+		}
+		created, err := apiKeyService.Create(ctx, args[2], args[3:])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s (%s); store this key now, it is not shown again:\n", created.ID, created.Name)
+		fmt.Fprintln(out, created.Key)
+	case "list":
+		apiKeys, err := apiKeyRepository.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, apiKey := range apiKeys {
+			lastUsed, revoked := "never", ""
+			if apiKey.LastUsedAt != nil {
+				lastUsed = apiKey.LastUsedAt.Format("2006-01-02T15:04:05Z07:00")
+			}
+			if apiKey.RevokedAt != nil {
+				revoked = " revoked"
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s…\t%s\tlast used %s%s\n", apiKey.ID, apiKey.Name, apiKey.Prefix, strings.Join(apiKey.Scopes, ","), lastUsed, revoked)
+		}
+	case "revoke":
+		if len(args) < 3 {
+			return errors.New(usage)
+		}
+		return apiKeyRepository.RevokeByIds(ctx, args[2:])
+	default:
+		return errors.New(usage)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -16,11 +17,27 @@ type NamedTagList struct {
 	Tags []string
 }
 
+type TagSuggestion struct {
+	Tag   string
+	Score float64
+}
+
 type Build struct {
 	Sha1    string
 	Version string
 }
 
+type bearerTransport struct {
+	apiKey string
+	next   http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "Bearer "+t.apiKey)
+	return t.next.RoundTrip(request)
+}
+
 func getNamedTagLists(baseUrl string, buckets []string) ([]NamedTagList, error) {
 	var (
 		err      error
@@ -155,6 +172,26 @@ func deleteNamedTagLists(baseUrl string, buckets []string) error {
 	return nil
 }
 
+func getRelatedTags(baseUrl string, buckets []string, tag string) ([]TagSuggestion, error) {
+	var (
+		err      error
+		response *http.Response
+	)
+
+	if response, err = http.Get(fmt.Sprintf("%s/tags/%s/related?%s", baseUrl, url.PathEscape(tag), queryString(buckets))); err != nil {
+		return nil, err
+	}
+
+	if err := assertStatusCode(response, 200); err != nil {
+		return nil, err
+	}
+
+	var tagSuggestions []TagSuggestion
+	defer response.Body.Close()
+	err = json.NewDecoder(response.Body).Decode(&tagSuggestions)
+	return tagSuggestions, err
+}
+
 func getVersion(baseUrl string) (*Build, error) {
 	var (
 		response *http.Response
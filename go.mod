@@ -9,7 +9,7 @@ require (
 	github.com/georgysavva/scany v0.2.7 // indirect
 	github.com/google/uuid v1.1.2
 	github.com/jackc/pgx/v4 v4.9.2
-	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897 // indirect
-	golang.org/x/text v0.3.4 // indirect
+	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897
+	golang.org/x/text v0.3.4
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
@@ -2,64 +2,398 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"sync"
+	"syscall"
+	"time"
 
 	v1 "github.com/arctair/hashbang/v1"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 var (
-	sha1    string
-	version string
+	sha1      string
+	version   string
+	buildTime string
 )
 
+func connect(logger v1.Logger) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.Connect(context.Background(), os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return nil, err
+	}
+	return pool, v1.Migrate(pool, logger)
+}
+
+// newLogger writes to stderr so that command output stays clean.
+func newLogger() v1.Logger {
+	level, err := v1.ParseLevel(getenv("LOG_LEVEL", "info"))
+	if err != nil {
+		panic(err)
+	}
+	return v1.NewLogger(os.Stderr, getenv("LOG_FORMAT", v1.FormatLogfmt), level)
+}
+
+func getenv(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getenvInt(name string, fallback int) int {
+	value, err := strconv.Atoi(getenv(name, strconv.Itoa(fallback)))
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", name, err))
+	}
+	return value
+}
+
+func getenvFloat(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(getenv(name, strconv.FormatFloat(fallback, 'f', -1, 64)), 64)
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", name, err))
+	}
+	return value
+}
+
+// newTracer exports spans to an OTLP/HTTP collector or appends them to a file,
+// and returns nil when neither is configured.
+func newTracer(logger v1.Logger) v1.Tracer {
+	serviceName := getenv("OTEL_SERVICE_NAME", "hashbang")
+	var exporter v1.SpanExporter
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		exporter = v1.NewOTLPSpanExporter(serviceName, endpoint)
+	} else if path := os.Getenv("TRACE_FILE"); path != "" {
+		exporter = v1.NewFileSpanExporter(serviceName, path)
+	} else {
+		return nil
+	}
+	return v1.NewTracer(exporter, logger, 5*time.Second)
+}
+
 // StartHTTPServer ...
 func StartHTTPServer(wg *sync.WaitGroup) *http.Server {
-	pool, err := pgxpool.Connect(context.Background(), os.Getenv("DATABASE_URL"))
+	logger := newLogger()
+	pool, err := connect(logger)
 	if err != nil {
 		panic(err)
 	}
 
-	if err = v1.Migrate(pool); err != nil {
+	tagDictionary, err := v1.LoadTagDictionary(os.Getenv("TAG_DICTIONARY"))
+	if err != nil {
 		panic(err)
 	}
 
-	namedTagListRepository := v1.NewNamedTagListRepository(
-		pool,
+	build := v1.NewBuild(sha1, version, buildTime)
+	metrics := v1.NewMetrics(build)
+	v1.InstrumentPool(metrics, pool)
+
+	queryTimeout, err := time.ParseDuration(getenv("QUERY_TIMEOUT", "5s"))
+	if err != nil {
+		panic(fmt.Errorf("QUERY_TIMEOUT: %w", err))
+	}
+	timeouts, err := v1.ParseTimeouts(queryTimeout, os.Getenv("QUERY_TIMEOUTS"))
+	if err != nil {
+		panic(fmt.Errorf("QUERY_TIMEOUTS: %w", err))
+	}
+
+	namedTagListRepository := v1.NewInstrumentedNamedTagListRepository(
+		v1.NewNamedTagListRepository(pool, timeouts),
+		metrics,
+	)
+	tagIndex := v1.NewTagIndex(namedTagListRepository)
+	namedTagListRepository = v1.NewIndexingNamedTagListRepository(
+		namedTagListRepository,
+		tagIndex,
+	)
+	bucketRepository := v1.NewBucketRepository(pool, timeouts)
+	unitOfWork := v1.NewUnitOfWork(pool, timeouts)
+	idempotencyKeyTTL, err := time.ParseDuration(getenv("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil {
+		panic(fmt.Errorf("IDEMPOTENCY_KEY_TTL: %w", err))
+	}
+	idGenerator, err := v1.NewUUIDGeneratorFor(getenv("ID_STRATEGY", v1.IDStrategyV4))
+	if err != nil {
+		panic(fmt.Errorf("ID_STRATEGY: %w", err))
+	}
+	namedTagListService := v1.NewNamedTagListService(
+		namedTagListRepository,
+		idGenerator,
+		bucketRepository,
+		v1.StorageQuota{
+			MaxNamedTagLists: getenvInt("QUOTA_MAX_NAMED_TAG_LISTS", 1000),
+			MaxTags:          getenvInt("QUOTA_MAX_TAGS", 30000),
+		},
+		unitOfWork,
+		v1.NewIdempotencyRepository(pool, timeouts, idempotencyKeyTTL),
+	)
+
+	rotationRepository := v1.NewRotationRepository(pool, timeouts)
+	tagMetadataRepository := v1.NewTagMetadataRepository(pool, timeouts)
+	postRepository := v1.NewPostRepository(pool, timeouts)
+	draftRepository := v1.NewDraftRepository(pool, timeouts)
+	apiKeyRepository := v1.NewAPIKeyRepository(pool, timeouts)
+	apiKeyService := v1.NewAPIKeyService(
+		apiKeyRepository,
+		v1.NewUUIDGenerator(),
+	)
+	userRepository := v1.NewUserRepository(pool, timeouts)
+	userService := v1.NewUserService(
+		userRepository,
+		v1.NewUUIDGenerator(),
 	)
 
-	server := &http.Server{
-		Addr: ":5000",
-		Handler: v1.NewRouter(
-			v1.NewNamedTagListController(
-				v1.NewLogger(),
+	shareSecret := []byte(os.Getenv("SHARE_SECRET"))
+	if len(shareSecret) == 0 {
+		// Share links then stop working whenever the server restarts.
+		shareSecret = make([]byte, 32)
+		if _, err = rand.Read(shareSecret); err != nil {
+			panic(err)
+		}
+	}
+	shareRepository := v1.NewShareRepository(pool, timeouts)
+	trustProxy := os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true"
+
+	var jwtVerifier v1.JWTVerifier
+	if jwksLocation := os.Getenv("JWT_JWKS"); jwksLocation != "" {
+		if os.Getenv("JWT_ISSUER") == "" || os.Getenv("JWT_AUDIENCE") == "" {
+			panic(fmt.Errorf("JWT_JWKS needs JWT_ISSUER and JWT_AUDIENCE"))
+		}
+		jwtVerifier = v1.NewJWTVerifier(
+			v1.JWTConfig{
+				Issuer:      os.Getenv("JWT_ISSUER"),
+				Audience:    os.Getenv("JWT_AUDIENCE"),
+				UserClaim:   getenv("JWT_USER_CLAIM", "sub"),
+				ScopesClaim: getenv("JWT_SCOPES_CLAIM", "hashbang_scopes"),
+			},
+			v1.NewJWKS(jwksLocation, time.Hour),
+		)
+	}
+
+	health := v1.NewHealthController(
+		v1.HealthChecks{
+			Readiness: map[string]v1.HealthCheck{
+				"database":   v1.PingCheck(pool),
+				"migrations": v1.SchemaCheck(pool),
+			},
+			Startup: map[string]v1.HealthCheck{
+				"migrations": v1.SchemaCheck(pool),
+			},
+		},
+		2*time.Second,
+	)
+
+	router := v1.NewRouter(
+		v1.NewNamedTagListController(
+			logger,
+			namedTagListRepository,
+			namedTagListService,
+			tagMetadataRepository,
+		),
+		v1.NewTagSuggestionController(
+			logger,
+			tagIndex,
+			v1.NewCaptionSuggestionService(
+				tagIndex,
+				tagDictionary,
+			),
+			namedTagListService,
+		),
+		v1.NewHousekeepingController(
+			logger,
+			v1.NewHousekeepingService(namedTagListRepository, unitOfWork),
+		),
+		v1.NewRotationController(
+			logger,
+			rotationRepository,
+			v1.NewRotationService(
+				rotationRepository,
+				namedTagListRepository,
+				v1.NewUUIDGenerator(),
+				v1.NewRandomSource(),
+			),
+		),
+		v1.NewTagMetadataController(
+			logger,
+			tagMetadataRepository,
+		),
+		v1.NewPostController(
+			logger,
+			postRepository,
+			v1.NewPostService(
+				postRepository,
+				namedTagListRepository,
+				v1.NewUUIDGenerator(),
+			),
+		),
+		v1.NewDraftController(
+			logger,
+			draftRepository,
+			v1.NewDraftService(
+				draftRepository,
+				namedTagListRepository,
+				v1.NewUUIDGenerator(),
+			),
+		),
+		v1.NewAPIKeyController(
+			logger,
+			apiKeyRepository,
+			apiKeyService,
+		),
+		v1.NewUserController(
+			logger,
+			userService,
+		),
+		v1.NewBucketController(
+			logger,
+			bucketRepository,
+			v1.NewBucketService(
+				bucketRepository,
+				userRepository,
+				unitOfWork,
+			),
+		),
+		v1.NewShareController(
+			logger,
+			shareRepository,
+			v1.NewShareService(
+				shareRepository,
 				namedTagListRepository,
-				v1.NewNamedTagListService(
-					namedTagListRepository,
-					v1.NewUUIDGenerator(),
-				),
+				v1.NewUUIDGenerator(),
+				shareSecret,
 			),
-			v1.NewVersionController(
-				v1.NewBuild(sha1, version),
+		),
+		v1.NewAuditController(
+			logger,
+			v1.NewAuditRepository(pool, timeouts),
+		),
+		v1.NewBatchController(
+			logger,
+			v1.NewBatchService(
+				namedTagListRepository,
+				namedTagListService,
+				unitOfWork,
 			),
 		),
+		v1.NewMetricsController(
+			logger,
+			metrics,
+		),
+		health,
+		v1.NewVersionController(
+			build,
+			func(ctx context.Context) (int, error) {
+				return v1.SchemaVersion(ctx, pool)
+			},
+		),
+	)
+
+	var handler http.Handler = v1.NewAuthenticator(
+		logger,
+		apiKeyService,
+		userService,
+		bucketRepository,
+		jwtVerifier,
+	).Handler(v1.NewRateLimiter(
+		v1.RateLimit{
+			Burst:     getenvInt("RATE_LIMIT_READ_BURST", 120),
+			PerSecond: getenvFloat("RATE_LIMIT_READ_PER_SECOND", 2),
+		},
+		v1.RateLimit{
+			Burst:     getenvInt("RATE_LIMIT_WRITE_BURST", 30),
+			PerSecond: getenvFloat("RATE_LIMIT_WRITE_PER_SECOND", 0.5),
+		},
+		trustProxy,
+	).Handler(router))
+	handler = v1.NewAddressRateLimiter(
+		v1.RateLimit{
+			Burst:     getenvInt("RATE_LIMIT_ADDRESS_BURST", 600),
+			PerSecond: getenvFloat("RATE_LIMIT_ADDRESS_PER_SECOND", 10),
+		},
+		trustProxy,
+	).Handler(handler)
+	handler = metrics.Handler(handler)
+	handler = v1.NewAccessLog(logger).Handler(handler)
+	tracer := newTracer(logger)
+	if tracer != nil {
+		handler = tracer.Handler(handler)
+	}
+	handler = v1.NewRequestTagger(trustProxy).Handler(handler)
+
+	server := &http.Server{
+		Addr:    ":5000",
+		Handler: handler,
 	}
 
+	drained := make(chan struct{})
 	go func() {
 		defer wg.Done()
 
 		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			log.Fatal(err)
+			logger.Error(err)
+			os.Exit(1)
 		}
+		<-drained
+	}()
+
+	go func() {
+		defer close(drained)
+		drain(logger, server, health, tracer)
 	}()
 
 	return server
 }
 
+// drain waits for a signal to stop, then fails readiness for SHUTDOWN_DRAIN so
+// that the load balancer stops sending requests before the server stops taking
+// them, and gives requests in flight SHUTDOWN_TIMEOUT to finish and their
+// spans to be exported.
+func drain(logger v1.Logger, server *http.Server, health v1.HealthController, tracer v1.Tracer) {
+	drainFor, err := time.ParseDuration(getenv("SHUTDOWN_DRAIN", "5s"))
+	if err != nil {
+		panic(fmt.Errorf("SHUTDOWN_DRAIN: %w", err))
+	}
+	timeout, err := time.ParseDuration(getenv("SHUTDOWN_TIMEOUT", "20s"))
+	if err != nil {
+		panic(fmt.Errorf("SHUTDOWN_TIMEOUT: %w", err))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	received := <-signals
+
+	logger.Info("draining", v1.F("signal", received.String()), v1.F("drain", drainFor))
+	health.Drain()
+	time.Sleep(drainFor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error(err)
+	}
+	if tracer != nil {
+		if err := tracer.Shutdown(ctx); err != nil {
+			logger.Error(err)
+		}
+	}
+	logger.Info("stopped")
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	serverExit := &sync.WaitGroup{}
 	serverExit.Add(1)
 	StartHTTPServer(serverExit)
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccessLog ...
+type AccessLog interface {
+	Handler(next http.Handler) http.Handler
+}
+
+type accessLog struct {
+	logger Logger
+	now    func() time.Time
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(bytes []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(bytes)
+	r.bytes += n
+	return n, err
+}
+
+// Handler logs one entry per request once it has been served, at warn level
+// for server errors and info otherwise.
+func (a *accessLog) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			start := a.now()
+			recorder := &responseRecorder{ResponseWriter: rw}
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			fields := []Field{
+				F("method", r.Method),
+				F("route", routeOf(r.URL.Path)),
+				F("status", recorder.status),
+				F("latency_ms", a.now().Sub(start)),
+				F("bytes", recorder.bytes),
+				F("request_id", RequestInfoFromContext(r.Context()).ID),
+				F("bucket", bucketsOf(r)),
+			}
+			if recorder.status >= http.StatusInternalServerError {
+				a.logger.Warn("request", fields...)
+			} else {
+				a.logger.Info("request", fields...)
+			}
+		},
+	)
+}
+
+// routes are the paths the router serves, with their variable segments as
+// placeholders.
+var routes = map[string]bool{
+	"/analytics/namedTagLists":        true,
+	"/analytics/tags":                 true,
+	"/apiKeys":                        true,
+	"/audit":                          true,
+	"/batch":                          true,
+	"/buckets":                        true,
+	"/buckets/{bucket}/members":       true,
+	"/buckets/{bucket}/namedTagLists": true,
+	"/debug/buildinfo":                true,
+	"/drafts":                         true,
+	"/drafts/{id}/render":             true,
+	"/healthz":                        true,
+	"/housekeeping":                   true,
+	"/housekeeping/merges":            true,
+	"/metrics":                        true,
+	"/namedTagLists":                  true,
+	"/namedTagLists/{id}":             true,
+	"/posts":                          true,
+	"/posts/import":                   true,
+	"/readyz":                         true,
+	"/rotations":                      true,
+	"/rotations/{id}/next":            true,
+	"/sessions":                       true,
+	"/shared/{token}":                 true,
+	"/shares":                         true,
+	"/startupz":                       true,
+	"/suggest":                        true,
+	"/suggest/fromCaption":            true,
+	"/tagMetadata":                    true,
+	"/tags/{tag}/related":             true,
+	"/users":                          true,
+	"/version":                        true,
+}
+
+// otherRoute stands for every path outside routes, so that made up paths
+// cannot each grow a new log route or metrics series.
+const otherRoute = "other"
+
+// routeOf replaces the variable segment of a path with a placeholder so that
+// requests for different resources log under the same route.
+func routeOf(path string) string {
+	segments := strings.Split(path, "/")
+	if len(segments) > 2 && segments[2] != "" {
+		switch segments[1] {
+		case "buckets":
+			segments[2] = "{bucket}"
+		case "shared":
+			segments[2] = "{token}"
+		case "tags":
+			segments[2] = "{tag}"
+		case "rotations", "drafts", "namedTagLists":
+			segments[2] = "{id}"
+		}
+	}
+	if route := strings.Join(segments, "/"); routes[route] {
+		return route
+	}
+	return otherRoute
+}
+
+func bucketsOf(r *http.Request) []string {
+	if route := routeOf(r.URL.Path); strings.HasPrefix(route, "/buckets/{bucket}") {
+		return strings.Split(r.URL.Path, "/")[2:3]
+	}
+	return r.URL.Query()["bucket"]
+}
+
+// NewAccessLog ...
+func NewAccessLog(logger Logger) AccessLog {
+	return &accessLog{logger, time.Now}
+}
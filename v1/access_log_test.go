@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	base := NewLogger(out, FormatLogfmt, LevelInfo)
+	base.(*logger).now = func() time.Time { return now }
+	log := &accessLog{base, func() time.Time { return now }}
+
+	for _, scenario := range []struct {
+		method  string
+		url     string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			http.MethodGet,
+			"/namedTagLists?bucket=blue&bucket=red",
+			func(w http.ResponseWriter, r *http.Request) {
+				now = now.Add(2 * time.Millisecond)
+				w.Write([]byte("[]\n"))
+			},
+			"time=2020-11-01T12:00:00.002Z level=info msg=request method=GET route=/namedTagLists status=200 latency_ms=2 bytes=3 request_id=abc bucket=blue,red\n",
+		},
+		{
+			http.MethodPost,
+			"/buckets/blue/members",
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			"time=2020-11-01T12:00:00.002Z level=warn msg=request method=POST route=/buckets/{bucket}/members status=500 latency_ms=0 bytes=0 request_id=abc bucket=blue\n",
+		},
+		{
+			http.MethodGet,
+			"/rotations/7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.WriteHeader(http.StatusOK)
+			},
+			"time=2020-11-01T12:00:00.002Z level=info msg=request method=GET route=other status=404 latency_ms=0 bytes=0 request_id=abc bucket=\"\"\n",
+		},
+	} {
+		t.Run(scenario.method+" "+scenario.url, func(t *testing.T) {
+			out.Reset()
+			request, _ := http.NewRequest(scenario.method, scenario.url, nil)
+			request = request.WithContext(WithRequestInfo(request.Context(), RequestInfo{ID: "abc"}))
+
+			log.Handler(scenario.handler).ServeHTTP(httptest.NewRecorder(), request)
+
+			if got := out.String(); got != scenario.want {
+				t.Errorf("got %s want %s", got, scenario.want)
+			}
+		})
+	}
+}
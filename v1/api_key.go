@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Permissions granted by an API key scope. Each permission implies the ones
+// ranked below it, so a write scope may also read.
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+var permissionRanks = map[string]int{
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionAdmin: 3,
+}
+
+// APIKey is the stored form of a key. The secret itself is never kept, only
+// its hash and a short prefix to tell keys apart.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// CreatedAPIKey carries the secret key back to the caller exactly once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyScope is a parsed scope such as "write:blog-*", granting a permission
+// on every bucket matching the glob.
+type APIKeyScope struct {
+	Permission string
+	Bucket     string
+}
+
+// ParseAPIKeyScope ...
+func ParseAPIKeyScope(scope string) (APIKeyScope, error) {
+	parts := strings.SplitN(scope, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return APIKeyScope{}, fmt.Errorf("scope %s must look like permission:bucket", scope)
+	}
+	if _, ok := permissionRanks[parts[0]]; !ok {
+		return APIKeyScope{}, fmt.Errorf("scope %s must grant read, write or admin", scope)
+	}
+	if _, err := path.Match(parts[1], ""); err != nil {
+		return APIKeyScope{}, fmt.Errorf("scope %s has a malformed bucket glob", scope)
+	}
+	return APIKeyScope{parts[0], parts[1]}, nil
+}
+
+// Grants reports whether the scope gives at least permission on bucket.
+func (s APIKeyScope) Grants(permission string, bucket string) bool {
+	if permissionRanks[s.Permission] < permissionRanks[permission] {
+		return false
+	}
+	matched, _ := path.Match(s.Bucket, bucket)
+	return matched
+}
+
+// Allows reports whether the key grants permission on every one of buckets.
+func (k APIKey) Allows(permission string, buckets []string) bool {
+	return scopesAllow(k.Scopes, permission, buckets)
+}
+
+// Covers reports whether the key's own scopes grant everything scope does, so
+// that the key may hand scope on to another key. A glob is only covered by a
+// scope on "*" or by the same glob.
+func (k APIKey) Covers(scope string) bool {
+	wanted, err := ParseAPIKeyScope(scope)
+	if err != nil {
+		return false
+	}
+	for _, rawScope := range k.Scopes {
+		own, err := ParseAPIKeyScope(rawScope)
+		if err != nil || permissionRanks[own.Permission] < permissionRanks[wanted.Permission] {
+			continue
+		}
+		if own.Bucket == "*" || own.Bucket == wanted.Bucket {
+			return true
+		} else if !strings.ContainsAny(wanted.Bucket, "*?[\\") && own.Grants(wanted.Permission, wanted.Bucket) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopesAllow reports whether scopes grant permission on every one of
+// buckets. Requests that name no bucket act on whatever bucket their ids
+// belong to, so they need a scope covering all buckets.
+func scopesAllow(rawScopes []string, permission string, buckets []string) bool {
+	scopes := []APIKeyScope{}
+	for _, scope := range rawScopes {
+		if parsed, err := ParseAPIKeyScope(scope); err == nil {
+			scopes = append(scopes, parsed)
+		}
+	}
+
+	if len(buckets) < 1 {
+		for _, scope := range scopes {
+			if scope.Bucket == "*" && scope.Grants(permission, "") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, bucket := range buckets {
+		granted := false
+		for _, scope := range scopes {
+			if scope.Grants(permission, bucket) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// APIKeyController ...
+type APIKeyController interface {
+	GetAPIKeys() http.Handler
+	CreateAPIKey() http.Handler
+	RevokeAPIKeys() http.Handler
+}
+
+type apiKeyController struct {
+	logger           Logger
+	apiKeyRepository APIKeyRepository
+	apiKeyService    APIKeyService
+}
+
+func (c *apiKeyController) GetAPIKeys() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			apiKeys, err := c.apiKeyRepository.FindAll(r.Context())
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(apiKeys)
+		},
+	)
+}
+
+func (c *apiKeyController) CreateAPIKey() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			var body struct {
+				Name   string   `json:"name"`
+				Scopes []string `json:"scopes"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if scope, ok := ungrantableScope(CallerFromContext(r.Context()), body.Scopes); !ok {
+				writeError(rw, http.StatusForbidden, "caller may not grant scope "+scope)
+			} else if created, err := c.apiKeyService.Create(r.Context(), body.Name, body.Scopes); errors.Is(err, ErrInvalidAPIKey) {
+				writeBadRequest(rw, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(created)
+			}
+		},
+	)
+}
+
+// ungrantableScope returns the first of scopes beyond the caller's own key.
+// Malformed scopes are left for the service to refuse.
+func ungrantableScope(caller *Caller, scopes []string) (string, bool) {
+	for _, scope := range scopes {
+		if _, err := ParseAPIKeyScope(scope); err != nil {
+			continue
+		}
+		if caller == nil || caller.APIKey == nil || !caller.APIKey.Covers(scope) {
+			return scope, false
+		}
+	}
+	return "", true
+}
+
+func (c *apiKeyController) RevokeAPIKeys() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) < 1 {
+				writeBadRequest(rw, "id query parameter is required")
+				return
+			}
+
+			if err := c.apiKeyRepository.RevokeByIds(r.Context(), ids); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+// NewAPIKeyController ...
+func NewAPIKeyController(
+	logger Logger,
+	apiKeyRepository APIKeyRepository,
+	apiKeyService APIKeyService,
+) APIKeyController {
+	return &apiKeyController{
+		logger,
+		apiKeyRepository,
+		apiKeyService,
+	}
+}
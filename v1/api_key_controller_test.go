@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubAPIKeyRepositoryForController struct {
+	APIKeyRepository
+
+	withIds   []string
+	willError bool
+
+	err error
+}
+
+func (r *stubAPIKeyRepositoryForController) FindAll(ctx context.Context) ([]APIKey, error) {
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	return []APIKey{{ID: "1", Name: "ci", Prefix: "hb_0123abcd", Scopes: []string{"read:*"}}}, nil
+}
+
+func (r *stubAPIKeyRepositoryForController) RevokeByIds(ctx context.Context, ids []string) error {
+	if !reflect.DeepEqual(ids, r.withIds) {
+		r.err = fmt.Errorf("Stub got ids %v want %v", ids, r.withIds)
+	}
+	if r.willError {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+type stubAPIKeyServiceForController struct {
+	APIKeyService
+
+	withName   string
+	withScopes []string
+	willError  error
+
+	err error
+}
+
+func (s *stubAPIKeyServiceForController) Create(ctx context.Context, name string, scopes []string) (*CreatedAPIKey, error) {
+	if name != s.withName || !reflect.DeepEqual(scopes, s.withScopes) {
+		s.err = fmt.Errorf("Stub got name %s want %s got scopes %v want %v", name, s.withName, scopes, s.withScopes)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &CreatedAPIKey{APIKey{ID: "1", Name: name, Scopes: scopes}, "hb_secret"}, nil
+}
+
+func TestAPIKeyController(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		controller := NewAPIKeyController(stubLoggerNew(), &stubAPIKeyRepositoryForController{}, &stubAPIKeyServiceForController{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/apiKeys", nil)
+		response := httptest.NewRecorder()
+		controller.GetAPIKeys().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotAPIKeys []APIKey
+		if err := json.NewDecoder(response.Body).Decode(&gotAPIKeys); err != nil {
+			t.Fatal(err)
+		}
+
+		wantAPIKeys := []APIKey{{ID: "1", Name: "ci", Prefix: "hb_0123abcd", Scopes: []string{"read:*"}}}
+
+		if !reflect.DeepEqual(gotAPIKeys, wantAPIKeys) {
+			t.Errorf("got api keys %+v want %+v", gotAPIKeys, wantAPIKeys)
+		}
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		service := &stubAPIKeyServiceForController{withName: "ci", withScopes: []string{"write:blog-*"}}
+		controller := NewAPIKeyController(stubLoggerNew(), &stubAPIKeyRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/apiKeys", strings.NewReader("{\"name\":\"ci\",\"scopes\":[\"write:blog-*\"]}"))
+		request = request.WithContext(WithCaller(request.Context(), &Caller{APIKey: &APIKey{Scopes: []string{"admin:*"}}}))
+		response := httptest.NewRecorder()
+		controller.CreateAPIKey().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotAPIKey CreatedAPIKey
+		if err := json.NewDecoder(response.Body).Decode(&gotAPIKey); err != nil {
+			t.Fatal(err)
+		}
+
+		if gotAPIKey.Key != "hb_secret" {
+			t.Errorf("got key %s want hb_secret", gotAPIKey.Key)
+		}
+	})
+
+	t.Run("POST invalid", func(t *testing.T) {
+		controller := NewAPIKeyController(
+			stubLoggerNew(),
+			&stubAPIKeyRepositoryForController{},
+			&stubAPIKeyServiceForController{willError: fmt.Errorf("%w: name must not be empty", ErrInvalidAPIKey)},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/apiKeys", strings.NewReader("{}"))
+		response := httptest.NewRecorder()
+		controller.CreateAPIKey().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := response.Body.String()
+		wantBody := "{\"error\":\"invalid api key: name must not be empty\"}\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("POST a scope beyond the caller's own", func(t *testing.T) {
+		for _, scenario := range []struct {
+			caller *Caller
+			scopes string
+			want   int
+		}{
+			{&Caller{APIKey: &APIKey{Scopes: []string{"admin:*"}}}, "[\"admin:*\",\"write:blog-*\"]", 201},
+			{&Caller{APIKey: &APIKey{Scopes: []string{"admin:blog-*"}}}, "[\"write:blog-travel\",\"read:blog-*\"]", 201},
+			{&Caller{APIKey: &APIKey{Scopes: []string{"admin:blog-*"}}}, "[\"admin:*\"]", 403},
+			{&Caller{APIKey: &APIKey{Scopes: []string{"admin:b?"}}}, "[\"read:b*\"]", 403},
+			{&Caller{APIKey: &APIKey{Scopes: []string{"write:*"}}}, "[\"admin:blue\"]", 403},
+			{&Caller{User: &User{ID: "2"}, Memberships: map[string]string{"mine": RoleOwner}}, "[\"admin:mine\"]", 403},
+			{nil, "[\"read:*\"]", 403},
+		} {
+			controller := NewAPIKeyController(stubLoggerNew(), &stubAPIKeyRepositoryForController{}, &stubAPIKeyServiceForController{})
+
+			request, _ := http.NewRequest(http.MethodPost, "/apiKeys", strings.NewReader("{\"name\":\"ci\",\"scopes\":"+scenario.scopes+"}"))
+			request = request.WithContext(WithCaller(request.Context(), scenario.caller))
+			response := httptest.NewRecorder()
+			controller.CreateAPIKey().ServeHTTP(response, request)
+
+			if got := response.Result().StatusCode; got != scenario.want {
+				t.Errorf("%+v granting %s: got status code %d want %d", scenario.caller, scenario.scopes, got, scenario.want)
+			}
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		repository := &stubAPIKeyRepositoryForController{withIds: []string{"1"}}
+		controller := NewAPIKeyController(stubLoggerNew(), repository, &stubAPIKeyServiceForController{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/apiKeys?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.RevokeAPIKeys().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE when repository has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewAPIKeyController(logger, &stubAPIKeyRepositoryForController{withIds: []string{"1"}, willError: true}, &stubAPIKeyServiceForController{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/apiKeys?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.RevokeAPIKeys().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// APIKeyRepository ...
+type APIKeyRepository interface {
+	FindAll(ctx context.Context) ([]APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*APIKey, error)
+	Create(ctx context.Context, apiKey APIKey, hash string) error
+	RevokeByIds(ctx context.Context, ids []string) error
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db database
+}
+
+const apiKeyColumns = "\"id\", \"name\", \"prefix\", \"scopes\", \"created_at\", \"last_used_at\", \"revoked_at\""
+
+func scanAPIKey(row pgx.Row, apiKey *APIKey) error {
+	return row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.Scopes,
+		&apiKey.CreatedAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt,
+	)
+}
+
+func (r *apiKeyRepository) FindAll(ctx context.Context) ([]APIKey, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindAll", "select "+apiKeyColumns+" from api_keys order by \"created_at\", \"id\""); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []APIKey{}
+
+	for rows.Next() {
+		var apiKey APIKey
+		if err = scanAPIKey(rows, &apiKey); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, hash string) (*APIKey, error) {
+	var apiKey APIKey
+	err := scanAPIKey(
+		r.db.queryRow(ctx, "FindByHash", "select "+apiKeyColumns+" from api_keys where \"hash\" = $1", hash),
+		&apiKey,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey APIKey, hash string) error {
+	return r.db.exec(
+		ctx,
+		"Create",
+		"insert into api_keys (\"id\", \"name\", \"prefix\", \"hash\", \"scopes\", \"created_at\") values ($1, $2, $3, $4, $5, $6)",
+		apiKey.ID,
+		apiKey.Name,
+		apiKey.Prefix,
+		hash,
+		apiKey.Scopes,
+		apiKey.CreatedAt,
+	)
+}
+
+func (r *apiKeyRepository) RevokeByIds(ctx context.Context, ids []string) error {
+	return r.db.exec(
+		ctx,
+		"RevokeByIds",
+		"update api_keys set \"revoked_at\" = now() where \"id\" = ANY($1) and \"revoked_at\" is null",
+		ids,
+	)
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	return r.db.exec(
+		ctx,
+		"Touch",
+		"update api_keys set \"last_used_at\" = $2 where \"id\" = $1",
+		id,
+		usedAt,
+	)
+}
+
+// NewAPIKeyRepository ...
+func NewAPIKeyRepository(pool *pgxpool.Pool, timeouts Timeouts) APIKeyRepository {
+	return &apiKeyRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	apiKey := APIKey{
+		ID:        "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		Name:      "ci",
+		Prefix:    "hb_0123abcd",
+		Scopes:    []string{"read:*", "write:blog-*"},
+		CreatedAt: time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("create api key", func(t *testing.T) {
+		assertutil.NotError(t, NewAPIKeyRepository(pool, Timeouts{}).Create(context.Background(), apiKey, "hash"))
+
+		got, err := NewAPIKeyRepository(pool, Timeouts{}).FindAll(context.Background())
+		assertutil.NotError(t, err)
+		for i := range got {
+			got[i].CreatedAt = got[i].CreatedAt.UTC()
+		}
+		want := []APIKey{apiKey}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("find api key by hash", func(t *testing.T) {
+		got, err := NewAPIKeyRepository(pool, Timeouts{}).FindByHash(context.Background(), "hash")
+		assertutil.NotError(t, err)
+
+		if got == nil || got.ID != apiKey.ID {
+			t.Errorf("got %+v want %+v", got, apiKey)
+		}
+
+		got, err = NewAPIKeyRepository(pool, Timeouts{}).FindByHash(context.Background(), "other")
+		assertutil.NotError(t, err)
+
+		if got != nil {
+			t.Errorf("got %+v want nil", got)
+		}
+	})
+
+	t.Run("touch api key", func(t *testing.T) {
+		usedAt := time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC)
+		assertutil.NotError(t, NewAPIKeyRepository(pool, Timeouts{}).Touch(context.Background(), apiKey.ID, usedAt))
+
+		got, err := NewAPIKeyRepository(pool, Timeouts{}).FindByHash(context.Background(), "hash")
+		assertutil.NotError(t, err)
+
+		if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) {
+			t.Errorf("got last used at %v want %v", got.LastUsedAt, usedAt)
+		}
+	})
+
+	t.Run("revoke api key by id", func(t *testing.T) {
+		assertutil.NotError(t, NewAPIKeyRepository(pool, Timeouts{}).RevokeByIds(context.Background(), []string{apiKey.ID}))
+
+		got, err := NewAPIKeyRepository(pool, Timeouts{}).FindByHash(context.Background(), "hash")
+		assertutil.NotError(t, err)
+
+		if got.RevokedAt == nil {
+			t.Errorf("got %+v want revoked", got)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubAPIKeyRepositoryForService struct {
+	APIKeyRepository
+
+	withAPIKey *APIKey
+	withHash   string
+	willError  bool
+
+	created     APIKey
+	createdHash string
+	touched     string
+}
+
+func (r *stubAPIKeyRepositoryForService) FindByHash(ctx context.Context, hash string) (*APIKey, error) {
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	if hash != r.withHash {
+		return nil, nil
+	}
+	return r.withAPIKey, nil
+}
+
+func (r *stubAPIKeyRepositoryForService) Create(ctx context.Context, apiKey APIKey, hash string) error {
+	r.created = apiKey
+	r.createdHash = hash
+	return nil
+}
+
+func (r *stubAPIKeyRepositoryForService) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	r.touched = id
+	return nil
+}
+
+func TestAPIKeyService(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		repository := &stubAPIKeyRepositoryForService{}
+		service := NewAPIKeyService(repository, &stubUUIDGenerator{response: "1"})
+
+		got, err := service.Create(context.Background(), "ci", []string{"write:blog-*"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(got.Key, "hb_") || len(got.Key) != 51 {
+			t.Errorf("got key %s want hb_ followed by 48 hex digits", got.Key)
+		}
+
+		if got.Prefix != got.Key[:11] {
+			t.Errorf("got prefix %s want %s", got.Prefix, got.Key[:11])
+		}
+
+		if !reflect.DeepEqual(repository.created, got.APIKey) {
+			t.Errorf("got created %+v want %+v", repository.created, got.APIKey)
+		}
+
+		if repository.createdHash != hashToken(got.Key) || strings.Contains(repository.createdHash, got.Key) {
+			t.Errorf("got created hash %s want hash of %s", repository.createdHash, got.Key)
+		}
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		for _, scenario := range []struct {
+			name    string
+			scopes  []string
+			wantErr string
+		}{
+			{name: "", scopes: []string{"read:*"}, wantErr: "invalid api key: name must not be empty"},
+			{name: "ci", scopes: []string{}, wantErr: "invalid api key: scopes must not be empty"},
+			{name: "ci", scopes: []string{"read"}, wantErr: "invalid api key: scope read must look like permission:bucket"},
+			{name: "ci", scopes: []string{"delete:*"}, wantErr: "invalid api key: scope delete:* must grant read, write or admin"},
+			{name: "ci", scopes: []string{"read:[blog"}, wantErr: "invalid api key: scope read:[blog has a malformed bucket glob"},
+		} {
+			service := NewAPIKeyService(&stubAPIKeyRepositoryForService{}, &stubUUIDGenerator{})
+
+			_, gotErr := service.Create(context.Background(), scenario.name, scenario.scopes)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("authenticate records last use", func(t *testing.T) {
+		repository := &stubAPIKeyRepositoryForService{
+			withAPIKey: &APIKey{ID: "1"},
+			withHash:   hashToken("hb_secret"),
+		}
+		service := NewAPIKeyService(repository, &stubUUIDGenerator{})
+
+		got, err := service.Authenticate(context.Background(), "hb_secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.LastUsedAt == nil || repository.touched != "1" {
+			t.Errorf("got last used at %v touched %s want key 1 touched", got.LastUsedAt, repository.touched)
+		}
+	})
+
+	t.Run("authenticate skips recording recent use", func(t *testing.T) {
+		lastUsedAt := time.Now().UTC()
+		repository := &stubAPIKeyRepositoryForService{
+			withAPIKey: &APIKey{ID: "1", LastUsedAt: &lastUsedAt},
+			withHash:   hashToken("hb_secret"),
+		}
+		service := NewAPIKeyService(repository, &stubUUIDGenerator{})
+
+		if _, err := service.Authenticate(context.Background(), "hb_secret"); err != nil {
+			t.Fatal(err)
+		}
+
+		if repository.touched != "" {
+			t.Errorf("got touched %s want none", repository.touched)
+		}
+	})
+
+	t.Run("authenticate rejects unknown and revoked keys", func(t *testing.T) {
+		revokedAt := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+		repository := &stubAPIKeyRepositoryForService{
+			withAPIKey: &APIKey{ID: "1", RevokedAt: &revokedAt},
+			withHash:   hashToken("hb_revoked"),
+		}
+		service := NewAPIKeyService(repository, &stubUUIDGenerator{})
+
+		for _, key := range []string{"secret", "hb_unknown", "hb_revoked"} {
+			if _, gotErr := service.Authenticate(context.Background(), key); gotErr != ErrUnknownAPIKey {
+				t.Errorf("got error %v for %s want %v", gotErr, key, ErrUnknownAPIKey)
+			}
+		}
+	})
+}
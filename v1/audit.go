@@ -0,0 +1,50 @@
+package v1
+
+import "time"
+
+// Audited actions on named tag lists.
+const (
+	AuditCreate  = "create"
+	AuditReplace = "replace"
+	AuditDelete  = "delete"
+	AuditMove    = "move"
+)
+
+// AuditEvent records one change to the named tag lists of a bucket along
+// with who made it. Before is empty for creations and After for deletions. A
+// move records an event in each bucket, with the list leaving one and
+// arriving in the other.
+type AuditEvent struct {
+	ID              string         `json:"id"`
+	At              time.Time      `json:"at"`
+	Actor           string         `json:"actor"`
+	RequestID       string         `json:"requestId"`
+	ClientIP        string         `json:"clientIp"`
+	Action          string         `json:"action"`
+	Bucket          string         `json:"bucket"`
+	NamedTagListIDs []string       `json:"namedTagListIds"`
+	Before          []NamedTagList `json:"before"`
+	After           []NamedTagList `json:"after"`
+}
+
+// AuditQuery selects the events of Buckets, optionally only those at or after
+// Since and those by Actor. After is the id of the last event already seen.
+type AuditQuery struct {
+	Buckets []string
+	Since   *time.Time
+	Actor   string
+	After   string
+	Limit   int
+}
+
+// Actor names the caller in audit events, such as apiKey:{id} or user:{id}.
+func (c *Caller) Actor() string {
+	if c == nil {
+		return ""
+	} else if c.APIKey != nil {
+		return "apiKey:" + c.APIKey.ID
+	} else if c.User != nil {
+		return "user:" + c.User.ID
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	uuid "github.com/google/uuid"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// AuditController ...
+type AuditController interface {
+	GetAuditEvents() http.Handler
+}
+
+type auditController struct {
+	logger          Logger
+	auditRepository AuditRepository
+}
+
+// GetAuditEvents pages through events oldest first, linking to the next page
+// while there may be more. With format=ndjson it instead streams every
+// matching event, one per line, for export.
+func (c *auditController) GetAuditEvents() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			values := r.URL.Query()
+			query := AuditQuery{
+				Buckets: values["bucket"],
+				Actor:   values.Get("actor"),
+				After:   values.Get("after"),
+			}
+			if len(query.Buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			}
+			if value := values.Get("since"); value != "" {
+				since, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					writeBadRequest(rw, "since must be an RFC 3339 timestamp")
+					return
+				}
+				query.Since = &since
+			}
+			if _, err := uuid.Parse(query.After); query.After != "" && err != nil {
+				writeBadRequest(rw, "after must be an audit event id")
+				return
+			}
+
+			if values.Get("format") == "ndjson" {
+				c.export(rw, r, query)
+				return
+			}
+
+			query.Limit = defaultAuditPageSize
+			if value := values.Get("limit"); value != "" {
+				var err error
+				if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit < 1 || query.Limit > maxAuditPageSize {
+					writeBadRequest(rw, "limit must be between 1 and "+strconv.Itoa(maxAuditPageSize))
+					return
+				}
+			}
+
+			events, err := c.auditRepository.Find(r.Context(), query)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			if len(events) == query.Limit {
+				values.Set("after", events[len(events)-1].ID)
+				rw.Header().Set("Link", "</audit?"+values.Encode()+">; rel=\"next\"")
+			}
+			json.NewEncoder(rw).Encode(events)
+		},
+	)
+}
+
+// export cannot report a failure once events have gone out, so it only logs.
+// It stops as soon as the client goes away.
+func (c *auditController) export(rw http.ResponseWriter, r *http.Request, query AuditQuery) {
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(rw)
+	if err := c.auditRepository.Each(r.Context(), query, func(event AuditEvent) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		return encoder.Encode(event)
+	}); err != nil && r.Context().Err() == nil {
+		c.logger.Error(err)
+	}
+}
+
+// NewAuditController ...
+func NewAuditController(logger Logger, auditRepository AuditRepository) AuditController {
+	return &auditController{
+		logger,
+		auditRepository,
+	}
+}
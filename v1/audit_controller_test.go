@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubAuditRepository struct {
+	withQuery AuditQuery
+	events    []AuditEvent
+
+	err error
+}
+
+func (r *stubAuditRepository) Find(ctx context.Context, query AuditQuery) ([]AuditEvent, error) {
+	if !reflect.DeepEqual(query, r.withQuery) {
+		r.err = fmt.Errorf("Stub got query %+v want %+v", query, r.withQuery)
+	}
+	return r.events, nil
+}
+
+func (r *stubAuditRepository) Each(ctx context.Context, query AuditQuery, fn func(AuditEvent) error) error {
+	events, _ := r.Find(ctx, query)
+	for _, event := range events {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAuditController(t *testing.T) {
+	at := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	event := AuditEvent{
+		ID:              "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		At:              at,
+		Actor:           "user:1",
+		RequestID:       "abc",
+		ClientIP:        "10.0.0.1",
+		Action:          AuditDelete,
+		Bucket:          "blue",
+		NamedTagListIDs: []string{"2"},
+		Before:          []NamedTagList{{ID: "2", Name: "beach", Tags: []string{"#sand"}}},
+		After:           []NamedTagList{},
+	}
+	eventJSON := "{\"id\":\"7fe6ca35-d868-48a9-94d4-6e7f7db450ea\",\"at\":\"2020-11-01T12:00:00Z\",\"actor\":\"user:1\",\"requestId\":\"abc\",\"clientIp\":\"10.0.0.1\",\"action\":\"delete\",\"bucket\":\"blue\",\"namedTagListIds\":[\"2\"],\"before\":[{\"id\":\"2\",\"name\":\"beach\",\"tags\":[\"#sand\"]}],\"after\":[]}\n"
+
+	t.Run("GET a page of events", func(t *testing.T) {
+		repository := &stubAuditRepository{
+			withQuery: AuditQuery{Buckets: []string{"blue"}, Since: &at, Actor: "user:1", Limit: 1},
+			events:    []AuditEvent{event},
+		}
+		request, _ := http.NewRequest(http.MethodGet, "/audit?bucket=blue&since=2020-11-01T12:00:00Z&actor=user:1&limit=1", nil)
+		response := httptest.NewRecorder()
+
+		NewAuditController(stubLoggerNew(), repository).GetAuditEvents().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Fatal(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotLink := response.Header().Get("Link")
+		wantLink := "</audit?actor=user%3A1&after=7fe6ca35-d868-48a9-94d4-6e7f7db450ea&bucket=blue&limit=1&since=2020-11-01T12%3A00%3A00Z>; rel=\"next\""
+
+		if gotLink != wantLink {
+			t.Errorf("got link %s want %s", gotLink, wantLink)
+		}
+
+		gotBody := response.Body.String()
+		wantBody := "[" + eventJSON[:len(eventJSON)-1] + "]\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("GET the last page", func(t *testing.T) {
+		repository := &stubAuditRepository{
+			withQuery: AuditQuery{Buckets: []string{"blue"}, After: event.ID, Limit: 100},
+			events:    []AuditEvent{},
+		}
+		request, _ := http.NewRequest(http.MethodGet, "/audit?bucket=blue&after="+event.ID, nil)
+		response := httptest.NewRecorder()
+
+		NewAuditController(stubLoggerNew(), repository).GetAuditEvents().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Fatal(repository.err)
+		}
+
+		if gotLink := response.Header().Get("Link"); gotLink != "" {
+			t.Errorf("got link %s want none", gotLink)
+		}
+
+		if gotBody, wantBody := response.Body.String(), "[]\n"; gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("GET an NDJSON export", func(t *testing.T) {
+		repository := &stubAuditRepository{
+			withQuery: AuditQuery{Buckets: []string{"blue"}},
+			events:    []AuditEvent{event, event},
+		}
+		request, _ := http.NewRequest(http.MethodGet, "/audit?bucket=blue&format=ndjson", nil)
+		response := httptest.NewRecorder()
+
+		NewAuditController(stubLoggerNew(), repository).GetAuditEvents().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Fatal(repository.err)
+		}
+
+		if gotContentType := response.Header().Get("Content-Type"); gotContentType != "application/x-ndjson" {
+			t.Errorf("got content type %s want application/x-ndjson", gotContentType)
+		}
+
+		if gotBody, wantBody := response.Body.String(), eventJSON+eventJSON; gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("stop an NDJSON export when the client goes away", func(t *testing.T) {
+		repository := &stubAuditRepository{
+			withQuery: AuditQuery{Buckets: []string{"blue"}},
+			events:    []AuditEvent{event, event},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/audit?bucket=blue&format=ndjson", nil)
+		response := httptest.NewRecorder()
+
+		NewAuditController(stubLoggerNew(), repository).GetAuditEvents().ServeHTTP(response, request)
+
+		if gotBody := response.Body.String(); gotBody != "" {
+			t.Errorf("got body %s want none", gotBody)
+		}
+	})
+
+	for _, scenario := range []struct {
+		url      string
+		wantBody string
+	}{
+		{"/audit", "{\"error\":\"bucket query parameter is required\"}\n"},
+		{"/audit?bucket=blue&since=yesterday", "{\"error\":\"since must be an RFC 3339 timestamp\"}\n"},
+		{"/audit?bucket=blue&after=1", "{\"error\":\"after must be an audit event id\"}\n"},
+		{"/audit?bucket=blue&limit=1001", "{\"error\":\"limit must be between 1 and 1000\"}\n"},
+	} {
+		t.Run("GET "+scenario.url, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, scenario.url, nil)
+			response := httptest.NewRecorder()
+
+			NewAuditController(stubLoggerNew(), &stubAuditRepository{}).GetAuditEvents().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			if gotBody := response.Body.String(); gotBody != scenario.wantBody {
+				t.Errorf("got body %s want %s", gotBody, scenario.wantBody)
+			}
+		})
+	}
+}
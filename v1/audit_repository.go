@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AuditRepository reads the audit log. Events are only ever written by the
+// repositories whose changes they record, in the same transaction.
+type AuditRepository interface {
+	Find(ctx context.Context, query AuditQuery) ([]AuditEvent, error)
+	Each(ctx context.Context, query AuditQuery, fn func(AuditEvent) error) error
+}
+
+type auditRepository struct {
+	db database
+}
+
+func (r *auditRepository) Find(ctx context.Context, query AuditQuery) ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	err := r.each(ctx, "Find", query, func(event AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Each streams events to fn for as long as ctx lasts, so that an export stops
+// when its client goes away.
+func (r *auditRepository) Each(ctx context.Context, query AuditQuery, fn func(AuditEvent) error) error {
+	return r.each(ctx, "Each", query, fn)
+}
+
+func (r *auditRepository) each(ctx context.Context, operation string, query AuditQuery, fn func(AuditEvent) error) error {
+	sql := "select \"id\", \"at\", \"actor\", \"request_id\", \"client_ip\", \"action\", \"bucket\", \"named_tag_list_ids\", \"before\", \"after\" from audit_events where \"bucket\" = ANY($1)"
+	args := []interface{}{query.Buckets}
+	if query.Since != nil {
+		args = append(args, *query.Since)
+		sql += " and \"at\" >= $" + strconv.Itoa(len(args))
+	}
+	if query.Actor != "" {
+		args = append(args, query.Actor)
+		sql += " and \"actor\" = $" + strconv.Itoa(len(args))
+	}
+	if query.After != "" {
+		args = append(args, query.After)
+		sql += " and (\"at\", \"id\") > (select \"at\", \"id\" from audit_events where \"id\" = $" + strconv.Itoa(len(args)) + ")"
+	}
+	sql += " order by \"at\", \"id\""
+	if query.Limit > 0 {
+		sql += " limit " + strconv.Itoa(query.Limit)
+	}
+
+	rows, err := r.db.query(ctx, operation, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event AuditEvent
+		if err = rows.Scan(
+			&event.ID,
+			&event.At,
+			&event.Actor,
+			&event.RequestID,
+			&event.ClientIP,
+			&event.Action,
+			&event.Bucket,
+			&event.NamedTagListIDs,
+			&event.Before,
+			&event.After,
+		); err != nil {
+			return err
+		}
+		if err = fn(event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// NewAuditRepository ...
+func NewAuditRepository(pool *pgxpool.Pool, timeouts Timeouts) AuditRepository {
+	return &auditRepository{database{pool, timeouts}}
+}
+
+// auditLog collects the events of one write, one per bucket it touched.
+type auditLog struct {
+	ctx    context.Context
+	action string
+	events map[string]*AuditEvent
+}
+
+func newAuditLog(ctx context.Context, action string) *auditLog {
+	return &auditLog{ctx, action, map[string]*AuditEvent{}}
+}
+
+func (l *auditLog) event(bucket string) *AuditEvent {
+	event, ok := l.events[bucket]
+	if !ok {
+		info := RequestInfoFromContext(l.ctx)
+		event = &AuditEvent{
+			Actor:           CallerFromContext(l.ctx).Actor(),
+			RequestID:       info.ID,
+			ClientIP:        info.ClientIP,
+			Action:          l.action,
+			Bucket:          bucket,
+			NamedTagListIDs: []string{},
+			Before:          []NamedTagList{},
+			After:           []NamedTagList{},
+		}
+		l.events[bucket] = event
+	}
+	return event
+}
+
+// before notes the state of a list ahead of the write and after its state
+// following it.
+func (l *auditLog) before(bucket string, namedTagList NamedTagList) {
+	event := l.event(bucket)
+	event.NamedTagListIDs = append(event.NamedTagListIDs, namedTagList.ID)
+	event.Before = append(event.Before, namedTagList)
+}
+
+func (l *auditLog) after(bucket string, namedTagList NamedTagList) {
+	event := l.event(bucket)
+	if l.action == AuditCreate || l.action == AuditMove {
+		event.NamedTagListIDs = append(event.NamedTagListIDs, namedTagList.ID)
+	}
+	event.After = append(event.After, namedTagList)
+}
+
+func (l *auditLog) write(tx pgx.Tx) error {
+	buckets := []string{}
+	for bucket := range l.events {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+
+	for _, bucket := range buckets {
+		event := l.events[bucket]
+		if err := tracedExec(
+			l.ctx,
+			tx,
+			"insert into audit_events (\"actor\", \"request_id\", \"client_ip\", \"action\", \"bucket\", \"named_tag_list_ids\", \"before\", \"after\") values ($1, $2, $3, $4, $5, $6, $7, $8)",
+			event.Actor,
+			event.RequestID,
+			event.ClientIP,
+			event.Action,
+			event.Bucket,
+			event.NamedTagListIDs,
+			event.Before,
+			event.After,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestAuditRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	start := time.Now().Add(-time.Minute)
+	ctx := WithRequestInfo(
+		WithCaller(context.Background(), &Caller{User: &User{ID: "1"}}),
+		RequestInfo{"abc", "10.0.0.1"},
+	)
+	namedTagListRepository := NewNamedTagListRepository(pool, Timeouts{})
+	beach := NamedTagList{ID: "7fe6ca35-d868-48a9-94d4-6e7f7db450ea", Name: "beach", Tags: []string{"#sand"}}
+	replaced := NamedTagList{ID: beach.ID, Name: "beach", Tags: []string{"#sea"}}
+	assertutil.NotError(t, namedTagListRepository.Create(ctx, "blue", beach))
+	assertutil.NotError(t, namedTagListRepository.Create(context.Background(), "red", NamedTagList{ID: "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4", Name: "city", Tags: []string{}}))
+	assertutil.NotError(t, namedTagListRepository.ReplaceByIds(ctx, []string{beach.ID}, replaced))
+	assertutil.NotError(t, namedTagListRepository.DeleteAll(ctx, []string{"blue"}))
+
+	t.Run("find events of a bucket in order", func(t *testing.T) {
+		events, err := NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue"}})
+		assertutil.NotError(t, err)
+
+		got := []AuditEvent{}
+		for _, event := range events {
+			if event.At.Before(start) {
+				t.Errorf("got event at %s before %s", event.At, start)
+			}
+			event.ID, event.At = "", time.Time{}
+			got = append(got, event)
+		}
+		want := []AuditEvent{
+			{Actor: "user:1", RequestID: "abc", ClientIP: "10.0.0.1", Action: AuditCreate, Bucket: "blue", NamedTagListIDs: []string{beach.ID}, Before: []NamedTagList{}, After: []NamedTagList{beach}},
+			{Actor: "user:1", RequestID: "abc", ClientIP: "10.0.0.1", Action: AuditReplace, Bucket: "blue", NamedTagListIDs: []string{beach.ID}, Before: []NamedTagList{beach}, After: []NamedTagList{replaced}},
+			{Actor: "user:1", RequestID: "abc", ClientIP: "10.0.0.1", Action: AuditDelete, Bucket: "blue", NamedTagListIDs: []string{beach.ID}, Before: []NamedTagList{replaced}, After: []NamedTagList{}},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("filter events by actor", func(t *testing.T) {
+		events, err := NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue", "red"}})
+		assertutil.NotError(t, err)
+		if len(events) != 4 {
+			t.Errorf("got %d events want 4", len(events))
+		}
+
+		events, err = NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue", "red"}, Actor: "user:1"})
+		assertutil.NotError(t, err)
+		if len(events) != 3 {
+			t.Errorf("got %d events want 3", len(events))
+		}
+	})
+
+	t.Run("page through events", func(t *testing.T) {
+		all, err := NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue"}})
+		assertutil.NotError(t, err)
+
+		got, err := NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue"}, After: all[0].ID, Limit: 1})
+		assertutil.NotError(t, err)
+
+		if !reflect.DeepEqual(got, all[1:2]) {
+			t.Errorf("got %+v want %+v", got, all[1:2])
+		}
+	})
+
+	t.Run("find no events since later", func(t *testing.T) {
+		since := time.Now().Add(time.Minute)
+		got, err := NewAuditRepository(pool, Timeouts{}).Find(context.Background(), AuditQuery{Buckets: []string{"blue"}, Since: &since})
+		assertutil.NotError(t, err)
+
+		if len(got) != 0 {
+			t.Errorf("got %+v want none", got)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Authenticator ...
+type Authenticator interface {
+	Handler(next http.Handler) http.Handler
+}
+
+type authenticator struct {
+	logger           Logger
+	apiKeyService    APIKeyService
+	userService      UserService
+	bucketRepository BucketRepository
+	jwtVerifier      JWTVerifier
+}
+
+var (
+	publicRoutes = map[string]bool{
+		"GET /version":   true,
+		"GET /metrics":   true,
+		"GET /healthz":   true,
+		"GET /readyz":    true,
+		"GET /startupz":  true,
+		"POST /users":    true,
+		"POST /sessions": true,
+	}
+	// authenticatedRoutes need a caller but act on no bucket in particular.
+	authenticatedRoutes = map[string]bool{
+		"GET /buckets":         true,
+		"GET /debug/buildinfo": true,
+		"POST /buckets":        true,
+		"POST /batch":          true,
+		"DELETE /sessions":     true,
+	}
+)
+
+// Handler requires a bearer API key, session token or JWT on every request
+// outside publicRoutes and share links. Reads need the read permission, every
+// other method needs write, and the /shares and /audit endpoints and bucket
+// membership changes need admin.
+// The permission is checked against each bucket the request names or whose
+// resources it addresses by id. Buckets a read names but may not see are
+// dropped from the request rather than refused.
+// /apiKeys acts on no bucket, whatever the query says, so it needs an API key
+// with admin on every bucket; no session or JWT may manage keys.
+func (a *authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + r.URL.Path
+			if publicRoutes[route] || r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/shared/") {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || token == r.Header.Get("Authorization") {
+				writeError(rw, http.StatusUnauthorized, "a bearer api key, session token or jwt is required")
+				return
+			}
+
+			caller, err := a.authenticate(r.Context(), token)
+			if errors.Is(err, ErrUnknownAPIKey) || errors.Is(err, ErrUnknownSession) || errors.Is(err, ErrInvalidToken) {
+				writeError(rw, http.StatusUnauthorized, err.Error())
+				return
+			} else if err != nil {
+				writeServerError(rw, r, a.logger, err)
+				return
+			}
+
+			if r.URL.Path == "/apiKeys" {
+				if !caller.ManagesAPIKeys() {
+					writeForbidden(rw, PermissionAdmin)
+					return
+				}
+			} else if !authenticatedRoutes[route] {
+				permission := requiredPermission(r)
+				query := r.URL.Query()
+				named := query["bucket"]
+				addressed, err := a.addressedBuckets(r)
+				if err != nil {
+					writeServerError(rw, r, a.logger, err)
+					return
+				}
+
+				if permission == PermissionRead && len(named) > 0 {
+					readable := caller.Readable(named)
+					if len(readable) < 1 || len(addressed) > 0 && !caller.Allows(permission, addressed) {
+						writeForbidden(rw, permission)
+						return
+					}
+					query["bucket"] = readable
+					r.URL.RawQuery = query.Encode()
+				} else if !caller.Allows(permission, append(named, addressed...)) {
+					writeForbidden(rw, permission)
+					return
+				}
+			}
+
+			next.ServeHTTP(rw, r.WithContext(WithCaller(r.Context(), caller)))
+		},
+	)
+}
+
+func (a *authenticator) authenticate(ctx context.Context, token string) (*Caller, error) {
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		apiKey, err := a.apiKeyService.Authenticate(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return &Caller{APIKey: apiKey}, nil
+	} else if !strings.HasPrefix(token, sessionPrefix) && a.jwtVerifier != nil {
+		return a.jwtVerifier.Verify(ctx, token)
+	}
+
+	user, err := a.userService.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := a.bucketRepository.FindMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	caller := &Caller{User: user, Memberships: map[string]string{}}
+	for _, membership := range memberships {
+		caller.Memberships[membership.Bucket] = membership.Role
+	}
+	return caller, nil
+}
+
+// addressedBuckets returns the bucket named in a /buckets/{bucket}/ path and
+// the buckets holding any resources the request addresses by id.
+func (a *authenticator) addressedBuckets(r *http.Request) ([]string, error) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if segments[0] == "buckets" && len(segments) > 1 {
+		return []string{segments[1]}, nil
+	}
+
+	ids := r.URL.Query()["id"]
+	if len(segments) > 1 && segments[1] != "" {
+		ids = append(ids, segments[1])
+	}
+	if len(ids) < 1 {
+		return nil, nil
+	}
+	return a.bucketRepository.FindBucketsByIds(r.Context(), segments[0], ids)
+}
+
+// readOnly reports whether a request only reads. Suggestions are posted for
+// their bodies, and write only when asked to create a list.
+func readOnly(r *http.Request) bool {
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return true
+	case r.Method != http.MethodPost:
+		return false
+	case r.URL.Path == "/suggest":
+		return true
+	case r.URL.Path == "/suggest/fromCaption":
+		return r.URL.Query().Get("create") != "true"
+	}
+	return false
+}
+
+func requiredPermission(r *http.Request) string {
+	if r.URL.Path == "/shares" || r.URL.Path == "/audit" {
+		return PermissionAdmin
+	} else if readOnly(r) {
+		return PermissionRead
+	} else if strings.HasPrefix(r.URL.Path, "/buckets/") && !strings.HasSuffix(r.URL.Path, "/namedTagLists") {
+		return PermissionAdmin
+	}
+	return PermissionWrite
+}
+
+func writeForbidden(rw http.ResponseWriter, permission string) {
+	writeError(rw, http.StatusForbidden, "caller lacks "+permission+" permission for this request")
+}
+
+// NewAuthenticator takes a nil jwtVerifier when no issuer is configured.
+func NewAuthenticator(
+	logger Logger,
+	apiKeyService APIKeyService,
+	userService UserService,
+	bucketRepository BucketRepository,
+	jwtVerifier JWTVerifier,
+) Authenticator {
+	return &authenticator{
+		logger,
+		apiKeyService,
+		userService,
+		bucketRepository,
+		jwtVerifier,
+	}
+}
@@ -0,0 +1,301 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type stubAPIKeyService struct {
+	APIKeyService
+
+	withKey    string
+	withAPIKey *APIKey
+	willError  error
+}
+
+func (s *stubAPIKeyService) Authenticate(ctx context.Context, key string) (*APIKey, error) {
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	if key != s.withKey {
+		return nil, ErrUnknownAPIKey
+	}
+	return s.withAPIKey, nil
+}
+
+type stubUserServiceForAuthenticator struct {
+	UserService
+
+	withToken string
+	withUser  *User
+}
+
+func (s *stubUserServiceForAuthenticator) Authenticate(ctx context.Context, token string) (*User, error) {
+	if token != s.withToken {
+		return nil, ErrUnknownSession
+	}
+	return s.withUser, nil
+}
+
+type stubJWTVerifier struct {
+	withToken  string
+	withCaller *Caller
+}
+
+func (v *stubJWTVerifier) Verify(ctx context.Context, token string) (*Caller, error) {
+	if token != v.withToken {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+	return v.withCaller, nil
+}
+
+type stubBucketRepositoryForAuthenticator struct {
+	BucketRepository
+
+	withMemberships []BucketMember
+	withBuckets     map[string]string
+}
+
+func (r *stubBucketRepositoryForAuthenticator) FindMemberships(ctx context.Context, userID string) ([]BucketMember, error) {
+	return r.withMemberships, nil
+}
+
+func (r *stubBucketRepositoryForAuthenticator) FindBucketsByIds(ctx context.Context, resource string, ids []string) ([]string, error) {
+	buckets := []string{}
+	for _, id := range ids {
+		if bucket, ok := r.withBuckets[resource+"/"+id]; ok {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets, nil
+}
+
+func TestAuthenticator(t *testing.T) {
+	apiKey := &APIKey{ID: "1", Scopes: []string{"read:*", "write:blog-*"}}
+	user := &User{ID: "2", Email: "someone@example.com"}
+	jwtCaller := &Caller{User: &User{ID: "subject"}, Scopes: []string{"write:blog-*"}}
+	var (
+		gotCaller *Caller
+		gotQuery  string
+	)
+	next := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gotCaller = CallerFromContext(r.Context())
+			gotQuery = r.URL.RawQuery
+			w.Write([]byte("the next handler body"))
+		},
+	)
+	forbidden := func(permission string) string {
+		return fmt.Sprintf("{\"error\":\"caller lacks %s permission for this request\"}\n", permission)
+	}
+
+	for _, scenario := range []struct {
+		method         string
+		url            string
+		authorization  string
+		wantStatusCode int
+		wantBody       string
+		wantQuery      string
+	}{
+		{http.MethodGet, "/version", "", 200, "the next handler body", ""},
+		{http.MethodPost, "/sessions", "", 200, "the next handler body", ""},
+		{http.MethodGet, "/metrics", "", 200, "the next handler body", ""},
+		{http.MethodGet, "/readyz", "", 200, "the next handler body", ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "", 401, "{\"error\":\"a bearer api key, session token or jwt is required\"}\n", ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "hb_secret", 401, "{\"error\":\"a bearer api key, session token or jwt is required\"}\n", ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "Bearer hb_other", 401, "{\"error\":\"unknown or revoked api key\"}\n", ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "Bearer hs_other", 401, "{\"error\":\"unknown or expired session\"}\n", ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "Bearer hb_secret", 200, "the next handler body", "bucket=red"},
+		{http.MethodPost, "/namedTagLists?bucket=blog-travel", "Bearer hb_secret", 200, "the next handler body", "bucket=blog-travel"},
+		{http.MethodPost, "/namedTagLists?bucket=blog-travel&bucket=red", "Bearer hb_secret", 403, forbidden("write"), ""},
+		{http.MethodDelete, "/namedTagLists?id=1", "Bearer hb_secret", 403, forbidden("write"), ""},
+		{http.MethodDelete, "/namedTagLists?id=3", "Bearer hb_secret", 200, "the next handler body", "id=3"},
+		{http.MethodPut, "/namedTagLists/1?bucket=blog-travel", "Bearer hb_secret", 403, forbidden("write"), ""},
+		{http.MethodPut, "/namedTagLists/3?bucket=blog-travel", "Bearer hb_secret", 200, "the next handler body", "bucket=blog-travel"},
+		{http.MethodPut, "/buckets/blog-travel/namedTagLists", "Bearer hb_secret", 200, "the next handler body", ""},
+		{http.MethodPut, "/buckets/red/namedTagLists", "Bearer hb_secret", 403, forbidden("write"), ""},
+		{http.MethodGet, "/apiKeys", "Bearer hb_secret", 403, forbidden("admin"), ""},
+		{http.MethodGet, "/apiKeys?bucket=blog-travel", "Bearer hb_secret", 403, forbidden("admin"), ""},
+		{http.MethodGet, "/namedTagLists?bucket=blue&bucket=green&bucket=red", "Bearer hs_secret", 200, "the next handler body", "bucket=blue&bucket=red"},
+		{http.MethodGet, "/namedTagLists?bucket=green", "Bearer hs_secret", 403, forbidden("read"), ""},
+		{http.MethodGet, "/namedTagLists", "Bearer hs_secret", 403, forbidden("read"), ""},
+		{http.MethodPost, "/namedTagLists?bucket=blue", "Bearer hs_secret", 200, "the next handler body", "bucket=blue"},
+		{http.MethodPost, "/namedTagLists?bucket=red", "Bearer hs_secret", 403, forbidden("write"), ""},
+		{http.MethodDelete, "/namedTagLists?id=1", "Bearer hs_secret", 403, forbidden("write"), ""},
+		{http.MethodDelete, "/namedTagLists?id=2", "Bearer hs_secret", 200, "the next handler body", "id=2"},
+		{http.MethodGet, "/drafts/1/render", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodGet, "/buckets", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodGet, "/debug/buildinfo", "", 401, "{\"error\":\"a bearer api key, session token or jwt is required\"}\n", ""},
+		{http.MethodGet, "/debug/buildinfo", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodPost, "/batch", "", 401, "{\"error\":\"a bearer api key, session token or jwt is required\"}\n", ""},
+		{http.MethodPost, "/batch", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodGet, "/buckets/red/members", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodPost, "/buckets/red/members", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodPost, "/buckets/blue/members", "Bearer hs_secret", 200, "the next handler body", ""},
+		{http.MethodGet, "/apiKeys", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodGet, "/apiKeys?bucket=blue", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodPost, "/apiKeys?bucket=blue", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodDelete, "/apiKeys?bucket=blue&id=1", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodGet, "/shared/abc.def", "", 200, "the next handler body", ""},
+		{http.MethodGet, "/shares?bucket=blue", "Bearer hs_secret", 200, "the next handler body", "bucket=blue"},
+		{http.MethodPost, "/shares?bucket=red", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodGet, "/audit?bucket=blue", "Bearer hs_secret", 200, "the next handler body", "bucket=blue"},
+		{http.MethodGet, "/audit?bucket=red", "Bearer hs_secret", 403, forbidden("admin"), ""},
+		{http.MethodPost, "/suggest?bucket=red", "Bearer hb_secret", 200, "the next handler body", "bucket=red"},
+		{http.MethodPost, "/suggest/fromCaption?bucket=red", "Bearer hb_secret", 200, "the next handler body", "bucket=red"},
+		{http.MethodPost, "/suggest/fromCaption?bucket=red&create=true", "Bearer hb_secret", 403, forbidden("write"), ""},
+		{http.MethodPost, "/suggest/fromCaption?bucket=blog-travel&create=true", "Bearer hb_secret", 200, "the next handler body", "bucket=blog-travel&create=true"},
+		{http.MethodPost, "/suggest?bucket=red", "Bearer hs_secret", 200, "the next handler body", "bucket=red"},
+		{http.MethodPost, "/suggest/fromCaption?bucket=red&create=true", "Bearer hs_secret", 403, forbidden("write"), ""},
+		{http.MethodGet, "/namedTagLists?bucket=red", "Bearer x.y.z", 401, "{\"error\":\"invalid bearer token: bad signature\"}\n", ""},
+		{http.MethodPost, "/namedTagLists?bucket=blog-travel", "Bearer a.b.c", 200, "the next handler body", "bucket=blog-travel"},
+		{http.MethodGet, "/namedTagLists?bucket=blog-travel&bucket=red", "Bearer a.b.c", 200, "the next handler body", "bucket=blog-travel"},
+	} {
+		gotCaller, gotQuery = nil, ""
+		authenticator := NewAuthenticator(
+			stubLoggerNew(),
+			&stubAPIKeyService{withKey: "hb_secret", withAPIKey: apiKey},
+			&stubUserServiceForAuthenticator{withToken: "hs_secret", withUser: user},
+			&stubBucketRepositoryForAuthenticator{
+				withMemberships: []BucketMember{
+					{Bucket: "blue", UserID: "2", Role: RoleOwner},
+					{Bucket: "red", UserID: "2", Role: RoleViewer},
+				},
+				withBuckets: map[string]string{
+					"namedTagLists/1": "red",
+					"namedTagLists/2": "blue",
+					"namedTagLists/3": "blog-travel",
+					"drafts/1":        "red",
+				},
+			},
+			&stubJWTVerifier{withToken: "a.b.c", withCaller: jwtCaller},
+		)
+
+		request, _ := http.NewRequest(scenario.method, scenario.url, nil)
+		if scenario.authorization != "" {
+			request.Header.Set("Authorization", scenario.authorization)
+		}
+		response := httptest.NewRecorder()
+		authenticator.Handler(next).ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+
+		if gotStatusCode != scenario.wantStatusCode {
+			t.Errorf("%s %s %s: got status code %d want %d", scenario.method, scenario.url, scenario.authorization, gotStatusCode, scenario.wantStatusCode)
+		}
+
+		gotBody := response.Body.String()
+
+		if gotBody != scenario.wantBody {
+			t.Errorf("%s %s %s: got body %q want %q", scenario.method, scenario.url, scenario.authorization, gotBody, scenario.wantBody)
+		}
+
+		if gotQuery != scenario.wantQuery {
+			t.Errorf("%s %s %s: got query %q want %q", scenario.method, scenario.url, scenario.authorization, gotQuery, scenario.wantQuery)
+		}
+
+		if scenario.wantStatusCode == 200 && scenario.authorization == "Bearer hb_secret" && gotCaller.APIKey != apiKey {
+			t.Errorf("%s %s: got caller %+v want api key %+v", scenario.method, scenario.url, gotCaller, apiKey)
+		}
+
+		if scenario.wantStatusCode == 200 && scenario.authorization == "Bearer a.b.c" && gotCaller != jwtCaller {
+			t.Errorf("%s %s: got caller %+v want %+v", scenario.method, scenario.url, gotCaller, jwtCaller)
+		}
+
+		if scenario.wantStatusCode == 200 && scenario.authorization == "Bearer hs_secret" && gotCaller.User != user {
+			t.Errorf("%s %s: got caller %+v want user %+v", scenario.method, scenario.url, gotCaller, user)
+		}
+	}
+
+	t.Run("admin scope grants every permission", func(t *testing.T) {
+		admin := APIKey{Scopes: []string{"admin:*"}}
+		for _, permission := range []string{PermissionRead, PermissionWrite, PermissionAdmin} {
+			if !admin.Allows(permission, nil) {
+				t.Errorf("got %s denied want allowed", permission)
+			}
+		}
+	})
+
+	t.Run("only an admin key on every bucket manages api keys", func(t *testing.T) {
+		for _, scopes := range [][]string{{"admin:*"}, {"admin:blue"}} {
+			authenticator := NewAuthenticator(
+				stubLoggerNew(),
+				&stubAPIKeyService{withKey: "hb_secret", withAPIKey: &APIKey{Scopes: scopes}},
+				&stubUserServiceForAuthenticator{},
+				&stubBucketRepositoryForAuthenticator{},
+				nil,
+			)
+
+			request, _ := http.NewRequest(http.MethodPost, "/apiKeys?bucket=blue", nil)
+			request.Header.Set("Authorization", "Bearer hb_secret")
+			response := httptest.NewRecorder()
+			authenticator.Handler(next).ServeHTTP(response, request)
+
+			want := 403
+			if scopes[0] == "admin:*" {
+				want = 200
+			}
+			if got := response.Result().StatusCode; got != want {
+				t.Errorf("%v: got status code %d want %d", scopes, got, want)
+			}
+		}
+	})
+
+	t.Run("sessions and jwts never manage api keys", func(t *testing.T) {
+		authenticator := NewAuthenticator(
+			stubLoggerNew(),
+			&stubAPIKeyService{},
+			&stubUserServiceForAuthenticator{withToken: "hs_secret", withUser: user},
+			&stubBucketRepositoryForAuthenticator{
+				withMemberships: []BucketMember{{Bucket: "mine", UserID: "2", Role: RoleOwner}},
+			},
+			&stubJWTVerifier{withToken: "a.b.c", withCaller: &Caller{User: &User{ID: "subject"}, Scopes: []string{"admin:*"}}},
+		)
+
+		for _, authorization := range []string{"Bearer hs_secret", "Bearer a.b.c"} {
+			for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+				request, _ := http.NewRequest(method, "/apiKeys?bucket=mine&id=1", nil)
+				request.Header.Set("Authorization", authorization)
+				response := httptest.NewRecorder()
+				authenticator.Handler(next).ServeHTTP(response, request)
+
+				if got := response.Result().StatusCode; got != 403 {
+					t.Errorf("%s %s: got status code %d want 403", method, authorization, got)
+				}
+			}
+		}
+	})
+
+	t.Run("service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		authenticator := NewAuthenticator(
+			logger,
+			&stubAPIKeyService{willError: errors.New("there was an error")},
+			&stubUserServiceForAuthenticator{},
+			&stubBucketRepositoryForAuthenticator{},
+			nil,
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists?bucket=red", nil)
+		request.Header.Set("Authorization", "Bearer hb_secret")
+		response := httptest.NewRecorder()
+		authenticator.Handler(next).ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// BatchController ...
+type BatchController interface {
+	Batch() http.Handler
+}
+
+type batchController struct {
+	logger       Logger
+	batchService BatchService
+}
+
+// Batch answers 200 once the batch commits. A batch that fails takes the
+// status of the operation that failed it.
+func (c *batchController) Batch() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			var (
+				batch    Batch
+				response *BatchResponse
+				err      error
+			)
+			if json.NewDecoder(r.Body).Decode(&batch) != nil {
+				writeBadRequest(rw, "body must be a batch of operations")
+			} else if response, err = c.batchService.Apply(r.Context(), batch); errors.Is(err, ErrInvalidBatch) {
+				writeBadRequest(rw, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.Header().Set("Content-Type", "application/json")
+				if !response.Committed {
+					rw.WriteHeader(response.Results[len(response.Results)-1].Status)
+				}
+				json.NewEncoder(rw).Encode(response)
+			}
+		},
+	)
+}
+
+// NewBatchController ...
+func NewBatchController(
+	logger Logger,
+	batchService BatchService,
+) BatchController {
+	return &batchController{
+		logger,
+		batchService,
+	}
+}
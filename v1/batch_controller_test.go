@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubBatchService struct {
+	withBatch   Batch
+	willRespond *BatchResponse
+	willError   error
+
+	err error
+}
+
+func (s *stubBatchService) Apply(ctx context.Context, batch Batch) (*BatchResponse, error) {
+	if !reflect.DeepEqual(batch, s.withBatch) {
+		s.err = fmt.Errorf("Stub got batch %+v want %+v", batch, s.withBatch)
+	}
+	return s.willRespond, s.willError
+}
+
+func TestBatchController(t *testing.T) {
+	body := "{\"continueOnError\":true,\"operations\":[{\"op\":\"delete\",\"id\":\"1\"}]}"
+	batch := Batch{ContinueOnError: true, Operations: []BatchOperation{{Op: BatchDelete, ID: "1"}}}
+
+	for _, scenario := range []struct {
+		name           string
+		body           string
+		willRespond    *BatchResponse
+		willError      error
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			"committed",
+			body,
+			&BatchResponse{true, []BatchResult{{Status: 204}}},
+			nil,
+			200,
+			"{\"committed\":true,\"results\":[{\"status\":204}]}\n",
+		},
+		{
+			"failed",
+			body,
+			&BatchResponse{false, []BatchResult{{Status: 204}, {Status: 404, Error: "named tag list not found: 2"}}},
+			nil,
+			404,
+			"{\"committed\":false,\"results\":[{\"status\":204},{\"status\":404,\"error\":\"named tag list not found: 2\"}]}\n",
+		},
+		{
+			"invalid",
+			body,
+			nil,
+			fmt.Errorf("%w: operations must not be empty", ErrInvalidBatch),
+			400,
+			"{\"error\":\"invalid batch: operations must not be empty\"}\n",
+		},
+		{
+			"not json",
+			"[",
+			nil,
+			nil,
+			400,
+			"{\"error\":\"body must be a batch of operations\"}\n",
+		},
+		{
+			"database busy",
+			body,
+			nil,
+			ErrDatabaseBusy,
+			503,
+			"",
+		},
+	} {
+		service := &stubBatchService{withBatch: batch, willRespond: scenario.willRespond, willError: scenario.willError}
+		controller := NewBatchController(stubLoggerNew(), service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/batch", strings.NewReader(scenario.body))
+		response := httptest.NewRecorder()
+		controller.Batch().ServeHTTP(response, request)
+
+		if service.err != nil && scenario.body == body {
+			t.Errorf("%s: %s", scenario.name, service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		if gotStatusCode != scenario.wantStatusCode {
+			t.Errorf("%s: got status code %d want %d", scenario.name, gotStatusCode, scenario.wantStatusCode)
+		}
+
+		if gotBody := response.Body.String(); scenario.wantBody != "" && gotBody != scenario.wantBody {
+			t.Errorf("%s: got body %s want %s", scenario.name, gotBody, scenario.wantBody)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Batch operations on named tag lists.
+const (
+	BatchCreate  = "create"
+	BatchReplace = "replace"
+	BatchPatch   = "patch"
+	BatchDelete  = "delete"
+	BatchMove    = "move"
+)
+
+// MaxBatchOperations ...
+const MaxBatchOperations = 100
+
+var (
+	// ErrInvalidBatch ...
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrNamedTagListNotFound ...
+	ErrNamedTagListNotFound = errors.New("named tag list not found")
+	// ErrForbidden ...
+	ErrForbidden = errors.New("caller lacks write permission for this operation")
+
+	// errBatchFailed rolls back a batch that stops at its first failure.
+	errBatchFailed = errors.New("batch failed")
+)
+
+// BatchOperation is one step of a batch. Create takes Bucket, Name, Tags and
+// optionally the ID to give the list; replace takes ID, Name and Tags; patch
+// takes ID and either of Name and Tags; delete takes ID; and move takes ID and
+// the Bucket to move to.
+type BatchOperation struct {
+	Op     string   `json:"op"`
+	ID     string   `json:"id,omitempty"`
+	Bucket string   `json:"bucket,omitempty"`
+	Name   *string  `json:"name,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Batch runs its operations in order in one transaction. Unless
+// ContinueOnError is set, the first failure undoes every operation.
+type Batch struct {
+	Operations      []BatchOperation `json:"operations"`
+	ContinueOnError bool             `json:"continueOnError"`
+}
+
+// BatchResult is the outcome of one operation with the HTTP status it would
+// have had as a request of its own.
+type BatchResult struct {
+	Status       int           `json:"status"`
+	NamedTagList *NamedTagList `json:"namedTagList,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// BatchResponse holds a result for each operation attempted. A batch that was
+// not committed stops at the operation that failed.
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchService ...
+type BatchService interface {
+	Apply(ctx context.Context, batch Batch) (*BatchResponse, error)
+}
+
+type batchService struct {
+	namedTagListRepository NamedTagListRepository
+	namedTagListService    NamedTagListService
+	unitOfWork             UnitOfWork
+}
+
+func (s *batchService) Apply(ctx context.Context, batch Batch) (*BatchResponse, error) {
+	if err := validateBatch(batch); err != nil {
+		return nil, err
+	}
+
+	ctx, span := StartSpan(ctx, "BatchService.Apply", F("operations", len(batch.Operations)))
+	var response *BatchResponse
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		response = &BatchResponse{Committed: true, Results: []BatchResult{}}
+		for _, operation := range batch.Operations {
+			var (
+				result BatchResult
+				failed error
+				err    error
+			)
+			if batch.ContinueOnError {
+				failed, err = s.unitOfWork.Savepoint(ctx, func(ctx context.Context) (err error) {
+					result, err = s.apply(ctx, operation)
+					return err
+				})
+			} else {
+				result, failed = s.apply(ctx, operation)
+			}
+			if err != nil {
+				return err
+			} else if failed != nil {
+				status := batchStatus(failed)
+				if status == http.StatusInternalServerError {
+					return failed
+				}
+				result = BatchResult{Status: status, Error: failed.Error()}
+			}
+
+			response.Results = append(response.Results, result)
+			if failed != nil && !batch.ContinueOnError {
+				response.Committed = false
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		err = nil
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *batchService) apply(ctx context.Context, operation BatchOperation) (BatchResult, error) {
+	if operation.Op == BatchCreate {
+		if !allowsWrite(ctx, operation.Bucket) {
+			return BatchResult{}, ErrForbidden
+		}
+		created, err := s.namedTagListService.Create(ctx, operation.Bucket, NamedTagList{ID: operation.ID, Name: *operation.Name, Tags: tagsOrEmpty(operation.Tags)})
+		if err != nil {
+			return BatchResult{}, err
+		}
+		return BatchResult{Status: http.StatusCreated, NamedTagList: created}, nil
+	}
+
+	found, err := s.namedTagListRepository.FindByIds(ctx, []string{operation.ID})
+	if err != nil {
+		return BatchResult{}, err
+	} else if len(found) < 1 {
+		return BatchResult{}, fmt.Errorf("%w: %s", ErrNamedTagListNotFound, operation.ID)
+	}
+	existing := found[0]
+	if !allowsWrite(ctx, existing.Bucket) || operation.Op == BatchMove && !allowsWrite(ctx, operation.Bucket) {
+		return BatchResult{}, ErrForbidden
+	}
+
+	namedTagList := existing.NamedTagList
+	switch operation.Op {
+	case BatchReplace:
+		namedTagList = NamedTagList{ID: existing.ID, Name: *operation.Name, Tags: tagsOrEmpty(operation.Tags)}
+	case BatchPatch:
+		if operation.Name != nil {
+			namedTagList.Name = *operation.Name
+		}
+		if operation.Tags != nil {
+			namedTagList.Tags = operation.Tags
+		}
+	case BatchDelete:
+		if err = s.namedTagListRepository.DeleteByIds(ctx, []string{existing.ID}); err != nil {
+			return BatchResult{}, err
+		}
+		return BatchResult{Status: http.StatusNoContent}, nil
+	case BatchMove:
+		if err = s.namedTagListService.Move(ctx, existing.ID, operation.Bucket); err != nil {
+			return BatchResult{}, err
+		}
+		return BatchResult{Status: http.StatusOK, NamedTagList: &namedTagList}, nil
+	}
+
+	if err = s.namedTagListService.Replace(ctx, []string{existing.ID}, namedTagList); err != nil {
+		return BatchResult{}, err
+	}
+	return BatchResult{Status: http.StatusOK, NamedTagList: &namedTagList}, nil
+}
+
+// validateBatch checks the shape of every operation before any runs so that
+// a malformed batch is refused as a whole.
+func validateBatch(batch Batch) error {
+	if len(batch.Operations) < 1 {
+		return fmt.Errorf("%w: operations must not be empty", ErrInvalidBatch)
+	} else if len(batch.Operations) > MaxBatchOperations {
+		return fmt.Errorf("%w: no more than %d operations are allowed", ErrInvalidBatch, MaxBatchOperations)
+	}
+
+	for i, operation := range batch.Operations {
+		var problem string
+		switch operation.Op {
+		case BatchCreate:
+			if operation.Bucket == "" || operation.Name == nil {
+				problem = "create needs a bucket and a name"
+			}
+		case BatchReplace:
+			if operation.Name == nil {
+				problem = "replace needs a name"
+			}
+		case BatchPatch:
+			if operation.Name == nil && operation.Tags == nil {
+				problem = "patch needs a name or tags"
+			}
+		case BatchMove:
+			if operation.Bucket == "" {
+				problem = "move needs a bucket"
+			}
+		case BatchDelete:
+		default:
+			problem = fmt.Sprintf("op must be one of create, replace, patch, delete or move, not %q", operation.Op)
+		}
+		if _, err := uuid.Parse(operation.ID); problem == "" && (operation.Op != BatchCreate || operation.ID != "") && err != nil {
+			problem = operation.Op + " needs the id of a named tag list"
+		}
+		if problem != "" {
+			return fmt.Errorf("%w: operation %d: %s", ErrInvalidBatch, i, problem)
+		}
+	}
+	return nil
+}
+
+// batchStatus gives the status of a failed operation, or 500 for failures
+// that are not the operation's own and so end the whole batch.
+func batchStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNamedTagListNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrQuotaExceeded), errors.Is(err, ErrNamedTagListExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// allowsWrite defers to the authenticator when there is no caller.
+func allowsWrite(ctx context.Context, bucket string) bool {
+	caller := CallerFromContext(ctx)
+	return caller == nil || caller.Allows(PermissionWrite, []string{bucket})
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// NewBatchService ...
+func NewBatchService(
+	namedTagListRepository NamedTagListRepository,
+	namedTagListService NamedTagListService,
+	unitOfWork UnitOfWork,
+) BatchService {
+	return &batchService{
+		namedTagListRepository,
+		namedTagListService,
+		unitOfWork,
+	}
+}
@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stubNamedTagListRepositoryForBatch keeps named tag lists in memory by id.
+type stubNamedTagListRepositoryForBatch struct {
+	NamedTagListRepository
+
+	withNamedTagLists map[string]BucketedNamedTagList
+	willError         bool
+
+	outsideUnitOfWork bool
+}
+
+func (r *stubNamedTagListRepositoryForBatch) touch(ctx context.Context) {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+}
+
+func (r *stubNamedTagListRepositoryForBatch) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	r.touch(ctx)
+	namedTagLists := []NamedTagList{}
+	for _, each := range r.withNamedTagLists {
+		if each.Bucket == buckets[0] {
+			namedTagLists = append(namedTagLists, each.NamedTagList)
+		}
+	}
+	return namedTagLists, nil
+}
+
+func (r *stubNamedTagListRepositoryForBatch) FindByIds(ctx context.Context, ids []string) ([]BucketedNamedTagList, error) {
+	r.touch(ctx)
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	found := []BucketedNamedTagList{}
+	for _, id := range ids {
+		if each, ok := r.withNamedTagLists[id]; ok {
+			found = append(found, each)
+		}
+	}
+	return found, nil
+}
+
+func (r *stubNamedTagListRepositoryForBatch) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
+	r.touch(ctx)
+	r.withNamedTagLists[namedTagList.ID] = BucketedNamedTagList{namedTagList, bucket}
+	return nil
+}
+
+func (r *stubNamedTagListRepositoryForBatch) ReplaceByIds(ctx context.Context, ids []string, namedTagList NamedTagList) error {
+	r.touch(ctx)
+	for _, id := range ids {
+		namedTagList.ID = id
+		r.withNamedTagLists[id] = BucketedNamedTagList{namedTagList, r.withNamedTagLists[id].Bucket}
+	}
+	return nil
+}
+
+func (r *stubNamedTagListRepositoryForBatch) DeleteByIds(ctx context.Context, ids []string) error {
+	r.touch(ctx)
+	for _, id := range ids {
+		delete(r.withNamedTagLists, id)
+	}
+	return nil
+}
+
+func (r *stubNamedTagListRepositoryForBatch) MoveByIds(ctx context.Context, ids []string, bucket string) error {
+	r.touch(ctx)
+	for _, id := range ids {
+		r.withNamedTagLists[id] = BucketedNamedTagList{r.withNamedTagLists[id].NamedTagList, bucket}
+	}
+	return nil
+}
+
+func TestBatchService(t *testing.T) {
+	const (
+		a       = "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c01"
+		b       = "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c02"
+		c       = "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c03"
+		created = "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c04"
+		unknown = "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6cff"
+	)
+	newRepository := func() *stubNamedTagListRepositoryForBatch {
+		return &stubNamedTagListRepositoryForBatch{
+			withNamedTagLists: map[string]BucketedNamedTagList{
+				a: {NamedTagList{ID: a, Name: "a", Tags: []string{"#a"}}, "blue"},
+				b: {NamedTagList{ID: b, Name: "b", Tags: []string{"#b"}}, "blue"},
+				c: {NamedTagList{ID: c, Name: "c", Tags: []string{"#c"}}, "red"},
+			},
+		}
+	}
+	newService := func(repository NamedTagListRepository, quota StorageQuota) BatchService {
+		unitOfWork := &stubUnitOfWork{}
+		return NewBatchService(
+			repository,
+			NewNamedTagListService(repository, &stubUUIDGenerator{response: created}, &stubBucketRepositoryForAuthenticator{}, quota, unitOfWork, &stubIdempotencyRepository{}),
+			unitOfWork,
+		)
+	}
+	name := func(name string) *string { return &name }
+
+	t.Run("commits every operation in order", func(t *testing.T) {
+		repository := newRepository()
+		service := newService(repository, StorageQuota{})
+
+		got, err := service.Apply(context.Background(), Batch{Operations: []BatchOperation{
+			{Op: BatchCreate, Bucket: "blue", Name: name("fresh"), Tags: []string{"#new"}},
+			{Op: BatchPatch, ID: a, Tags: []string{"#x"}},
+			{Op: BatchReplace, ID: b, Name: name("b2")},
+			{Op: BatchMove, ID: c, Bucket: "blue"},
+			{Op: BatchDelete, ID: a},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &BatchResponse{Committed: true, Results: []BatchResult{
+			{Status: 201, NamedTagList: &NamedTagList{ID: created, Name: "fresh", Tags: []string{"#new"}}},
+			{Status: 200, NamedTagList: &NamedTagList{ID: a, Name: "a", Tags: []string{"#x"}}},
+			{Status: 200, NamedTagList: &NamedTagList{ID: b, Name: "b2", Tags: []string{}}},
+			{Status: 200, NamedTagList: &NamedTagList{ID: c, Name: "c", Tags: []string{"#c"}}},
+			{Status: 204},
+		}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		wantNamedTagLists := map[string]BucketedNamedTagList{
+			b:       {NamedTagList{ID: b, Name: "b2", Tags: []string{}}, "blue"},
+			c:       {NamedTagList{ID: c, Name: "c", Tags: []string{"#c"}}, "blue"},
+			created: {NamedTagList{ID: created, Name: "fresh", Tags: []string{"#new"}}, "blue"},
+		}
+		if !reflect.DeepEqual(repository.withNamedTagLists, wantNamedTagLists) {
+			t.Errorf("got %+v want %+v", repository.withNamedTagLists, wantNamedTagLists)
+		}
+
+		if repository.outsideUnitOfWork {
+			t.Error("got repository calls outside the unit of work")
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		got, err := newService(newRepository(), StorageQuota{}).Apply(context.Background(), Batch{Operations: []BatchOperation{
+			{Op: BatchDelete, ID: a},
+			{Op: BatchPatch, ID: unknown, Name: name("z")},
+			{Op: BatchDelete, ID: b},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &BatchResponse{Committed: false, Results: []BatchResult{
+			{Status: 204},
+			{Status: 404, Error: "named tag list not found: " + unknown},
+		}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("continues past failures when asked", func(t *testing.T) {
+		repository := newRepository()
+
+		got, err := newService(repository, StorageQuota{MaxNamedTagLists: 1}).Apply(context.Background(), Batch{
+			ContinueOnError: true,
+			Operations: []BatchOperation{
+				{Op: BatchPatch, ID: unknown, Name: name("z")},
+				{Op: BatchMove, ID: a, Bucket: "red"},
+				{Op: BatchDelete, ID: b},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &BatchResponse{Committed: true, Results: []BatchResult{
+			{Status: 404, Error: "named tag list not found: " + unknown},
+			{Status: 409, Error: "quota exceeded: bucket red may hold at most 1 named tag lists"},
+			{Status: 204},
+		}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if _, ok := repository.withNamedTagLists[b]; ok {
+			t.Errorf("got %s not deleted", b)
+		}
+	})
+
+	t.Run("refuses operations on buckets the caller cannot write", func(t *testing.T) {
+		ctx := WithCaller(context.Background(), &Caller{Memberships: map[string]string{"blue": RoleEditor, "red": RoleViewer}})
+
+		got, err := newService(newRepository(), StorageQuota{}).Apply(ctx, Batch{Operations: []BatchOperation{
+			{Op: BatchPatch, ID: a, Name: name("allowed")},
+			{Op: BatchMove, ID: b, Bucket: "red"},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &BatchResponse{Committed: false, Results: []BatchResult{
+			{Status: 200, NamedTagList: &NamedTagList{ID: a, Name: "allowed", Tags: []string{"#a"}}},
+			{Status: 403, Error: ErrForbidden.Error()},
+		}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("a repository error ends the batch even when continuing", func(t *testing.T) {
+		repository := newRepository()
+		repository.willError = true
+
+		_, gotErr := newService(repository, StorageQuota{}).Apply(context.Background(), Batch{
+			ContinueOnError: true,
+			Operations:      []BatchOperation{{Op: BatchDelete, ID: a}},
+		})
+
+		if gotErr == nil || gotErr.Error() != "there was an error" {
+			t.Errorf("got error %v want there was an error", gotErr)
+		}
+	})
+
+	t.Run("refuses malformed batches before running any operation", func(t *testing.T) {
+		for _, scenario := range []struct {
+			operations []BatchOperation
+			wantErr    string
+		}{
+			{[]BatchOperation{}, "invalid batch: operations must not be empty"},
+			{make([]BatchOperation, MaxBatchOperations+1), "invalid batch: no more than 100 operations are allowed"},
+			{[]BatchOperation{{Op: BatchDelete, ID: a}, {Op: "upsert"}}, "invalid batch: operation 1: op must be one of create, replace, patch, delete or move, not \"upsert\""},
+			{[]BatchOperation{{Op: BatchCreate, Bucket: "blue"}}, "invalid batch: operation 0: create needs a bucket and a name"},
+			{[]BatchOperation{{Op: BatchCreate, ID: "offline-1", Bucket: "blue", Name: name("z")}}, "invalid batch: operation 0: create needs the id of a named tag list"},
+			{[]BatchOperation{{Op: BatchReplace, ID: a}}, "invalid batch: operation 0: replace needs a name"},
+			{[]BatchOperation{{Op: BatchPatch, ID: a}}, "invalid batch: operation 0: patch needs a name or tags"},
+			{[]BatchOperation{{Op: BatchMove, ID: a}}, "invalid batch: operation 0: move needs a bucket"},
+			{[]BatchOperation{{Op: BatchDelete, ID: "1"}}, "invalid batch: operation 0: delete needs the id of a named tag list"},
+		} {
+			repository := newRepository()
+
+			_, gotErr := newService(repository, StorageQuota{}).Apply(context.Background(), Batch{Operations: scenario.operations})
+
+			if !errors.Is(gotErr, ErrInvalidBatch) || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+
+			if got := len(repository.withNamedTagLists); got != 3 {
+				t.Errorf("%s: got %d named tag lists want 3", scenario.wantErr, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// BucketController ...
+type BucketController interface {
+	GetBuckets() http.Handler
+	CreateBucket() http.Handler
+	GetMembers() http.Handler
+	InviteMember() http.Handler
+	RevokeMember() http.Handler
+}
+
+type bucketController struct {
+	logger           Logger
+	bucketRepository BucketRepository
+	bucketService    BucketService
+}
+
+func (c *bucketController) GetBuckets() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			user, ok := signedInUser(rw, r)
+			if !ok {
+				return
+			}
+
+			memberships, err := c.bucketRepository.FindMemberships(r.Context(), user.ID)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(memberships)
+		},
+	)
+}
+
+func (c *bucketController) CreateBucket() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			user, ok := signedInUser(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var body struct {
+				Name string `json:"name"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if member, err := c.bucketService.Create(r.Context(), user.ID, body.Name); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(member)
+			}
+		},
+	)
+}
+
+func (c *bucketController) GetMembers() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := membersPath(rw, r)
+			if !ok {
+				return
+			}
+
+			members, err := c.bucketRepository.FindMembers(r.Context(), bucket)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(members)
+		},
+	)
+}
+
+func (c *bucketController) InviteMember() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := membersPath(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var body struct {
+				Email string `json:"email"`
+				Role  string `json:"role"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if member, err := c.bucketService.Invite(r.Context(), bucket, body.Email, body.Role); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(member)
+			}
+		},
+	)
+}
+
+func (c *bucketController) RevokeMember() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := membersPath(rw, r)
+			if !ok {
+				return
+			}
+
+			userIDs := r.URL.Query()["userId"]
+			if len(userIDs) != 1 {
+				writeBadRequest(rw, "exactly one userId query parameter is required")
+			} else if err := c.bucketService.Revoke(r.Context(), bucket, userIDs[0]); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+func (c *bucketController) writeServiceError(rw http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrInvalidBucket) {
+		writeBadRequest(rw, err.Error())
+	} else if errors.Is(err, ErrUnknownUser) {
+		writeError(rw, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, ErrBucketTaken) || errors.Is(err, ErrLastOwner) {
+		writeError(rw, http.StatusConflict, err.Error())
+	} else {
+		writeServerError(rw, r, c.logger, err)
+	}
+}
+
+func signedInUser(rw http.ResponseWriter, r *http.Request) (*User, bool) {
+	caller := CallerFromContext(r.Context())
+	if caller == nil || caller.User == nil {
+		writeError(rw, http.StatusForbidden, "only signed in users belong to buckets")
+		return nil, false
+	}
+	return caller.User, true
+}
+
+// membersPath reads the bucket out of /buckets/{bucket}/members.
+func membersPath(rw http.ResponseWriter, r *http.Request) (string, bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/buckets/")
+	if !strings.HasSuffix(path, "/members") || path == "/members" {
+		http.NotFound(rw, r)
+		return "", false
+	}
+	return strings.TrimSuffix(path, "/members"), true
+}
+
+// NewBucketController ...
+func NewBucketController(
+	logger Logger,
+	bucketRepository BucketRepository,
+	bucketService BucketService,
+) BucketController {
+	return &bucketController{
+		logger,
+		bucketRepository,
+		bucketService,
+	}
+}
@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubBucketRepositoryForController struct {
+	BucketRepository
+
+	withBucket string
+	withUserID string
+
+	err error
+}
+
+func (r *stubBucketRepositoryForController) FindMembers(ctx context.Context, bucket string) ([]BucketMember, error) {
+	if bucket != r.withBucket {
+		r.err = fmt.Errorf("Stub got bucket %s want %s", bucket, r.withBucket)
+	}
+	return []BucketMember{{Bucket: bucket, UserID: "1", Email: "owner@example.com", Role: RoleOwner}}, nil
+}
+
+func (r *stubBucketRepositoryForController) FindMemberships(ctx context.Context, userID string) ([]BucketMember, error) {
+	if userID != r.withUserID {
+		r.err = fmt.Errorf("Stub got user id %s want %s", userID, r.withUserID)
+	}
+	return []BucketMember{{Bucket: "blue", UserID: userID, Email: "owner@example.com", Role: RoleOwner}}, nil
+}
+
+type stubBucketService struct {
+	withArgs  []string
+	willError error
+
+	err error
+}
+
+func (s *stubBucketService) check(args ...string) {
+	if !reflect.DeepEqual(args, s.withArgs) {
+		s.err = fmt.Errorf("Stub got %v want %v", args, s.withArgs)
+	}
+}
+
+func (s *stubBucketService) Create(ctx context.Context, userID string, bucket string) (*BucketMember, error) {
+	s.check(userID, bucket)
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &BucketMember{bucket, userID, "owner@example.com", RoleOwner}, nil
+}
+
+func (s *stubBucketService) Invite(ctx context.Context, bucket string, email string, role string) (*BucketMember, error) {
+	s.check(bucket, email, role)
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &BucketMember{bucket, "2", email, role}, nil
+}
+
+func (s *stubBucketService) Revoke(ctx context.Context, bucket string, userID string) error {
+	s.check(bucket, userID)
+	return s.willError
+}
+
+func withUser(request *http.Request) *http.Request {
+	return request.WithContext(WithCaller(request.Context(), &Caller{User: &User{ID: "1"}}))
+}
+
+func TestBucketController(t *testing.T) {
+	t.Run("GET /buckets", func(t *testing.T) {
+		repository := &stubBucketRepositoryForController{withUserID: "1"}
+		controller := NewBucketController(stubLoggerNew(), repository, &stubBucketService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/buckets", nil)
+		response := httptest.NewRecorder()
+		controller.GetBuckets().ServeHTTP(response, withUser(request))
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		var gotMemberships []BucketMember
+		if err := json.NewDecoder(response.Body).Decode(&gotMemberships); err != nil {
+			t.Fatal(err)
+		}
+		wantMemberships := []BucketMember{{Bucket: "blue", UserID: "1", Email: "owner@example.com", Role: RoleOwner}}
+
+		if !reflect.DeepEqual(gotMemberships, wantMemberships) {
+			t.Errorf("got memberships %+v want %+v", gotMemberships, wantMemberships)
+		}
+	})
+
+	t.Run("GET /buckets with api key", func(t *testing.T) {
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, &stubBucketService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/buckets", nil)
+		request = request.WithContext(WithCaller(request.Context(), &Caller{APIKey: &APIKey{ID: "1"}}))
+		response := httptest.NewRecorder()
+		controller.GetBuckets().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 403
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /buckets", func(t *testing.T) {
+		service := &stubBucketService{withArgs: []string{"1", "blue"}}
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/buckets", strings.NewReader("{\"name\":\"blue\"}"))
+		response := httptest.NewRecorder()
+		controller.CreateBucket().ServeHTTP(response, withUser(request))
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /buckets when bucket is in use", func(t *testing.T) {
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, &stubBucketService{withArgs: []string{"1", "blue"}, willError: ErrBucketTaken})
+
+		request, _ := http.NewRequest(http.MethodPost, "/buckets", strings.NewReader("{\"name\":\"blue\"}"))
+		response := httptest.NewRecorder()
+		controller.CreateBucket().ServeHTTP(response, withUser(request))
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 409
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET /buckets/{bucket}/members", func(t *testing.T) {
+		repository := &stubBucketRepositoryForController{withBucket: "blue"}
+		controller := NewBucketController(stubLoggerNew(), repository, &stubBucketService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/buckets/blue/members", nil)
+		response := httptest.NewRecorder()
+		controller.GetMembers().ServeHTTP(response, withUser(request))
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /buckets/{bucket}/members", func(t *testing.T) {
+		service := &stubBucketService{withArgs: []string{"blue", "editor@example.com", RoleEditor}}
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/buckets/blue/members", strings.NewReader("{\"email\":\"editor@example.com\",\"role\":\"editor\"}"))
+		response := httptest.NewRecorder()
+		controller.InviteMember().ServeHTTP(response, withUser(request))
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /buckets/{bucket}/members for unknown user", func(t *testing.T) {
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, &stubBucketService{withArgs: []string{"blue", "nobody@example.com", RoleEditor}, willError: ErrUnknownUser})
+
+		request, _ := http.NewRequest(http.MethodPost, "/buckets/blue/members", strings.NewReader("{\"email\":\"nobody@example.com\",\"role\":\"editor\"}"))
+		response := httptest.NewRecorder()
+		controller.InviteMember().ServeHTTP(response, withUser(request))
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 404
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE /buckets/{bucket}/members", func(t *testing.T) {
+		service := &stubBucketService{withArgs: []string{"blue", "2"}}
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodDelete, "/buckets/blue/members?userId=2", nil)
+		response := httptest.NewRecorder()
+		controller.RevokeMember().ServeHTTP(response, withUser(request))
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE /buckets/{bucket}/members for last owner", func(t *testing.T) {
+		controller := NewBucketController(stubLoggerNew(), &stubBucketRepositoryForController{}, &stubBucketService{withArgs: []string{"blue", "1"}, willError: ErrLastOwner})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/buckets/blue/members?userId=1", nil)
+		response := httptest.NewRecorder()
+		controller.RevokeMember().ServeHTTP(response, withUser(request))
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 409
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+}
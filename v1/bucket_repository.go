@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"context"
+
+	uuid "github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// resourceTables names the table behind each path whose resources can be
+// addressed by id alone.
+var resourceTables = map[string]string{
+	"namedTagLists": "named_tag_lists",
+	"rotations":     "rotations",
+	"posts":         "posts",
+	"drafts":        "drafts",
+	"shares":        "shares",
+}
+
+// BucketRepository ...
+type BucketRepository interface {
+	FindMembers(ctx context.Context, bucket string) ([]BucketMember, error)
+	FindMemberships(ctx context.Context, userID string) ([]BucketMember, error)
+	PutMember(ctx context.Context, bucket string, userID string, role string) error
+	DeleteMember(ctx context.Context, bucket string, userID string) error
+	InUse(ctx context.Context, bucket string) (bool, error)
+	FindBucketsByIds(ctx context.Context, resource string, ids []string) ([]string, error)
+}
+
+type bucketRepository struct {
+	db database
+}
+
+func (r *bucketRepository) findMembers(ctx context.Context, operation string, where string, value string) ([]BucketMember, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(
+		ctx,
+		operation,
+		"select m.\"bucket\", m.\"user_id\", u.\"email\", m.\"role\" from bucket_members m join users u on u.\"id\" = m.\"user_id\" where "+where+" = $1 order by m.\"bucket\", u.\"email\"",
+		value,
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []BucketMember{}
+
+	var member BucketMember
+	for rows.Next() {
+		if err = rows.Scan(&member.Bucket, &member.UserID, &member.Email, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *bucketRepository) FindMembers(ctx context.Context, bucket string) ([]BucketMember, error) {
+	return r.findMembers(ctx, "FindMembers", "m.\"bucket\"", bucket)
+}
+
+func (r *bucketRepository) FindMemberships(ctx context.Context, userID string) ([]BucketMember, error) {
+	return r.findMembers(ctx, "FindMemberships", "m.\"user_id\"", userID)
+}
+
+func (r *bucketRepository) PutMember(ctx context.Context, bucket string, userID string, role string) error {
+	return r.db.exec(
+		ctx,
+		"PutMember",
+		"upsert into bucket_members (\"bucket\", \"user_id\", \"role\") values ($1, $2, $3)",
+		bucket,
+		userID,
+		role,
+	)
+}
+
+func (r *bucketRepository) DeleteMember(ctx context.Context, bucket string, userID string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteMember",
+		"delete from bucket_members where \"bucket\" = $1 and \"user_id\" = $2",
+		bucket,
+		userID,
+	)
+}
+
+// InUse reports whether a bucket already has members or holds any data, so
+// that buckets created before ownership existed cannot be claimed.
+func (r *bucketRepository) InUse(ctx context.Context, bucket string) (bool, error) {
+	var inUse bool
+	err := r.db.queryRow(
+		ctx,
+		"InUse",
+		"select exists (select 1 from bucket_members where \"bucket\" = $1) or exists (select 1 from named_tag_lists where \"bucket\" = $1) or exists (select 1 from rotations where \"bucket\" = $1) or exists (select 1 from tag_metadata where \"bucket\" = $1) or exists (select 1 from posts where \"bucket\" = $1) or exists (select 1 from drafts where \"bucket\" = $1) or exists (select 1 from shares where \"bucket\" = $1) or exists (select 1 from audit_events where \"bucket\" = $1)",
+		bucket,
+	).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *bucketRepository) FindBucketsByIds(ctx context.Context, resource string, ids []string) ([]string, error) {
+	table, ok := resourceTables[resource]
+	valid := []string{}
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if !ok || len(valid) < 1 {
+		return []string{}, nil
+	}
+
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindBucketsByIds", "select distinct \"bucket\" from "+table+" where \"id\" = ANY($1)", valid); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []string{}
+
+	var bucket string
+	for rows.Next() {
+		if err = rows.Scan(&bucket); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// NewBucketRepository ...
+func NewBucketRepository(pool *pgxpool.Pool, timeouts Timeouts) BucketRepository {
+	return &bucketRepository{database{pool, timeouts}}
+}
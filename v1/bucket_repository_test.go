@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestBucketRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	owner := User{ID: "7fe6ca35-d868-48a9-94d4-6e7f7db450ea", Email: "owner@example.com", PasswordHash: "hash", CreatedAt: time.Now()}
+	viewer := User{ID: "a5a5acbf-1541-4fd8-bf9a-343b75b8550f", Email: "viewer@example.com", PasswordHash: "hash", CreatedAt: time.Now()}
+	assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).Create(context.Background(), owner))
+	assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).Create(context.Background(), viewer))
+
+	t.Run("put and find members", func(t *testing.T) {
+		assertutil.NotError(t, NewBucketRepository(pool, Timeouts{}).PutMember(context.Background(), "blue", owner.ID, RoleOwner))
+		assertutil.NotError(t, NewBucketRepository(pool, Timeouts{}).PutMember(context.Background(), "blue", viewer.ID, RoleEditor))
+		assertutil.NotError(t, NewBucketRepository(pool, Timeouts{}).PutMember(context.Background(), "blue", viewer.ID, RoleViewer))
+
+		got, err := NewBucketRepository(pool, Timeouts{}).FindMembers(context.Background(), "blue")
+		assertutil.NotError(t, err)
+		want := []BucketMember{
+			{"blue", owner.ID, owner.Email, RoleOwner},
+			{"blue", viewer.ID, viewer.Email, RoleViewer},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		got, err = NewBucketRepository(pool, Timeouts{}).FindMemberships(context.Background(), viewer.ID)
+		assertutil.NotError(t, err)
+		want = []BucketMember{{"blue", viewer.ID, viewer.Email, RoleViewer}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("delete member", func(t *testing.T) {
+		assertutil.NotError(t, NewBucketRepository(pool, Timeouts{}).DeleteMember(context.Background(), "blue", viewer.ID))
+
+		got, err := NewBucketRepository(pool, Timeouts{}).FindMemberships(context.Background(), viewer.ID)
+		assertutil.NotError(t, err)
+
+		if len(got) != 0 {
+			t.Errorf("got %+v want none", got)
+		}
+	})
+
+	t.Run("bucket in use", func(t *testing.T) {
+		assertutil.NotError(t, NewNamedTagListRepository(pool, Timeouts{}).Create(context.Background(), "legacy", NamedTagList{ID: "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4", Name: "legacy", Tags: []string{}}))
+
+		now := time.Now().UTC()
+		assertutil.NotError(t, NewShareRepository(pool, Timeouts{}).Create(context.Background(), Share{ID: "7c0d2a3e-5b4f-4e6a-9d8c-1b2a3f4e5d6c", Bucket: "shared", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+		namedTagListRepository := NewNamedTagListRepository(pool, Timeouts{})
+		assertutil.NotError(t, namedTagListRepository.Create(context.Background(), "audited", NamedTagList{ID: "a4c5e6f7-0b1c-4d2e-8f3a-5b6c7d8e9f01", Name: "gone", Tags: []string{}}))
+		assertutil.NotError(t, namedTagListRepository.DeleteAll(context.Background(), []string{"audited"}))
+
+		for bucket, want := range map[string]bool{"blue": true, "legacy": true, "shared": true, "audited": true, "red": false} {
+			got, err := NewBucketRepository(pool, Timeouts{}).InUse(context.Background(), bucket)
+			assertutil.NotError(t, err)
+
+			if got != want {
+				t.Errorf("got in use %t for %s want %t", got, bucket, want)
+			}
+		}
+	})
+
+	t.Run("find buckets by ids", func(t *testing.T) {
+		got, err := NewBucketRepository(pool, Timeouts{}).FindBucketsByIds(context.Background(), "namedTagLists", []string{"39abb8d4-3ac2-4f6f-ae5c-40e4382893d4", "not-a-uuid"})
+		assertutil.NotError(t, err)
+		want := []string{"legacy"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidBucket ...
+	ErrInvalidBucket = errors.New("invalid bucket")
+	// ErrBucketTaken ...
+	ErrBucketTaken = errors.New("bucket is already in use")
+	// ErrUnknownUser ...
+	ErrUnknownUser = errors.New("no user has that email")
+	// ErrLastOwner ...
+	ErrLastOwner = errors.New("a bucket must keep at least one owner")
+)
+
+// BucketService ...
+type BucketService interface {
+	Create(ctx context.Context, userID string, bucket string) (*BucketMember, error)
+	Invite(ctx context.Context, bucket string, email string, role string) (*BucketMember, error)
+	Revoke(ctx context.Context, bucket string, userID string) error
+}
+
+type bucketService struct {
+	bucketRepository BucketRepository
+	userRepository   UserRepository
+	unitOfWork       UnitOfWork
+}
+
+func (s *bucketService) Create(ctx context.Context, userID string, bucket string) (*BucketMember, error) {
+	if strings.TrimSpace(bucket) == "" || strings.Contains(bucket, "/") {
+		return nil, fmt.Errorf("%w: name must be non-empty and must not contain /", ErrInvalidBucket)
+	}
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, ErrUnknownUser
+	}
+
+	// The check and the claim share a transaction so that two callers cannot
+	// both claim the same bucket.
+	member := BucketMember{bucket, user.ID, user.Email, RoleOwner}
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		inUse, err := s.bucketRepository.InUse(ctx, bucket)
+		if err != nil {
+			return err
+		} else if inUse {
+			return ErrBucketTaken
+		}
+		return s.bucketRepository.PutMember(ctx, bucket, user.ID, RoleOwner)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *bucketService) Invite(ctx context.Context, bucket string, email string, role string) (*BucketMember, error) {
+	if _, ok := rolePermissions[role]; !ok {
+		return nil, fmt.Errorf("%w: role must be viewer, editor or owner", ErrInvalidBucket)
+	}
+
+	user, err := s.userRepository.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, ErrUnknownUser
+	}
+
+	if role != RoleOwner {
+		if err = s.checkOwnerRemains(ctx, bucket, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := BucketMember{bucket, user.ID, user.Email, role}
+	return &member, s.bucketRepository.PutMember(ctx, bucket, user.ID, role)
+}
+
+func (s *bucketService) Revoke(ctx context.Context, bucket string, userID string) error {
+	if err := s.checkOwnerRemains(ctx, bucket, userID); err != nil {
+		return err
+	}
+	return s.bucketRepository.DeleteMember(ctx, bucket, userID)
+}
+
+// checkOwnerRemains refuses to demote or remove userID when they are the
+// bucket's only owner.
+func (s *bucketService) checkOwnerRemains(ctx context.Context, bucket string, userID string) error {
+	members, err := s.bucketRepository.FindMembers(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	owners, isOwner := 0, false
+	for _, member := range members {
+		if member.Role == RoleOwner {
+			owners++
+			isOwner = isOwner || member.UserID == userID
+		}
+	}
+	if isOwner && owners == 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// NewBucketService ...
+func NewBucketService(
+	bucketRepository BucketRepository,
+	userRepository UserRepository,
+	unitOfWork UnitOfWork,
+) BucketService {
+	return &bucketService{
+		bucketRepository,
+		userRepository,
+		unitOfWork,
+	}
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type stubBucketRepositoryForService struct {
+	BucketRepository
+
+	withInUse   bool
+	withMembers []BucketMember
+
+	put     []string
+	deleted []string
+
+	outsideUnitOfWork bool
+}
+
+func (r *stubBucketRepositoryForService) FindMembers(ctx context.Context, bucket string) ([]BucketMember, error) {
+	return r.withMembers, nil
+}
+
+func (r *stubBucketRepositoryForService) PutMember(ctx context.Context, bucket string, userID string, role string) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	r.put = []string{bucket, userID, role}
+	return nil
+}
+
+func (r *stubBucketRepositoryForService) DeleteMember(ctx context.Context, bucket string, userID string) error {
+	r.deleted = []string{bucket, userID}
+	return nil
+}
+
+func (r *stubBucketRepositoryForService) InUse(ctx context.Context, bucket string) (bool, error) {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	return r.withInUse, nil
+}
+
+func TestBucketService(t *testing.T) {
+	userRepository := stubUserRepositoryNew()
+	userRepository.users["owner@example.com"] = User{ID: "1", Email: "owner@example.com"}
+	userRepository.users["editor@example.com"] = User{ID: "2", Email: "editor@example.com"}
+
+	t.Run("create", func(t *testing.T) {
+		repository := &stubBucketRepositoryForService{}
+		service := NewBucketService(repository, userRepository, &stubUnitOfWork{})
+
+		got, err := service.Create(context.Background(), "1", "blue")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &BucketMember{Bucket: "blue", UserID: "1", Email: "owner@example.com", Role: RoleOwner}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if wantPut := []string{"blue", "1", "owner"}; !reflect.DeepEqual(repository.put, wantPut) {
+			t.Errorf("got put %v want %v", repository.put, wantPut)
+		}
+
+		if repository.outsideUnitOfWork {
+			t.Error("got check and claim outside one unit of work")
+		}
+	})
+
+	t.Run("create when bucket is in use", func(t *testing.T) {
+		service := NewBucketService(&stubBucketRepositoryForService{withInUse: true}, userRepository, &stubUnitOfWork{})
+
+		if _, gotErr := service.Create(context.Background(), "1", "blue"); gotErr != ErrBucketTaken {
+			t.Errorf("got error %v want %v", gotErr, ErrBucketTaken)
+		}
+	})
+
+	t.Run("invite", func(t *testing.T) {
+		repository := &stubBucketRepositoryForService{}
+		service := NewBucketService(repository, userRepository, &stubUnitOfWork{})
+
+		got, err := service.Invite(context.Background(), "blue", "Editor@example.com", RoleEditor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &BucketMember{Bucket: "blue", UserID: "2", Email: "editor@example.com", Role: RoleEditor}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("invite invalid", func(t *testing.T) {
+		service := NewBucketService(
+			&stubBucketRepositoryForService{withMembers: []BucketMember{{Bucket: "blue", UserID: "1", Role: RoleOwner}}},
+			userRepository,
+			&stubUnitOfWork{},
+		)
+
+		for _, scenario := range []struct {
+			email   string
+			role    string
+			wantErr string
+		}{
+			{"editor@example.com", "admin", "invalid bucket: role must be viewer, editor or owner"},
+			{"nobody@example.com", RoleViewer, "no user has that email"},
+			{"owner@example.com", RoleViewer, "a bucket must keep at least one owner"},
+		} {
+			_, gotErr := service.Invite(context.Background(), "blue", scenario.email, scenario.role)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		repository := &stubBucketRepositoryForService{
+			withMembers: []BucketMember{
+				{Bucket: "blue", UserID: "1", Role: RoleOwner},
+				{Bucket: "blue", UserID: "2", Role: RoleEditor},
+			},
+		}
+		service := NewBucketService(repository, userRepository, &stubUnitOfWork{})
+
+		if err := service.Revoke(context.Background(), "blue", "2"); err != nil {
+			t.Fatal(err)
+		}
+
+		if wantDeleted := []string{"blue", "2"}; !reflect.DeepEqual(repository.deleted, wantDeleted) {
+			t.Errorf("got deleted %v want %v", repository.deleted, wantDeleted)
+		}
+
+		if gotErr := service.Revoke(context.Background(), "blue", "1"); gotErr != ErrLastOwner {
+			t.Errorf("got error %v want %v", gotErr, ErrLastOwner)
+		}
+	})
+}
@@ -1,17 +1,62 @@
 package v1
 
-// Build ...
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// processStart is as close to when the process started as the package can
+// tell.
+var processStart = time.Now()
+
+// Build describes the running binary. sha1, version and buildTime come from
+// ldflags; the rest is read from the binary and the runtime.
 type Build struct {
-	sha1    string
-	version string
+	sha1      string
+	version   string
+	buildTime string
+	goVersion string
+	path      string
+	deps      []Dependency
+	startTime time.Time
+}
+
+// Dependency is a module the binary was built with.
+type Dependency struct {
+	Path    string      `json:"path"`
+	Version string      `json:"version"`
+	Sum     string      `json:"sum,omitempty"`
+	Replace *Dependency `json:"replace,omitempty"`
 }
 
 // NewBuild ...
-func NewBuild(sha1, version string) *Build {
-	return &Build{
-		sha1,
-		version,
+func NewBuild(sha1, version, buildTime string) *Build {
+	b := &Build{
+		sha1:      sha1,
+		version:   version,
+		buildTime: buildTime,
+		goVersion: runtime.Version(),
+		deps:      []Dependency{},
+		startTime: processStart,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.path = info.Main.Path
+		for _, module := range info.Deps {
+			b.deps = append(b.deps, dependencyOf(module))
+		}
 	}
+	return b
+}
+
+func dependencyOf(module *debug.Module) Dependency {
+	dependency := Dependency{Path: module.Path, Version: module.Version, Sum: module.Sum}
+	if module.Replace != nil {
+		replace := dependencyOf(module.Replace)
+		dependency.Replace = &replace
+	}
+	return dependency
 }
 
 func (b *Build) getSha1() string {
@@ -21,3 +66,9 @@ func (b *Build) getSha1() string {
 func (b *Build) getVersion() string {
 	return b.version
 }
+
+// dirty is true for builds of a work tree with uncommitted changes, which the
+// build script marks in the version.
+func (b *Build) dirty() bool {
+	return strings.HasSuffix(b.version, "-dirty")
+}
@@ -0,0 +1,69 @@
+package v1
+
+import "context"
+
+type callerContextKey struct{}
+
+// Caller is whoever a request was authenticated as: either an API key or a
+// user together with the buckets they are a member of. Users vouched for by
+// a JWT carry scopes from its claims instead of, or as well as, memberships.
+type Caller struct {
+	APIKey      *APIKey
+	User        *User
+	Memberships map[string]string
+	Scopes      []string
+}
+
+// CallerFromContext returns the caller a request was authenticated as, if any.
+func CallerFromContext(ctx context.Context) *Caller {
+	caller, _ := ctx.Value(callerContextKey{}).(*Caller)
+	return caller
+}
+
+// WithCaller ...
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// Allows reports whether the caller has permission on every one of buckets.
+func (c *Caller) Allows(permission string, buckets []string) bool {
+	if c.APIKey != nil {
+		return c.APIKey.Allows(permission, buckets)
+	}
+	if len(buckets) < 1 {
+		return scopesAllow(c.Scopes, permission, nil)
+	}
+	for _, bucket := range buckets {
+		if !c.allowsBucket(permission, bucket) {
+			return false
+		}
+	}
+	return true
+}
+
+// ManagesAPIKeys reports whether the caller may list, create and revoke API
+// keys. Only an API key with admin on every bucket may; session users and JWT
+// callers never do, whatever their roles or scopes, since anyone can sign up
+// and own a bucket.
+func (c *Caller) ManagesAPIKeys() bool {
+	return c.APIKey != nil && c.APIKey.Allows(PermissionAdmin, nil)
+}
+
+// Readable returns the buckets the caller may read, in the order given.
+func (c *Caller) Readable(buckets []string) []string {
+	readable := []string{}
+	for _, bucket := range buckets {
+		if c.Allows(PermissionRead, []string{bucket}) {
+			readable = append(readable, bucket)
+		}
+	}
+	return readable
+}
+
+func (c *Caller) allowsBucket(permission string, bucket string) bool {
+	role, ok := c.Memberships[bucket]
+	if ok && permissionRanks[rolePermissions[role]] >= permissionRanks[permission] {
+		return true
+	}
+	return scopesAllow(c.Scopes, permission, []string{bucket})
+}
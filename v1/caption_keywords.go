@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CaptionKeyword ...
+type CaptionKeyword struct {
+	Word    string
+	Stem    string
+	Hashtag bool
+}
+
+// ExtractKeywords splits a caption into lower case words, drops stopwords and
+// stems what is left. Hashtags already in the caption are kept as they are.
+func ExtractKeywords(caption string) []CaptionKeyword {
+	keywords := []CaptionKeyword{}
+	seen := map[string]bool{}
+	for _, token := range strings.FieldsFunc(caption, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' && r != '\''
+	}) {
+		hashtag := strings.HasPrefix(token, "#")
+		word := strings.ToLower(strings.Trim(token, "#'"))
+		if word == "" || strings.Contains(word, "#") {
+			continue
+		}
+		if !hashtag && (len([]rune(word)) < 3 || stopwords[word]) {
+			continue
+		}
+
+		stem := Stem(word)
+		if seen[stem] {
+			continue
+		}
+		seen[stem] = true
+		keywords = append(keywords, CaptionKeyword{Word: word, Stem: stem, Hashtag: hashtag})
+	}
+	return keywords
+}
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		a about above after again against all am an and any are aren't as at
+		be because been before being below between both but by can can't cannot
+		could couldn't did didn't do does doesn't doing don't down during each
+		few for from further get got had hadn't has hasn't have haven't having he
+		he'd he'll he's her here here's hers herself him himself his how how's i
+		i'd i'll i'm i've if in into is isn't it it's its itself just let's like
+		me more most much mustn't my myself no nor not now of off on once only or
+		other ought our ours ourselves out over own same shan't she she'd she'll
+		she's should shouldn't so some such than that that's the their theirs
+		them themselves then there there's these they they'd they'll they're
+		they've this those through to too under until up very was wasn't we we'd
+		we'll we're we've were weren't what what's when when's where where's
+		which while who who's whom why why's will with won't would wouldn't you
+		you'd you'll you're you've your yours yourself yourselves
+	`) {
+		stopwords[word] = true
+	}
+}
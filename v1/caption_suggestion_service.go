@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HashtagCandidate ...
+type HashtagCandidate struct {
+	Tag    string `json:"tag"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+// CaptionSuggestion is shaped like a NamedTagList so that it can be posted to
+// /namedTagLists as is.
+type CaptionSuggestion struct {
+	ID         string             `json:"id,omitempty"`
+	Name       string             `json:"name"`
+	Tags       []string           `json:"tags"`
+	Candidates []HashtagCandidate `json:"candidates"`
+}
+
+// CaptionSuggestionService ...
+type CaptionSuggestionService interface {
+	Suggest(ctx context.Context, buckets []string, caption string) (*CaptionSuggestion, error)
+}
+
+type captionSuggestionService struct {
+	tagIndex      TagIndex
+	tagDictionary TagDictionary
+}
+
+func (s *captionSuggestionService) Suggest(ctx context.Context, buckets []string, caption string) (*CaptionSuggestion, error) {
+	tagCounts, err := s.tagIndex.Tags(ctx, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketTags := []string{}
+	for tag := range tagCounts {
+		bucketTags = append(bucketTags, tag)
+	}
+	sort.Slice(bucketTags, func(a, b int) bool {
+		if tagCounts[bucketTags[a]] != tagCounts[bucketTags[b]] {
+			return tagCounts[bucketTags[a]] > tagCounts[bucketTags[b]]
+		}
+		return bucketTags[a] < bucketTags[b]
+	})
+	bucketTagsByStem := tagsByStem(bucketTags)
+	dictionaryTagsByStem := tagsByStem(s.tagDictionary)
+
+	suggestion := &CaptionSuggestion{
+		Name:       captionName(caption),
+		Tags:       []string{},
+		Candidates: []HashtagCandidate{},
+	}
+	seen := map[string]bool{}
+	for _, keyword := range ExtractKeywords(caption) {
+		var candidate HashtagCandidate
+		if tag, ok := bucketTagsByStem[keyword.Stem]; ok {
+			candidate = HashtagCandidate{
+				Tag:    tag,
+				Source: "bucket",
+				Reason: fmt.Sprintf("%q matches a tag used in %s", keyword.Word, strings.Join(buckets, ", ")),
+			}
+		} else if tag, ok := dictionaryTagsByStem[keyword.Stem]; ok {
+			candidate = HashtagCandidate{
+				Tag:    tag,
+				Source: "dictionary",
+				Reason: fmt.Sprintf("%q matches the local dictionary", keyword.Word),
+			}
+		} else if keyword.Hashtag {
+			candidate = HashtagCandidate{
+				Tag:    "#" + keyword.Word,
+				Source: "caption",
+				Reason: fmt.Sprintf("%q is a hashtag in the caption", keyword.Word),
+			}
+		} else {
+			continue
+		}
+
+		if seen[candidate.Tag] {
+			continue
+		}
+		seen[candidate.Tag] = true
+		suggestion.Tags = append(suggestion.Tags, candidate.Tag)
+		suggestion.Candidates = append(suggestion.Candidates, candidate)
+	}
+	return suggestion, nil
+}
+
+// NewCaptionSuggestionService ...
+func NewCaptionSuggestionService(
+	tagIndex TagIndex,
+	tagDictionary TagDictionary,
+) CaptionSuggestionService {
+	return &captionSuggestionService{
+		tagIndex,
+		tagDictionary,
+	}
+}
+
+func tagsByStem(tags []string) map[string]string {
+	byStem := map[string]string{}
+	for _, tag := range tags {
+		stem := Stem(strings.ToLower(strings.TrimPrefix(tag, "#")))
+		if _, ok := byStem[stem]; !ok {
+			byStem[stem] = tag
+		}
+	}
+	return byStem
+}
+
+func captionName(caption string) string {
+	name := []rune(strings.TrimSpace(strings.SplitN(strings.TrimSpace(caption), "\n", 2)[0]))
+	if len(name) > 40 {
+		return strings.TrimSpace(string(name[:40]))
+	}
+	return string(name)
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type stubTagIndexForCaptionSuggestion struct {
+	TagIndex
+
+	withTags  map[string]int
+	willError bool
+}
+
+func (i *stubTagIndexForCaptionSuggestion) Tags(ctx context.Context, buckets []string) (map[string]int, error) {
+	if i.willError {
+		return nil, errors.New("there was an error")
+	}
+	return i.withTags, nil
+}
+
+func TestCaptionSuggestionService(t *testing.T) {
+	t.Run("suggest", func(t *testing.T) {
+		service := NewCaptionSuggestionService(
+			&stubTagIndexForCaptionSuggestion{
+				withTags: map[string]int{"#beach": 3, "#Beaches": 1, "#tdd": 2},
+			},
+			TagDictionary{"#sunsets", "#surfing"},
+		)
+
+		got, err := service.Suggest(
+			context.Background(),
+			[]string{"bucket"},
+			"Another windy sunset at the beaches\nwith the #crew and #Beach",
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &CaptionSuggestion{
+			Name: "Another windy sunset at the beaches",
+			Tags: []string{"#sunsets", "#beach", "#crew"},
+			Candidates: []HashtagCandidate{
+				{Tag: "#sunsets", Source: "dictionary", Reason: "\"sunset\" matches the local dictionary"},
+				{Tag: "#beach", Source: "bucket", Reason: "\"beaches\" matches a tag used in bucket"},
+				{Tag: "#crew", Source: "caption", Reason: "\"crew\" is a hashtag in the caption"},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("suggest when index has error", func(t *testing.T) {
+		service := NewCaptionSuggestionService(
+			&stubTagIndexForCaptionSuggestion{willError: true},
+			TagDictionary{},
+		)
+
+		_, gotErr := service.Suggest(context.Background(), []string{"bucket"}, "caption")
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// database runs a repository's statements in the unit of work in ctx, if
+// any, or else on a pool connection held no longer than the operation's
+// timeout allows. Statements stop when ctx is cancelled, so that work for a
+// client that has gone away stops with it.
+type database struct {
+	pool     *pgxpool.Pool
+	timeouts Timeouts
+}
+
+// query returns rows that hold their connection, and the operation's
+// deadline, until they are closed.
+func (d database) query(ctx context.Context, operation string, sql string, args ...interface{}) (pgx.Rows, error) {
+	if t := transactionFrom(ctx); t != nil {
+		return tracedQuery(ctx, t.tx, sql, args...)
+	}
+
+	ctx, cancel := d.timeouts.withTimeout(ctx, operation)
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		cancel()
+		return nil, timedOut(ctx, ErrDatabaseBusy, err)
+	}
+	rows, err := tracedQuery(ctx, conn, sql, args...)
+	if err != nil {
+		conn.Release()
+		cancel()
+		return nil, timedOut(ctx, ErrQueryTimeout, err)
+	}
+	return &pooledRows{rows, ctx, func() {
+		conn.Release()
+		cancel()
+	}}, nil
+}
+
+// queryRow scans like pgx's QueryRow, reporting pgx.ErrNoRows when the query
+// returns nothing.
+func (d database) queryRow(ctx context.Context, operation string, sql string, args ...interface{}) pgx.Row {
+	rows, err := d.query(ctx, operation, sql, args...)
+	return &pooledRow{rows, err}
+}
+
+func (d database) exec(ctx context.Context, operation string, sql string, args ...interface{}) error {
+	if t := transactionFrom(ctx); t != nil {
+		return tracedExec(ctx, t.tx, sql, args...)
+	}
+	return withConn(ctx, d.pool, d.timeouts, operation, func(ctx context.Context, conn *pgxpool.Conn) error {
+		ctx, span := startQuerySpan(ctx, sql)
+		_, err := conn.Exec(ctx, sql, args...)
+		span.End(err)
+		return err
+	})
+}
+
+// write joins the unit of work in ctx, if any, or runs fn in a retried
+// transaction of its own.
+func (d database) write(ctx context.Context, operation string, fn func(context.Context, pgx.Tx) error) error {
+	if t := transactionFrom(ctx); t != nil {
+		return fn(ctx, t.tx)
+	}
+	return withConn(ctx, d.pool, d.timeouts, operation, func(ctx context.Context, conn *pgxpool.Conn) error {
+		return executeTx(ctx, conn, fn)
+	})
+}
+
+// withConn holds a connection for use only as long as the operation's timeout
+// and ctx allow. A cancelled or expired context interrupts the query, and the
+// connection goes back to the pool, or is closed if it cannot be reused,
+// before withConn returns.
+func withConn(ctx context.Context, pool *pgxpool.Pool, timeouts Timeouts, operation string, use func(context.Context, *pgxpool.Conn) error) error {
+	ctx, cancel := timeouts.withTimeout(ctx, operation)
+	defer cancel()
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return timedOut(ctx, ErrDatabaseBusy, err)
+	}
+	defer conn.Release()
+
+	return timedOut(ctx, ErrQueryTimeout, use(ctx, conn))
+}
+
+type pooledRows struct {
+	pgx.Rows
+	ctx     context.Context
+	release func()
+}
+
+func (r *pooledRows) Err() error {
+	return timedOut(r.ctx, ErrQueryTimeout, r.Rows.Err())
+}
+
+func (r *pooledRows) Close() {
+	r.Rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+}
+
+type pooledRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *pooledRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
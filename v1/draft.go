@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"strings"
+	"time"
+)
+
+// Draft is a caption planned for publishing with the tags of one or more
+// named tag lists.
+type Draft struct {
+	ID              string     `json:"id"`
+	Bucket          string     `json:"bucket"`
+	Caption         string     `json:"caption"`
+	NamedTagListIDs []string   `json:"namedTagListIds"`
+	PublishAt       *time.Time `json:"publishAt"`
+	Status          string     `json:"status"`
+}
+
+// DraftStatuses ...
+var DraftStatuses = map[string]bool{
+	"draft":     true,
+	"scheduled": true,
+	"posted":    true,
+}
+
+// RenderedDraft ...
+type RenderedDraft struct {
+	Text                   string   `json:"text"`
+	Tags                   []string `json:"tags"`
+	MissingNamedTagListIDs []string `json:"missingNamedTagListIds"`
+}
+
+// RenderCaption appends the hashtags to the caption after a blank line.
+func RenderCaption(caption string, tags []string) string {
+	caption = strings.TrimSpace(caption)
+	if len(tags) < 1 {
+		return caption
+	} else if caption == "" {
+		return strings.Join(tags, " ")
+	}
+	return caption + "\n\n" + strings.Join(tags, " ")
+}
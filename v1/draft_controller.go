@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DraftController ...
+type DraftController interface {
+	GetDrafts() http.Handler
+	CreateDraft() http.Handler
+	ReplaceDraft() http.Handler
+	DeleteDrafts() http.Handler
+	RenderDraft() http.Handler
+}
+
+type draftController struct {
+	logger          Logger
+	draftRepository DraftRepository
+	draftService    DraftService
+}
+
+func (c *draftController) GetDrafts() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			buckets := r.URL.Query()["bucket"]
+			if len(buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			}
+
+			var from, to *time.Time
+			for name, bound := range map[string]**time.Time{"from": &from, "to": &to} {
+				if value := r.URL.Query().Get(name); value != "" {
+					parsed, err := time.Parse(time.RFC3339, value)
+					if err != nil {
+						writeBadRequest(rw, name+" must be an RFC 3339 time")
+						return
+					}
+					*bound = &parsed
+				}
+			}
+
+			drafts, err := c.draftRepository.FindAll(r.Context(), buckets, from, to)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(drafts)
+		},
+	)
+}
+
+func (c *draftController) CreateDraft() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var (
+				draft *Draft
+				err   error
+			)
+			if json.NewDecoder(r.Body).Decode(&draft) != nil || draft == nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if draft, err = c.draftService.Create(r.Context(), bucket, *draft); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(draft)
+			}
+		},
+	)
+}
+
+func (c *draftController) ReplaceDraft() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) != 1 {
+				writeBadRequest(rw, "exactly one id query parameter is required")
+				return
+			}
+
+			defer r.Body.Close()
+			var draft *Draft
+			if json.NewDecoder(r.Body).Decode(&draft) != nil || draft == nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if err := c.draftService.Replace(r.Context(), ids[0], *draft); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+func (c *draftController) DeleteDrafts() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) < 1 {
+				writeBadRequest(rw, "id query parameter is required")
+				return
+			}
+
+			if err := c.draftRepository.DeleteByIds(r.Context(), ids); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+// RenderDraft responds with JSON unless format=text is asked for, in which
+// case only the caption text is written.
+func (c *draftController) RenderDraft() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/drafts/")
+			if !strings.HasSuffix(path, "/render") || path == "/render" {
+				http.NotFound(rw, r)
+				return
+			}
+
+			rendered, err := c.draftService.Render(r.Context(), strings.TrimSuffix(path, "/render"))
+			if err != nil {
+				c.writeServiceError(rw, r, err)
+			} else if r.URL.Query().Get("format") == "text" {
+				rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				rw.Write([]byte(rendered.Text))
+			} else {
+				json.NewEncoder(rw).Encode(rendered)
+			}
+		},
+	)
+}
+
+func (c *draftController) writeServiceError(rw http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrDraftNotFound) {
+		writeError(rw, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, ErrInvalidDraft) {
+		writeBadRequest(rw, err.Error())
+	} else {
+		writeServerError(rw, r, c.logger, err)
+	}
+}
+
+// NewDraftController ...
+func NewDraftController(
+	logger Logger,
+	draftRepository DraftRepository,
+	draftService DraftService,
+) DraftController {
+	return &draftController{
+		logger,
+		draftRepository,
+		draftService,
+	}
+}
@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubDraftRepositoryForController struct {
+	DraftRepository
+
+	withBuckets []string
+	withFrom    *time.Time
+	withTo      *time.Time
+	withIds     []string
+	willError   string
+
+	err error
+}
+
+func (r *stubDraftRepositoryForController) FindAll(ctx context.Context, buckets []string, from, to *time.Time) ([]Draft, error) {
+	requestMatched := reflect.DeepEqual(buckets, r.withBuckets) && reflect.DeepEqual(from, r.withFrom) && reflect.DeepEqual(to, r.withTo)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got buckets %v want %v got from %v want %v got to %v want %v", buckets, r.withBuckets, from, r.withFrom, to, r.withTo)
+	}
+	if requestMatched == (r.willError == "FindAll") {
+		return nil, errors.New("there was an error")
+	}
+	return []Draft{{ID: "1", Bucket: "bucket", Caption: "Windy day", NamedTagListIDs: []string{}, Status: "draft"}}, nil
+}
+
+func (r *stubDraftRepositoryForController) DeleteByIds(ctx context.Context, ids []string) error {
+	requestMatched := reflect.DeepEqual(ids, r.withIds)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got ids %v want %v", ids, r.withIds)
+	}
+	if requestMatched == (r.willError == "DeleteByIds") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+type stubDraftService struct {
+	withBucket string
+	withID     string
+	withDraft  Draft
+	willError  error
+
+	err error
+}
+
+func (s *stubDraftService) Create(ctx context.Context, bucket string, draft Draft) (*Draft, error) {
+	if bucket != s.withBucket || !reflect.DeepEqual(draft, s.withDraft) {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got draft %+v want %+v", bucket, s.withBucket, draft, s.withDraft)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	draft.ID = "1"
+	draft.Bucket = bucket
+	return &draft, nil
+}
+
+func (s *stubDraftService) Replace(ctx context.Context, id string, draft Draft) error {
+	if id != s.withID || !reflect.DeepEqual(draft, s.withDraft) {
+		s.err = fmt.Errorf("Stub got id %s want %s got draft %+v want %+v", id, s.withID, draft, s.withDraft)
+	}
+	return s.willError
+}
+
+func (s *stubDraftService) Render(ctx context.Context, id string) (*RenderedDraft, error) {
+	if id != s.withID {
+		s.err = fmt.Errorf("Stub got id %s want %s", id, s.withID)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &RenderedDraft{Text: "Windy day\n\n#windy", Tags: []string{"#windy"}, MissingNamedTagListIDs: []string{}}, nil
+}
+
+func TestDraftController(t *testing.T) {
+	t.Run("GET calendar", func(t *testing.T) {
+		from := time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+		repository := &stubDraftRepositoryForController{
+			withBuckets: []string{"bucket"},
+			withFrom:    &from,
+			withTo:      &to,
+		}
+		controller := NewDraftController(stubLoggerNew(), repository, &stubDraftService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/drafts?bucket=bucket&from=2020-11-01T00:00:00Z&to=2020-12-01T00:00:00Z", nil)
+		response := httptest.NewRecorder()
+		controller.GetDrafts().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotDrafts []Draft
+		if err := json.NewDecoder(response.Body).Decode(&gotDrafts); err != nil {
+			t.Fatal(err)
+		}
+
+		wantDrafts := []Draft{{ID: "1", Bucket: "bucket", Caption: "Windy day", NamedTagListIDs: []string{}, Status: "draft"}}
+
+		if !reflect.DeepEqual(gotDrafts, wantDrafts) {
+			t.Errorf("got drafts %+v want %+v", gotDrafts, wantDrafts)
+		}
+	})
+
+	t.Run("GET with malformed time", func(t *testing.T) {
+		controller := NewDraftController(stubLoggerNew(), &stubDraftRepositoryForController{}, &stubDraftService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/drafts?bucket=bucket&from=yesterday", nil)
+		response := httptest.NewRecorder()
+		controller.GetDrafts().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		service := &stubDraftService{withBucket: "bucket", withDraft: Draft{Caption: "Windy day"}}
+		controller := NewDraftController(stubLoggerNew(), &stubDraftRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/drafts?bucket=bucket", strings.NewReader("{\"caption\":\"Windy day\"}"))
+		response := httptest.NewRecorder()
+		controller.CreateDraft().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST invalid", func(t *testing.T) {
+		controller := NewDraftController(
+			stubLoggerNew(),
+			&stubDraftRepositoryForController{},
+			&stubDraftService{willError: fmt.Errorf("%w: status must be draft, scheduled or posted", ErrInvalidDraft)},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/drafts?bucket=bucket", strings.NewReader("{\"status\":\"published\"}"))
+		response := httptest.NewRecorder()
+		controller.CreateDraft().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("PUT", func(t *testing.T) {
+		service := &stubDraftService{withID: "1", withDraft: Draft{Caption: "Windy day", Status: "posted"}}
+		controller := NewDraftController(stubLoggerNew(), &stubDraftRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPut, "/drafts?id=1", strings.NewReader("{\"caption\":\"Windy day\",\"status\":\"posted\"}"))
+		response := httptest.NewRecorder()
+		controller.ReplaceDraft().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("PUT when draft does not exist", func(t *testing.T) {
+		controller := NewDraftController(
+			stubLoggerNew(),
+			&stubDraftRepositoryForController{},
+			&stubDraftService{withID: "1", willError: ErrDraftNotFound},
+		)
+
+		request, _ := http.NewRequest(http.MethodPut, "/drafts?id=1", strings.NewReader("{}"))
+		response := httptest.NewRecorder()
+		controller.ReplaceDraft().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 404
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		repository := &stubDraftRepositoryForController{withIds: []string{"1"}}
+		controller := NewDraftController(stubLoggerNew(), repository, &stubDraftService{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/drafts?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.DeleteDrafts().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET render as text", func(t *testing.T) {
+		service := &stubDraftService{withID: "1"}
+		controller := NewDraftController(stubLoggerNew(), &stubDraftRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodGet, "/drafts/1/render?format=text", nil)
+		response := httptest.NewRecorder()
+		controller.RenderDraft().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := response.Body.String()
+		wantBody := "Windy day\n\n#windy"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("GET render when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewDraftController(
+			logger,
+			&stubDraftRepositoryForController{},
+			&stubDraftService{withID: "1", willError: errors.New("there was an error")},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/drafts/1/render", nil)
+		response := httptest.NewRecorder()
+		controller.RenderDraft().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
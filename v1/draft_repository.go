@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// DraftRepository ...
+type DraftRepository interface {
+	FindAll(ctx context.Context, buckets []string, from, to *time.Time) ([]Draft, error)
+	FindByID(ctx context.Context, id string) (*Draft, error)
+	Create(ctx context.Context, draft Draft) error
+	ReplaceByID(ctx context.Context, id string, draft Draft) error
+	DeleteByIds(ctx context.Context, ids []string) error
+}
+
+type draftRepository struct {
+	db database
+}
+
+// FindAll returns the drafts in the buckets. When from or to is given only
+// drafts with a publish time in [from, to) are returned.
+func (r *draftRepository) FindAll(ctx context.Context, buckets []string, from, to *time.Time) ([]Draft, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(
+		ctx,
+		"FindAll",
+		"select \"id\", \"bucket\", \"caption\", \"named_tag_list_ids\", \"publish_at\", \"status\" from drafts where \"bucket\" = ANY($1) and ($2::timestamptz is null or \"publish_at\" >= $2) and ($3::timestamptz is null or \"publish_at\" < $3) order by \"publish_at\", \"id\"",
+		buckets,
+		from,
+		to,
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drafts := []Draft{}
+	for rows.Next() {
+		var draft Draft
+		if err = rows.Scan(&draft.ID, &draft.Bucket, &draft.Caption, &draft.NamedTagListIDs, &draft.PublishAt, &draft.Status); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return drafts, rows.Err()
+}
+
+func (r *draftRepository) FindByID(ctx context.Context, id string) (*Draft, error) {
+	var draft Draft
+	err := r.db.queryRow(
+		ctx,
+		"FindByID",
+		"select \"id\", \"bucket\", \"caption\", \"named_tag_list_ids\", \"publish_at\", \"status\" from drafts where \"id\" = $1",
+		id,
+	).Scan(&draft.ID, &draft.Bucket, &draft.Caption, &draft.NamedTagListIDs, &draft.PublishAt, &draft.Status)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) Create(ctx context.Context, draft Draft) error {
+	return r.db.exec(
+		ctx,
+		"Create",
+		"insert into drafts (\"id\", \"bucket\", \"caption\", \"named_tag_list_ids\", \"publish_at\", \"status\") values ($1, $2, $3, $4, $5, $6)",
+		draft.ID,
+		draft.Bucket,
+		draft.Caption,
+		draft.NamedTagListIDs,
+		draft.PublishAt,
+		draft.Status,
+	)
+}
+
+func (r *draftRepository) ReplaceByID(ctx context.Context, id string, draft Draft) error {
+	return r.db.exec(
+		ctx,
+		"ReplaceByID",
+		"update drafts set \"caption\" = $1, \"named_tag_list_ids\" = $2, \"publish_at\" = $3, \"status\" = $4 where \"id\" = $5",
+		draft.Caption,
+		draft.NamedTagListIDs,
+		draft.PublishAt,
+		draft.Status,
+		id,
+	)
+}
+
+func (r *draftRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteByIds",
+		"delete from drafts where \"id\" = ANY($1)",
+		ids,
+	)
+}
+
+// NewDraftRepository ...
+func NewDraftRepository(pool *pgxpool.Pool, timeouts Timeouts) DraftRepository {
+	return &draftRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestDraftRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	november := time.Date(2020, 11, 15, 12, 0, 0, 0, time.UTC)
+	december := time.Date(2020, 12, 15, 12, 0, 0, 0, time.UTC)
+	scheduled := Draft{
+		ID:              "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		Bucket:          "blue",
+		Caption:         "Windy day",
+		NamedTagListIDs: []string{"39abb8d4-3ac2-4f6f-ae5c-40e4382893d4"},
+		PublishAt:       &november,
+		Status:          "scheduled",
+	}
+	later := Draft{
+		ID:              "a5a5acbf-1541-4fd8-bf9a-343b75b8550f",
+		Bucket:          "blue",
+		Caption:         "Snowy day",
+		NamedTagListIDs: []string{},
+		PublishAt:       &december,
+		Status:          "draft",
+	}
+
+	utc := func(drafts []Draft) []Draft {
+		for i := range drafts {
+			if drafts[i].PublishAt != nil {
+				publishAt := drafts[i].PublishAt.UTC()
+				drafts[i].PublishAt = &publishAt
+			}
+		}
+		return drafts
+	}
+
+	t.Run("create drafts", func(t *testing.T) {
+		assertutil.NotError(t, NewDraftRepository(pool, Timeouts{}).Create(context.Background(), later))
+		assertutil.NotError(t, NewDraftRepository(pool, Timeouts{}).Create(context.Background(), scheduled))
+
+		got, err := NewDraftRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"}, nil, nil)
+		assertutil.NotError(t, err)
+		want := []Draft{scheduled, later}
+
+		if !reflect.DeepEqual(utc(got), want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("find drafts in calendar range", func(t *testing.T) {
+		from := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+		got, err := NewDraftRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"}, &from, nil)
+		assertutil.NotError(t, err)
+		want := []Draft{later}
+
+		if !reflect.DeepEqual(utc(got), want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("replace draft by id", func(t *testing.T) {
+		replaced := scheduled
+		replaced.Status = "posted"
+		assertutil.NotError(t, NewDraftRepository(pool, Timeouts{}).ReplaceByID(context.Background(), scheduled.ID, replaced))
+
+		got, err := NewDraftRepository(pool, Timeouts{}).FindByID(context.Background(), scheduled.ID)
+		assertutil.NotError(t, err)
+
+		if !reflect.DeepEqual(utc([]Draft{*got}), []Draft{replaced}) {
+			t.Errorf("got %+v want %+v", got, replaced)
+		}
+	})
+
+	t.Run("delete draft by id", func(t *testing.T) {
+		assertutil.NotError(t, NewDraftRepository(pool, Timeouts{}).DeleteByIds(context.Background(), []string{scheduled.ID, later.ID}))
+
+		got, err := NewDraftRepository(pool, Timeouts{}).FindByID(context.Background(), scheduled.ID)
+		assertutil.NotError(t, err)
+
+		if got != nil {
+			t.Errorf("got %+v want nil", got)
+		}
+	})
+}
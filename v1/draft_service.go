@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	uuid "github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidDraft ...
+	ErrInvalidDraft = errors.New("invalid draft")
+	// ErrDraftNotFound ...
+	ErrDraftNotFound = errors.New("draft not found")
+)
+
+// DraftService ...
+type DraftService interface {
+	Create(ctx context.Context, bucket string, draft Draft) (*Draft, error)
+	Replace(ctx context.Context, id string, draft Draft) error
+	Render(ctx context.Context, id string) (*RenderedDraft, error)
+}
+
+type draftService struct {
+	draftRepository        DraftRepository
+	namedTagListRepository NamedTagListRepository
+	uuidGenerator          UUIDGenerator
+}
+
+func (s *draftService) Create(ctx context.Context, bucket string, draft Draft) (*Draft, error) {
+	if err := validateDraft(&draft); err != nil {
+		return nil, err
+	}
+	draft.ID = s.uuidGenerator.Generate()
+	draft.Bucket = bucket
+	return &draft, s.draftRepository.Create(ctx, draft)
+}
+
+func (s *draftService) Replace(ctx context.Context, id string, draft Draft) error {
+	if err := validateDraft(&draft); err != nil {
+		return err
+	}
+	existing, err := s.findByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.draftRepository.ReplaceByID(ctx, existing.ID, draft)
+}
+
+func (s *draftService) Render(ctx context.Context, id string) (*RenderedDraft, error) {
+	draft, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{draft.Bucket})
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]NamedTagList{}
+	for _, namedTagList := range namedTagLists {
+		byID[namedTagList.ID] = namedTagList
+	}
+
+	tags := []string{}
+	missing := []string{}
+	for _, id := range draft.NamedTagListIDs {
+		if namedTagList, ok := byID[id]; ok {
+			tags = append(tags, namedTagList.Tags...)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	tags = uniqueTags(tags)
+
+	return &RenderedDraft{
+		Text:                   RenderCaption(draft.Caption, tags),
+		Tags:                   tags,
+		MissingNamedTagListIDs: missing,
+	}, nil
+}
+
+func (s *draftService) findByID(ctx context.Context, id string) (*Draft, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrDraftNotFound
+	}
+	draft, err := s.draftRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if draft == nil {
+		return nil, ErrDraftNotFound
+	}
+	return draft, nil
+}
+
+// NewDraftService ...
+func NewDraftService(
+	draftRepository DraftRepository,
+	namedTagListRepository NamedTagListRepository,
+	uuidGenerator UUIDGenerator,
+) DraftService {
+	return &draftService{
+		draftRepository,
+		namedTagListRepository,
+		uuidGenerator,
+	}
+}
+
+func validateDraft(draft *Draft) error {
+	if draft.Status == "" {
+		draft.Status = "draft"
+	}
+	if !DraftStatuses[draft.Status] {
+		return fmt.Errorf("%w: status must be draft, scheduled or posted", ErrInvalidDraft)
+	}
+	if draft.Status == "scheduled" && draft.PublishAt == nil {
+		return fmt.Errorf("%w: publishAt is required when scheduled", ErrInvalidDraft)
+	}
+	if draft.NamedTagListIDs == nil {
+		draft.NamedTagListIDs = []string{}
+	}
+	for _, id := range draft.NamedTagListIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: %s is not a named tag list id", ErrInvalidDraft, id)
+		}
+	}
+	return nil
+}
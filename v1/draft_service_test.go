@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubDraftRepositoryForService struct {
+	DraftRepository
+
+	withDraft *Draft
+	willError bool
+
+	created  Draft
+	replaced Draft
+}
+
+func (r *stubDraftRepositoryForService) FindByID(ctx context.Context, id string) (*Draft, error) {
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	if r.withDraft == nil || r.withDraft.ID != id {
+		return nil, nil
+	}
+	return r.withDraft, nil
+}
+
+func (r *stubDraftRepositoryForService) Create(ctx context.Context, draft Draft) error {
+	r.created = draft
+	return nil
+}
+
+func (r *stubDraftRepositoryForService) ReplaceByID(ctx context.Context, id string, draft Draft) error {
+	r.replaced = draft
+	return nil
+}
+
+func TestDraftService(t *testing.T) {
+	publishAt := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	beachID := "7fe6ca35-d868-48a9-94d4-6e7f7db450ea"
+	codeID := "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4"
+	missingID := "a5a5acbf-1541-4fd8-bf9a-343b75b8550f"
+	draftID := "3e99aa77-615e-4a55-930d-d4c77cfd1b72"
+	namedTagListRepository := &stubNamedTagListRepositoryForRotation{
+		withBucket: "bucket",
+		withNamedTagLists: []NamedTagList{
+			{ID: beachID, Tags: []string{"#beach", "#windy"}},
+			{ID: codeID, Tags: []string{"#tdd", "#windy"}},
+		},
+	}
+
+	t.Run("create", func(t *testing.T) {
+		repository := &stubDraftRepositoryForService{}
+		service := NewDraftService(repository, namedTagListRepository, &stubUUIDGenerator{response: draftID})
+
+		got, err := service.Create(context.Background(), "bucket", Draft{Caption: "Windy day", PublishAt: &publishAt, Status: "scheduled"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Draft{
+			ID:              draftID,
+			Bucket:          "bucket",
+			Caption:         "Windy day",
+			NamedTagListIDs: []string{},
+			PublishAt:       &publishAt,
+			Status:          "scheduled",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if !reflect.DeepEqual(repository.created, *want) {
+			t.Errorf("got created %+v want %+v", repository.created, *want)
+		}
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		for _, scenario := range []struct {
+			draft   Draft
+			wantErr string
+		}{
+			{draft: Draft{Status: "published"}, wantErr: "invalid draft: status must be draft, scheduled or posted"},
+			{draft: Draft{Status: "scheduled"}, wantErr: "invalid draft: publishAt is required when scheduled"},
+			{draft: Draft{NamedTagListIDs: []string{"beach"}}, wantErr: "invalid draft: beach is not a named tag list id"},
+		} {
+			service := NewDraftService(&stubDraftRepositoryForService{}, namedTagListRepository, &stubUUIDGenerator{})
+
+			_, gotErr := service.Create(context.Background(), "bucket", scenario.draft)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		repository := &stubDraftRepositoryForService{withDraft: &Draft{ID: draftID}}
+		service := NewDraftService(repository, namedTagListRepository, &stubUUIDGenerator{})
+
+		if err := service.Replace(context.Background(), draftID, Draft{Caption: "Posted", Status: "posted"}); err != nil {
+			t.Fatal(err)
+		}
+		want := Draft{Caption: "Posted", NamedTagListIDs: []string{}, Status: "posted"}
+
+		if !reflect.DeepEqual(repository.replaced, want) {
+			t.Errorf("got replaced %+v want %+v", repository.replaced, want)
+		}
+	})
+
+	t.Run("replace when draft does not exist", func(t *testing.T) {
+		service := NewDraftService(&stubDraftRepositoryForService{}, namedTagListRepository, &stubUUIDGenerator{})
+
+		if gotErr := service.Replace(context.Background(), draftID, Draft{}); gotErr != ErrDraftNotFound {
+			t.Errorf("got error %v want %v", gotErr, ErrDraftNotFound)
+		}
+	})
+
+	t.Run("render", func(t *testing.T) {
+		service := NewDraftService(
+			&stubDraftRepositoryForService{
+				withDraft: &Draft{
+					ID:              draftID,
+					Bucket:          "bucket",
+					Caption:         "Windy day\n",
+					NamedTagListIDs: []string{beachID, missingID, codeID},
+				},
+			},
+			namedTagListRepository,
+			&stubUUIDGenerator{},
+		)
+
+		got, err := service.Render(context.Background(), draftID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &RenderedDraft{
+			Text:                   "Windy day\n\n#beach #windy #tdd",
+			Tags:                   []string{"#beach", "#windy", "#tdd"},
+			MissingNamedTagListIDs: []string{missingID},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("render when repository has error", func(t *testing.T) {
+		service := NewDraftService(&stubDraftRepositoryForService{willError: true}, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Render(context.Background(), draftID)
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
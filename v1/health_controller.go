@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// HealthCheck returns an error when whatever it checks is unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+// HealthChecks are the checks behind each probe by name.
+type HealthChecks struct {
+	Liveness  map[string]HealthCheck
+	Readiness map[string]HealthCheck
+	Startup   map[string]HealthCheck
+}
+
+// CheckResult ...
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport passes only when every one of its checks does.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Health check statuses.
+const (
+	HealthPass = "pass"
+	HealthFail = "fail"
+)
+
+// ErrDraining is what readiness reports once shutdown has begun.
+var ErrDraining = errors.New("shutting down")
+
+// HealthController answers the liveness, readiness and startup probes. Drain
+// fails readiness from then on so that load balancers stop sending requests
+// before the server stops taking them.
+type HealthController interface {
+	GetLiveness() http.Handler
+	GetReadiness() http.Handler
+	GetStartup() http.Handler
+	Drain()
+}
+
+type healthController struct {
+	checks   HealthChecks
+	timeout  time.Duration
+	now      func() time.Time
+	draining int32
+}
+
+func (c *healthController) GetLiveness() http.Handler {
+	return c.handler(func() map[string]HealthCheck { return c.checks.Liveness })
+}
+
+func (c *healthController) GetReadiness() http.Handler {
+	return c.handler(func() map[string]HealthCheck {
+		checks := map[string]HealthCheck{"shutdown": c.checkDraining}
+		for name, check := range c.checks.Readiness {
+			checks[name] = check
+		}
+		return checks
+	})
+}
+
+func (c *healthController) GetStartup() http.Handler {
+	return c.handler(func() map[string]HealthCheck { return c.checks.Startup })
+}
+
+func (c *healthController) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+func (c *healthController) checkDraining(ctx context.Context) error {
+	if atomic.LoadInt32(&c.draining) == 1 {
+		return ErrDraining
+	}
+	return nil
+}
+
+func (c *healthController) handler(checks func() map[string]HealthCheck) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			report := c.run(r.Context(), checks())
+			rw.Header().Set("Content-Type", "application/json")
+			rw.Header().Set("Cache-Control", "no-store")
+			if report.Status != HealthPass {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			}
+			json.NewEncoder(rw).Encode(report)
+		},
+	)
+}
+
+// run runs every check at once, each bounded by the controller's timeout.
+func (c *healthController) run(ctx context.Context, checks map[string]HealthCheck) HealthReport {
+	report := HealthReport{HealthPass, map[string]CheckResult{}}
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := c.now()
+			err := check(ctx)
+			result := CheckResult{
+				Status:    HealthPass,
+				LatencyMs: float64(c.now().Sub(start)) / float64(time.Millisecond),
+			}
+			if err != nil {
+				result.Status = HealthFail
+				result.Error = err.Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = HealthFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+// PingCheck passes when the pool can hand out a connection that reaches the
+// database.
+func PingCheck(pool *pgxpool.Pool) HealthCheck {
+	return func(ctx context.Context) error {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Release()
+		return conn.Conn().Ping(ctx)
+	}
+}
+
+// SchemaCheck passes when the database has run every migration this build
+// knows about. A database ahead of the build still passes, since during a
+// rolling deploy the new pods migrate while the old ones keep serving.
+func SchemaCheck(pool *pgxpool.Pool) HealthCheck {
+	return schemaCheck(func(ctx context.Context) (int, error) {
+		return SchemaVersion(ctx, pool)
+	}, LatestSchemaVersion())
+}
+
+func schemaCheck(schemaVersion func(ctx context.Context) (int, error), latest int) HealthCheck {
+	return func(ctx context.Context) error {
+		version, err := schemaVersion(ctx)
+		if err != nil {
+			return err
+		} else if version < latest {
+			return fmt.Errorf("schema version is %d, want at least %d", version, latest)
+		}
+		return nil
+	}
+}
+
+// NewHealthController gives each check timeout to finish.
+func NewHealthController(checks HealthChecks, timeout time.Duration) HealthController {
+	return &healthController{
+		checks:  checks,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
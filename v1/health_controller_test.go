@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthController(t *testing.T) {
+	pass := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	get := func(t *testing.T, handler http.Handler) (int, HealthReport) {
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+
+		var report HealthReport
+		if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		return response.Result().StatusCode, report
+	}
+
+	statuses := func(report HealthReport) map[string]string {
+		got := map[string]string{}
+		for name, result := range report.Checks {
+			got[name] = result.Status
+		}
+		return got
+	}
+
+	t.Run("GET readiness when every check passes", func(t *testing.T) {
+		controller := NewHealthController(HealthChecks{Readiness: map[string]HealthCheck{"database": pass, "migrations": pass}}, time.Second)
+
+		gotStatusCode, report := get(t, controller.GetReadiness())
+		if gotStatusCode != 200 {
+			t.Errorf("got status code %d want %d", gotStatusCode, 200)
+		}
+		if report.Status != HealthPass {
+			t.Errorf("got status %s want %s", report.Status, HealthPass)
+		}
+		want := map[string]string{"database": HealthPass, "migrations": HealthPass, "shutdown": HealthPass}
+		if got := statuses(report); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("GET readiness when a check fails", func(t *testing.T) {
+		controller := NewHealthController(HealthChecks{Readiness: map[string]HealthCheck{"database": fail, "migrations": pass}}, time.Second)
+
+		gotStatusCode, report := get(t, controller.GetReadiness())
+		if gotStatusCode != 503 {
+			t.Errorf("got status code %d want %d", gotStatusCode, 503)
+		}
+		want := CheckResult{Status: HealthFail, Error: "connection refused"}
+		got := report.Checks["database"]
+		got.LatencyMs = 0
+		if report.Status != HealthFail || got != want {
+			t.Errorf("got %+v want %+v", report, want)
+		}
+	})
+
+	t.Run("GET readiness when a check hangs", func(t *testing.T) {
+		controller := NewHealthController(HealthChecks{Readiness: map[string]HealthCheck{"database": hang}}, 50*time.Millisecond)
+
+		gotStatusCode, report := get(t, controller.GetReadiness())
+		if gotStatusCode != 503 {
+			t.Errorf("got status code %d want %d", gotStatusCode, 503)
+		}
+		if got := report.Checks["database"]; got.Status != HealthFail || got.LatencyMs < 50 {
+			t.Errorf("got %+v want a failure after at least 50ms", got)
+		}
+	})
+
+	t.Run("GET readiness while draining", func(t *testing.T) {
+		controller := NewHealthController(HealthChecks{Readiness: map[string]HealthCheck{"database": pass}}, time.Second)
+		controller.Drain()
+
+		gotStatusCode, report := get(t, controller.GetReadiness())
+		if gotStatusCode != 503 {
+			t.Errorf("got status code %d want %d", gotStatusCode, 503)
+		}
+		if got := report.Checks["shutdown"]; got.Status != HealthFail || got.Error != ErrDraining.Error() {
+			t.Errorf("got %+v want %s", got, ErrDraining)
+		}
+	})
+
+	t.Run("GET liveness and startup while draining", func(t *testing.T) {
+		controller := NewHealthController(HealthChecks{Startup: map[string]HealthCheck{"migrations": pass}}, time.Second)
+		controller.Drain()
+
+		if gotStatusCode, report := get(t, controller.GetLiveness()); gotStatusCode != 200 || len(report.Checks) != 0 {
+			t.Errorf("got status code %d and %+v want 200 and no checks", gotStatusCode, report)
+		}
+		if gotStatusCode, report := get(t, controller.GetStartup()); gotStatusCode != 200 || statuses(report)["migrations"] != HealthPass {
+			t.Errorf("got status code %d and %+v want 200 and passing migrations", gotStatusCode, report)
+		}
+	})
+
+	t.Run("schema check passes a database at or ahead of the build", func(t *testing.T) {
+		for _, scenario := range []struct {
+			version  int
+			wantPass bool
+		}{
+			{19, false},
+			{20, true},
+			{21, true},
+		} {
+			check := schemaCheck(func(ctx context.Context) (int, error) { return scenario.version, nil }, 20)
+			if err := check(context.Background()); (err == nil) != scenario.wantPass {
+				t.Errorf("schema version %d: got error %v want pass %t", scenario.version, err, scenario.wantPass)
+			}
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TagMerge ...
+type TagMerge struct {
+	From   string `json:"from"`
+	Into   string `json:"into"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ListMerge ...
+type ListMerge struct {
+	From       string  `json:"from"`
+	Into       string  `json:"into"`
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// HousekeepingReport lists suggested merges. The same shape is accepted when
+// applying merges.
+type HousekeepingReport struct {
+	TagMerges  []TagMerge  `json:"tagMerges"`
+	ListMerges []ListMerge `json:"listMerges"`
+}
+
+// HousekeepingResult ...
+type HousekeepingResult struct {
+	Replaced []string `json:"replaced"`
+	Deleted  []string `json:"deleted"`
+}
+
+// FoldTag lower cases a tag and strips its leading # and any diacritics.
+func FoldTag(tag string) string {
+	folded, _, err := transform.String(
+		transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC),
+		strings.ToLower(strings.TrimPrefix(tag, "#")),
+	)
+	if err != nil {
+		return strings.ToLower(strings.TrimPrefix(tag, "#"))
+	}
+	return folded
+}
+
+// EditDistance is the Levenshtein distance between two strings in runes.
+func EditDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
+
+// TagSetSimilarity is the Jaccard similarity of two tag sets.
+func TagSetSimilarity(a, b []string) float64 {
+	set := map[string]bool{}
+	for _, tag := range a {
+		set[tag] = true
+	}
+	union := len(set)
+	both := 0
+	for _, tag := range uniqueTags(b) {
+		if set[tag] {
+			both++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(both) / float64(union)
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// HousekeepingController ...
+type HousekeepingController interface {
+	GetReport() http.Handler
+	ApplyMerges() http.Handler
+}
+
+type housekeepingController struct {
+	logger              Logger
+	housekeepingService HousekeepingService
+}
+
+func (c *housekeepingController) GetReport() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			maxDistance := 1
+			if value := r.URL.Query().Get("distance"); value != "" {
+				var err error
+				if maxDistance, err = strconv.Atoi(value); err != nil || maxDistance < 0 {
+					writeBadRequest(rw, "distance must be a non-negative integer")
+					return
+				}
+			}
+
+			minSimilarity := 0.8
+			if value := r.URL.Query().Get("similarity"); value != "" {
+				var err error
+				if minSimilarity, err = strconv.ParseFloat(value, 64); err != nil || minSimilarity <= 0 || minSimilarity > 1 {
+					writeBadRequest(rw, "similarity must be greater than 0 and at most 1")
+					return
+				}
+			}
+
+			report, err := c.housekeepingService.Report(r.Context(), bucket, maxDistance, minSimilarity)
+			if errors.Is(err, ErrHousekeepingTooLarge) {
+				writeError(rw, http.StatusUnprocessableEntity, err.Error())
+				return
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(report)
+		},
+	)
+}
+
+func (c *housekeepingController) ApplyMerges() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var (
+				merges HousekeepingReport
+				result *HousekeepingResult
+				err    error
+			)
+			if json.NewDecoder(r.Body).Decode(&merges) != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if result, err = c.housekeepingService.Apply(r.Context(), bucket, merges); errors.Is(err, ErrUnknownNamedTagList) {
+				writeBadRequest(rw, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				json.NewEncoder(rw).Encode(result)
+			}
+		},
+	)
+}
+
+// NewHousekeepingController ...
+func NewHousekeepingController(
+	logger Logger,
+	housekeepingService HousekeepingService,
+) HousekeepingController {
+	return &housekeepingController{
+		logger,
+		housekeepingService,
+	}
+}
+
+func singleBucket(rw http.ResponseWriter, r *http.Request) (string, bool) {
+	buckets := r.URL.Query()["bucket"]
+	if len(buckets) < 1 {
+		writeBadRequest(rw, "bucket query parameter is required")
+		return "", false
+	} else if len(buckets) > 1 {
+		writeBadRequest(rw, "no more than one bucket must be supplied")
+		return "", false
+	}
+	return buckets[0], true
+}
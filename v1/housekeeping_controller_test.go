@@ -0,0 +1,256 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubHousekeepingService struct {
+	withBucket        string
+	withMaxDistance   int
+	withMinSimilarity float64
+	withMerges        HousekeepingReport
+	willError         error
+
+	err error
+}
+
+func (s *stubHousekeepingService) Report(ctx context.Context, bucket string, maxDistance int, minSimilarity float64) (*HousekeepingReport, error) {
+	requestMatched := bucket == s.withBucket && maxDistance == s.withMaxDistance && minSimilarity == s.withMinSimilarity
+	if !requestMatched {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got distance %d want %d got similarity %f want %f", bucket, s.withBucket, maxDistance, s.withMaxDistance, minSimilarity, s.withMinSimilarity)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &HousekeepingReport{
+		TagMerges:  []TagMerge{{From: "#photograpy", Into: "#photography", Reason: "edit distance 1"}},
+		ListMerges: []ListMerge{},
+	}, nil
+}
+
+func (s *stubHousekeepingService) Apply(ctx context.Context, bucket string, merges HousekeepingReport) (*HousekeepingResult, error) {
+	requestMatched := bucket == s.withBucket && reflect.DeepEqual(merges, s.withMerges)
+	if !requestMatched {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got merges %+v want %+v", bucket, s.withBucket, merges, s.withMerges)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &HousekeepingResult{Replaced: []string{"2"}, Deleted: []string{"1"}}, nil
+}
+
+func TestHousekeepingController(t *testing.T) {
+	t.Run("GET report", func(t *testing.T) {
+		service := &stubHousekeepingService{
+			withBucket:        "bucket",
+			withMaxDistance:   2,
+			withMinSimilarity: 0.8,
+		}
+		controller := NewHousekeepingController(stubLoggerNew(), service)
+
+		request, _ := http.NewRequest(http.MethodGet, "/housekeeping?bucket=bucket&distance=2", nil)
+		response := httptest.NewRecorder()
+		controller.GetReport().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotReport HousekeepingReport
+		if err := json.NewDecoder(response.Body).Decode(&gotReport); err != nil {
+			t.Fatal(err)
+		}
+
+		wantReport := HousekeepingReport{
+			TagMerges:  []TagMerge{{From: "#photograpy", Into: "#photography", Reason: "edit distance 1"}},
+			ListMerges: []ListMerge{},
+		}
+
+		if !reflect.DeepEqual(gotReport, wantReport) {
+			t.Errorf("got report %+v want %+v", gotReport, wantReport)
+		}
+	})
+
+	t.Run("GET report with bad query parameters", func(t *testing.T) {
+		for _, scenario := range []struct {
+			query            string
+			wantResponseBody map[string]string
+		}{
+			{query: "", wantResponseBody: map[string]string{"error": "bucket query parameter is required"}},
+			{query: "bucket=one&bucket=two", wantResponseBody: map[string]string{"error": "no more than one bucket must be supplied"}},
+			{query: "bucket=one&distance=-1", wantResponseBody: map[string]string{"error": "distance must be a non-negative integer"}},
+			{query: "bucket=one&similarity=2", wantResponseBody: map[string]string{"error": "similarity must be greater than 0 and at most 1"}},
+		} {
+			controller := NewHousekeepingController(stubLoggerNew(), &stubHousekeepingService{})
+
+			request, _ := http.NewRequest(http.MethodGet, "/housekeeping?"+scenario.query, nil)
+			response := httptest.NewRecorder()
+			controller.GetReport().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			var gotResponseBody map[string]string
+			if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotResponseBody, scenario.wantResponseBody) {
+				t.Errorf("got response body %+v want %+v", gotResponseBody, scenario.wantResponseBody)
+			}
+		}
+	})
+
+	t.Run("GET report when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewHousekeepingController(
+			logger,
+			&stubHousekeepingService{
+				withBucket:        "bucket",
+				withMaxDistance:   1,
+				withMinSimilarity: 0.8,
+				willError:         errors.New("there was an error"),
+			},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/housekeeping?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetReport().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+
+	t.Run("GET report on a bucket too large", func(t *testing.T) {
+		controller := NewHousekeepingController(
+			stubLoggerNew(),
+			&stubHousekeepingService{
+				withBucket:        "bucket",
+				withMaxDistance:   1,
+				withMinSimilarity: 0.8,
+				willError:         fmt.Errorf("%w: 1001 named tag lists is more than 1000", ErrHousekeepingTooLarge),
+			},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/housekeeping?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetReport().ServeHTTP(response, request)
+
+		if gotStatusCode := response.Result().StatusCode; gotStatusCode != 422 {
+			t.Errorf("got status code %d want 422", gotStatusCode)
+		}
+		if gotBody, wantBody := response.Body.String(), "{\"error\":\"bucket is too large to report on: 1001 named tag lists is more than 1000\"}\n"; gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("POST merges", func(t *testing.T) {
+		service := &stubHousekeepingService{
+			withBucket: "bucket",
+			withMerges: HousekeepingReport{
+				ListMerges: []ListMerge{{From: "1", Into: "2"}},
+			},
+		}
+		controller := NewHousekeepingController(stubLoggerNew(), service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/housekeeping/merges?bucket=bucket", strings.NewReader("{\"listMerges\":[{\"from\":\"1\",\"into\":\"2\"}]}"))
+		response := httptest.NewRecorder()
+		controller.ApplyMerges().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotResult HousekeepingResult
+		if err := json.NewDecoder(response.Body).Decode(&gotResult); err != nil {
+			t.Fatal(err)
+		}
+
+		wantResult := HousekeepingResult{Replaced: []string{"2"}, Deleted: []string{"1"}}
+
+		if !reflect.DeepEqual(gotResult, wantResult) {
+			t.Errorf("got result %+v want %+v", gotResult, wantResult)
+		}
+	})
+
+	t.Run("POST merges when request body malformed", func(t *testing.T) {
+		controller := NewHousekeepingController(stubLoggerNew(), &stubHousekeepingService{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/housekeeping/merges?bucket=bucket", strings.NewReader("{\"garbalooy\":\"gook"))
+		response := httptest.NewRecorder()
+		controller.ApplyMerges().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST merges with unknown list", func(t *testing.T) {
+		controller := NewHousekeepingController(
+			stubLoggerNew(),
+			&stubHousekeepingService{
+				willError: fmt.Errorf("%w: %s", ErrUnknownNamedTagList, "4"),
+			},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/housekeeping/merges?bucket=bucket", strings.NewReader("{}"))
+		response := httptest.NewRecorder()
+		controller.ApplyMerges().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotResponseBody map[string]string
+		if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+			t.Fatal(err)
+		}
+
+		wantResponseBody := map[string]string{"error": "named tag list is not in bucket: 4"}
+
+		if !reflect.DeepEqual(gotResponseBody, wantResponseBody) {
+			t.Errorf("got response body %+v want %+v", gotResponseBody, wantResponseBody)
+		}
+	})
+}
@@ -0,0 +1,312 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownNamedTagList ...
+var ErrUnknownNamedTagList = errors.New("named tag list is not in bucket")
+
+// ErrHousekeepingTooLarge ...
+var ErrHousekeepingTooLarge = errors.New("bucket is too large to report on")
+
+// A report compares tags and lists in pairs, so it refuses buckets past these.
+const (
+	maxHousekeepingTags  = 2000
+	maxHousekeepingLists = 1000
+)
+
+// HousekeepingService ...
+type HousekeepingService interface {
+	Report(ctx context.Context, bucket string, maxDistance int, minSimilarity float64) (*HousekeepingReport, error)
+	Apply(ctx context.Context, bucket string, merges HousekeepingReport) (*HousekeepingResult, error)
+}
+
+type housekeepingService struct {
+	namedTagListRepository NamedTagListRepository
+	unitOfWork             UnitOfWork
+}
+
+func (s *housekeepingService) Report(ctx context.Context, bucket string, maxDistance int, minSimilarity float64) (*HousekeepingReport, error) {
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+	if len(namedTagLists) > maxHousekeepingLists {
+		return nil, fmt.Errorf("%w: %d named tag lists is more than %d", ErrHousekeepingTooLarge, len(namedTagLists), maxHousekeepingLists)
+	}
+
+	counts := map[string]int{}
+	for _, namedTagList := range namedTagLists {
+		for _, tag := range uniqueTags(namedTagList.Tags) {
+			counts[tag]++
+		}
+	}
+	tags := []string{}
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxHousekeepingTags {
+		return nil, fmt.Errorf("%w: %d distinct tags is more than %d", ErrHousekeepingTooLarge, len(tags), maxHousekeepingTags)
+	}
+	sort.Slice(tags, func(a, b int) bool {
+		if counts[tags[a]] != counts[tags[b]] {
+			return counts[tags[a]] > counts[tags[b]]
+		}
+		return tags[a] < tags[b]
+	})
+
+	report := &HousekeepingReport{
+		TagMerges:  []TagMerge{},
+		ListMerges: []ListMerge{},
+	}
+
+	folded := make([]string, len(tags))
+	for i, tag := range tags {
+		folded[i] = FoldTag(tag)
+	}
+	tagCandidates := tagMergeCandidates(folded, maxDistance)
+	merged := map[string]bool{}
+	for i, into := range tags {
+		if merged[into] {
+			continue
+		}
+		for _, j := range tagCandidates[i] {
+			from := tags[j]
+			if merged[from] {
+				continue
+			}
+			var reason string
+			if folded[i] == folded[j] {
+				reason = "identical after case and diacritic folding"
+			} else if distance := EditDistance(folded[i], folded[j]); distance <= maxDistance {
+				reason = fmt.Sprintf("edit distance %d", distance)
+			} else {
+				continue
+			}
+			merged[from] = true
+			report.TagMerges = append(report.TagMerges, TagMerge{From: from, Into: into, Reason: reason})
+		}
+	}
+
+	tagSets := make([][]string, len(namedTagLists))
+	for i, namedTagList := range namedTagLists {
+		tagSets[i] = uniqueTags(namedTagList.Tags)
+	}
+	listCandidates := listMergeCandidates(tagSets, minSimilarity)
+	for i := range namedTagLists {
+		for _, j := range listCandidates[i] {
+			similarity := TagSetSimilarity(namedTagLists[i].Tags, namedTagLists[j].Tags)
+			if similarity < minSimilarity {
+				continue
+			}
+			into, from := namedTagLists[i], namedTagLists[j]
+			if len(tagSets[j]) > len(tagSets[i]) {
+				into, from = from, into
+			}
+			report.ListMerges = append(report.ListMerges, ListMerge{From: from.ID, Into: into.ID, Similarity: similarity})
+		}
+	}
+
+	return report, nil
+}
+
+// tagMergeCandidates returns, for each folded tag, the later tags worth
+// measuring against it: those within maxDistance of its length that also share
+// its first or last letter. A pair that differs at both ends is not suggested.
+func tagMergeCandidates(folded []string, maxDistance int) [][]int {
+	type block struct {
+		length int
+		edge   rune
+		last   bool
+	}
+	blocks := map[block][]int{}
+	keys := make([][2]block, len(folded))
+	longest := 0
+	for i, tag := range folded {
+		runes := []rune(tag)
+		var first, last rune
+		if len(runes) > 0 {
+			first, last = runes[0], runes[len(runes)-1]
+		}
+		keys[i] = [2]block{{len(runes), first, false}, {len(runes), last, true}}
+		for _, key := range keys[i] {
+			blocks[key] = append(blocks[key], i)
+		}
+		if len(runes) > longest {
+			longest = len(runes)
+		}
+	}
+
+	candidates := make([][]int, len(folded))
+	for i := range folded {
+		seen := map[int]bool{}
+		for _, key := range keys[i] {
+			from, to := key.length-maxDistance, key.length+maxDistance
+			if from < 0 {
+				from = 0
+			}
+			if to > longest || to < key.length {
+				to = longest
+			}
+			for length := from; length <= to; length++ {
+				for _, j := range blocks[block{length, key.edge, key.last}] {
+					if j > i && !seen[j] {
+						seen[j] = true
+						candidates[i] = append(candidates[i], j)
+					}
+				}
+			}
+		}
+		sort.Ints(candidates[i])
+	}
+	return candidates
+}
+
+// listMergeCandidates returns, for each tag set, the later sets that could
+// reach minSimilarity with it: those sharing a tag whose sizes are close enough.
+// Empty sets are alike, so they are candidates for each other.
+func listMergeCandidates(tagSets [][]string, minSimilarity float64) [][]int {
+	byTag := map[string][]int{}
+	empty := []int{}
+	for i, tags := range tagSets {
+		if len(tags) == 0 {
+			empty = append(empty, i)
+		}
+		for _, tag := range tags {
+			byTag[tag] = append(byTag[tag], i)
+		}
+	}
+
+	candidates := make([][]int, len(tagSets))
+	for i, tags := range tagSets {
+		sharing := [][]int{empty}
+		if len(tags) > 0 {
+			sharing = sharing[:0]
+			for _, tag := range tags {
+				sharing = append(sharing, byTag[tag])
+			}
+		}
+		seen := map[int]bool{}
+		for _, others := range sharing {
+			for _, j := range others {
+				if j <= i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				smaller, larger := len(tags), len(tagSets[j])
+				if smaller > larger {
+					smaller, larger = larger, smaller
+				}
+				if larger == 0 || float64(smaller)/float64(larger) >= minSimilarity {
+					candidates[i] = append(candidates[i], j)
+				}
+			}
+		}
+		sort.Ints(candidates[i])
+	}
+	return candidates
+}
+
+// Apply makes every merge or none of them.
+func (s *housekeepingService) Apply(ctx context.Context, bucket string, merges HousekeepingReport) (*HousekeepingResult, error) {
+	var result *HousekeepingResult
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) (err error) {
+		result, err = s.apply(ctx, bucket, merges)
+		return err
+	})
+	return result, err
+}
+
+func (s *housekeepingService) apply(ctx context.Context, bucket string, merges HousekeepingReport) (*HousekeepingResult, error) {
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*NamedTagList{}
+	for i := range namedTagLists {
+		byID[namedTagLists[i].ID] = &namedTagLists[i]
+	}
+	changed := map[string]bool{}
+	deleted := map[string]bool{}
+
+	for _, merge := range merges.ListMerges {
+		from, into := byID[merge.From], byID[merge.Into]
+		if from == nil || deleted[merge.From] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNamedTagList, merge.From)
+		} else if into == nil || deleted[merge.Into] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNamedTagList, merge.Into)
+		} else if merge.From == merge.Into {
+			continue
+		}
+		into.Tags = uniqueTags(append(into.Tags, from.Tags...))
+		changed[merge.Into] = true
+		deleted[merge.From] = true
+	}
+
+	tagMerges := map[string]string{}
+	for _, merge := range merges.TagMerges {
+		if merge.From != merge.Into {
+			tagMerges[merge.From] = merge.Into
+		}
+	}
+	for i := range namedTagLists {
+		namedTagList := &namedTagLists[i]
+		if deleted[namedTagList.ID] {
+			continue
+		}
+		tags := make([]string, len(namedTagList.Tags))
+		for j, tag := range namedTagList.Tags {
+			tags[j] = resolveTagMerge(tagMerges, tag)
+			if tags[j] != tag {
+				changed[namedTagList.ID] = true
+			}
+		}
+		namedTagList.Tags = uniqueTags(tags)
+	}
+
+	result := &HousekeepingResult{
+		Replaced: []string{},
+		Deleted:  []string{},
+	}
+	for _, namedTagList := range namedTagLists {
+		if deleted[namedTagList.ID] {
+			result.Deleted = append(result.Deleted, namedTagList.ID)
+		} else if changed[namedTagList.ID] {
+			if err := s.namedTagListRepository.ReplaceByIds(ctx, []string{namedTagList.ID}, namedTagList); err != nil {
+				return nil, err
+			}
+			result.Replaced = append(result.Replaced, namedTagList.ID)
+		}
+	}
+	if len(result.Deleted) > 0 {
+		if err := s.namedTagListRepository.DeleteByIds(ctx, result.Deleted); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// NewHousekeepingService ...
+func NewHousekeepingService(namedTagListRepository NamedTagListRepository, unitOfWork UnitOfWork) HousekeepingService {
+	return &housekeepingService{
+		namedTagListRepository,
+		unitOfWork,
+	}
+}
+
+func resolveTagMerge(tagMerges map[string]string, tag string) string {
+	seen := map[string]bool{tag: true}
+	for {
+		into, ok := tagMerges[tag]
+		if !ok || seen[into] {
+			return tag
+		}
+		seen[into] = true
+		tag = into
+	}
+}
@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type stubNamedTagListRepositoryForHousekeeping struct {
+	NamedTagListRepository
+
+	withNamedTagLists []NamedTagList
+	willError         bool
+
+	replaced          []NamedTagList
+	deleted           []string
+	outsideUnitOfWork bool
+}
+
+func (r *stubNamedTagListRepositoryForHousekeeping) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	namedTagLists := make([]NamedTagList, len(r.withNamedTagLists))
+	copy(namedTagLists, r.withNamedTagLists)
+	return namedTagLists, nil
+}
+
+func (r *stubNamedTagListRepositoryForHousekeeping) ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	r.replaced = append(r.replaced, ntl)
+	return nil
+}
+
+func (r *stubNamedTagListRepositoryForHousekeeping) DeleteByIds(ctx context.Context, ids []string) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	r.deleted = append(r.deleted, ids...)
+	return nil
+}
+
+func TestHousekeepingService(t *testing.T) {
+	newRepository := func() *stubNamedTagListRepositoryForHousekeeping {
+		return &stubNamedTagListRepositoryForHousekeeping{
+			withNamedTagLists: []NamedTagList{
+				{ID: "1", Name: "one", Tags: []string{"#photography", "#cafe", "#windy"}},
+				{ID: "2", Name: "two", Tags: []string{"#photography", "#cafe", "#windy", "#tdd"}},
+				{ID: "3", Name: "three", Tags: []string{"#photograpy", "#Windy", "#café"}},
+			},
+		}
+	}
+
+	t.Run("report", func(t *testing.T) {
+		service := NewHousekeepingService(newRepository(), &stubUnitOfWork{})
+
+		got, err := service.Report(context.Background(), "bucket", 1, 0.6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &HousekeepingReport{
+			TagMerges: []TagMerge{
+				{From: "#café", Into: "#cafe", Reason: "identical after case and diacritic folding"},
+				{From: "#photograpy", Into: "#photography", Reason: "edit distance 1"},
+				{From: "#Windy", Into: "#windy", Reason: "identical after case and diacritic folding"},
+			},
+			ListMerges: []ListMerge{
+				{From: "1", Into: "2", Similarity: 0.75},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("report on a large bucket", func(t *testing.T) {
+		namedTagLists := []NamedTagList{}
+		seed := 1
+		for i := 0; i < maxHousekeepingLists; i++ {
+			tags := []string{}
+			for len(tags) < 2 {
+				tag := "#"
+				for k := 0; k < 6; k++ {
+					seed = (seed*1103515245 + 12345) % (1 << 31)
+					tag += string(rune('a' + seed>>16%26))
+				}
+				tags = append(tags, tag)
+			}
+			namedTagLists = append(namedTagLists, NamedTagList{ID: fmt.Sprint(i), Tags: tags})
+		}
+
+		folded := []string{}
+		tagSets := [][]string{}
+		for _, namedTagList := range namedTagLists {
+			for _, tag := range namedTagList.Tags {
+				folded = append(folded, FoldTag(tag))
+			}
+			tagSets = append(tagSets, uniqueTags(namedTagList.Tags))
+		}
+		tagPairs, listPairs := 0, 0
+		for _, candidates := range tagMergeCandidates(folded, 1) {
+			tagPairs += len(candidates)
+		}
+		for _, candidates := range listMergeCandidates(tagSets, 0.8) {
+			listPairs += len(candidates)
+		}
+		if n := len(folded); tagPairs > n*n/20 {
+			t.Errorf("got %d tag pairs to compare for %d tags", tagPairs, n)
+		}
+		if listPairs > len(tagSets) {
+			t.Errorf("got %d list pairs to compare for %d lists", listPairs, len(tagSets))
+		}
+
+		repository := &stubNamedTagListRepositoryForHousekeeping{withNamedTagLists: namedTagLists}
+		if _, err := NewHousekeepingService(repository, &stubUnitOfWork{}).Report(context.Background(), "bucket", 1, 0.8); err != nil {
+			t.Fatal(err)
+		}
+
+		repository.withNamedTagLists = append(namedTagLists, NamedTagList{ID: "one too many"})
+		if _, err := NewHousekeepingService(repository, &stubUnitOfWork{}).Report(context.Background(), "bucket", 1, 0.8); !errors.Is(err, ErrHousekeepingTooLarge) {
+			t.Errorf("got error %v want %v", err, ErrHousekeepingTooLarge)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		repository := newRepository()
+		service := NewHousekeepingService(repository, &stubUnitOfWork{})
+
+		got, err := service.Apply(context.Background(), "bucket", HousekeepingReport{
+			TagMerges: []TagMerge{
+				{From: "#photograpy", Into: "#photography"},
+				{From: "#Windy", Into: "#windy"},
+				{From: "#café", Into: "#cafe"},
+			},
+			ListMerges: []ListMerge{
+				{From: "1", Into: "2"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &HousekeepingResult{
+			Replaced: []string{"2", "3"},
+			Deleted:  []string{"1"},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		wantReplaced := []NamedTagList{
+			{ID: "2", Name: "two", Tags: []string{"#photography", "#cafe", "#windy", "#tdd"}},
+			{ID: "3", Name: "three", Tags: []string{"#photography", "#windy", "#cafe"}},
+		}
+
+		if !reflect.DeepEqual(repository.replaced, wantReplaced) {
+			t.Errorf("got replaced %+v want %+v", repository.replaced, wantReplaced)
+		}
+
+		wantDeleted := []string{"1"}
+
+		if !reflect.DeepEqual(repository.deleted, wantDeleted) {
+			t.Errorf("got deleted %+v want %+v", repository.deleted, wantDeleted)
+		}
+
+		if repository.outsideUnitOfWork {
+			t.Error("got writes outside the unit of work")
+		}
+	})
+
+	t.Run("apply with list from another bucket", func(t *testing.T) {
+		repository := newRepository()
+		service := NewHousekeepingService(repository, &stubUnitOfWork{})
+
+		_, gotErr := service.Apply(context.Background(), "bucket", HousekeepingReport{
+			ListMerges: []ListMerge{
+				{From: "4", Into: "2"},
+			},
+		})
+
+		if !errors.Is(gotErr, ErrUnknownNamedTagList) {
+			t.Fatalf("got error %v want %v", gotErr, ErrUnknownNamedTagList)
+		}
+
+		if repository.replaced != nil || repository.deleted != nil {
+			t.Errorf("got replaced %+v deleted %+v want no writes", repository.replaced, repository.deleted)
+		}
+	})
+
+	t.Run("report when repository has error", func(t *testing.T) {
+		service := NewHousekeepingService(&stubNamedTagListRepositoryForHousekeeping{willError: true}, &stubUnitOfWork{})
+
+		_, gotErr := service.Report(context.Background(), "bucket", 1, 0.8)
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
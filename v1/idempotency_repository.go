@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// IdempotentResponse is what a request sent with an Idempotency-Key got back,
+// kept so that a retry of the same request can get it again. RequestHash
+// tells a retry apart from a different request reusing the key.
+type IdempotentResponse struct {
+	Caller      string
+	Bucket      string
+	Key         string
+	RequestHash string
+	Body        []byte
+}
+
+// ErrIdempotencyKeyInUse means another request saved a response under the
+// same key first.
+var ErrIdempotencyKeyInUse = errors.New("idempotency key is in use by another request")
+
+// IdempotencyRepository keeps responses by caller, bucket and key for as long
+// as its time-to-live, after which a key may be used afresh.
+type IdempotencyRepository interface {
+	Find(ctx context.Context, caller string, bucket string, key string) (*IdempotentResponse, error)
+	Save(ctx context.Context, response IdempotentResponse) error
+}
+
+type idempotencyRepository struct {
+	db  database
+	ttl time.Duration
+}
+
+func (r *idempotencyRepository) Find(ctx context.Context, caller string, bucket string, key string) (*IdempotentResponse, error) {
+	response := IdempotentResponse{Caller: caller, Bucket: bucket, Key: key}
+	err := r.db.queryRow(
+		ctx,
+		"Find",
+		"select \"request_hash\", \"body\" from idempotency_keys where \"caller\" = $1 and \"bucket\" = $2 and \"key\" = $3 and \"expires_at\" > $4",
+		caller,
+		bucket,
+		key,
+		time.Now(),
+	).Scan(&response.RequestHash, &response.Body)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Save also forgets the caller's expired keys for the bucket so that the
+// table only grows with keys still in use. It leaves a response already saved
+// under the key alone and reports ErrIdempotencyKeyInUse.
+func (r *idempotencyRepository) Save(ctx context.Context, response IdempotentResponse) error {
+	now := time.Now()
+	if err := r.db.exec(
+		ctx,
+		"Save",
+		"delete from idempotency_keys where \"caller\" = $1 and \"bucket\" = $2 and \"expires_at\" <= $3",
+		response.Caller,
+		response.Bucket,
+		now,
+	); err != nil {
+		return err
+	}
+	var key string
+	err := r.db.queryRow(
+		ctx,
+		"Save",
+		"insert into idempotency_keys (\"caller\", \"bucket\", \"key\", \"request_hash\", \"body\", \"expires_at\") values ($1, $2, $3, $4, $5, $6) on conflict do nothing returning \"key\"",
+		response.Caller,
+		response.Bucket,
+		response.Key,
+		response.RequestHash,
+		response.Body,
+		now.Add(r.ttl),
+	).Scan(&key)
+	if err == pgx.ErrNoRows {
+		return ErrIdempotencyKeyInUse
+	}
+	return err
+}
+
+// NewIdempotencyRepository joins the unit of work in ctx, if any, so that a
+// response is only kept when what it reports commits.
+func NewIdempotencyRepository(pool *pgxpool.Pool, timeouts Timeouts, ttl time.Duration) IdempotencyRepository {
+	return &idempotencyRepository{database{pool, timeouts}, ttl}
+}
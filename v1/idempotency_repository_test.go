@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	response := IdempotentResponse{
+		Caller:      "apiKey:1",
+		Bucket:      "blue",
+		Key:         "retry-me",
+		RequestHash: "abc",
+		Body:        []byte("{\"id\":\"1\"}"),
+	}
+
+	t.Run("find a saved response", func(t *testing.T) {
+		repository := NewIdempotencyRepository(pool, Timeouts{}, time.Hour)
+		assertutil.NotError(t, repository.Save(context.Background(), response))
+
+		got, err := repository.Find(context.Background(), "apiKey:1", "blue", "retry-me")
+		assertutil.NotError(t, err)
+		if !reflect.DeepEqual(got, &response) {
+			t.Errorf("got %+v want %+v", got, &response)
+		}
+
+		got, err = repository.Find(context.Background(), "apiKey:2", "blue", "retry-me")
+		assertutil.NotError(t, err)
+		if got != nil {
+			t.Errorf("got %+v for another caller want nothing", got)
+		}
+	})
+
+	t.Run("keep the response saved first", func(t *testing.T) {
+		repository := NewIdempotencyRepository(pool, Timeouts{}, time.Hour)
+		first := response
+		first.Bucket = "orange"
+		assertutil.NotError(t, repository.Save(context.Background(), first))
+
+		second := first
+		second.Body = []byte("{\"id\":\"2\"}")
+		if gotErr := repository.Save(context.Background(), second); !errors.Is(gotErr, ErrIdempotencyKeyInUse) {
+			t.Errorf("got error %v want %v", gotErr, ErrIdempotencyKeyInUse)
+		}
+
+		got, err := repository.Find(context.Background(), "apiKey:1", "orange", "retry-me")
+		assertutil.NotError(t, err)
+		if !reflect.DeepEqual(got, &first) {
+			t.Errorf("got %+v want %+v", got, &first)
+		}
+	})
+
+	t.Run("an expired key may be used again", func(t *testing.T) {
+		expired := response
+		expired.Bucket = "red"
+		assertutil.NotError(t, NewIdempotencyRepository(pool, Timeouts{}, -time.Second).Save(context.Background(), expired))
+
+		got, err := NewIdempotencyRepository(pool, Timeouts{}, time.Hour).Find(context.Background(), "apiKey:1", "red", "retry-me")
+		assertutil.NotError(t, err)
+		if got != nil {
+			t.Errorf("got %+v want nothing", got)
+		}
+
+		assertutil.NotError(t, NewIdempotencyRepository(pool, Timeouts{}, time.Hour).Save(context.Background(), expired))
+	})
+
+	t.Run("a unit of work that fails keeps no response", func(t *testing.T) {
+		repository := NewIdempotencyRepository(pool, Timeouts{}, time.Hour)
+		undone := response
+		undone.Bucket = "green"
+
+		err := NewUnitOfWork(pool, Timeouts{}).Do(context.Background(), func(ctx context.Context) error {
+			if err := repository.Save(ctx, undone); err != nil {
+				return err
+			}
+			return errors.New("there was an error")
+		})
+		if err == nil {
+			t.Fatal("got no error")
+		}
+
+		got, err := repository.Find(context.Background(), "apiKey:1", "green", "retry-me")
+		assertutil.NotError(t, err)
+		if got != nil {
+			t.Errorf("got %+v want nothing", got)
+		}
+	})
+}
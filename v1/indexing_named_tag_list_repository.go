@@ -0,0 +1,77 @@
+package v1
+
+import "context"
+
+type indexingNamedTagListRepository struct {
+	NamedTagListRepository
+
+	tagIndex TagIndex
+}
+
+func (r *indexingNamedTagListRepository) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
+	if err := r.NamedTagListRepository.Create(ctx, bucket, namedTagList); err != nil {
+		return err
+	}
+	afterCommit(ctx, func() { r.tagIndex.Put(bucket, namedTagList) })
+	return nil
+}
+
+func (r *indexingNamedTagListRepository) ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error {
+	if err := r.NamedTagListRepository.ReplaceByIds(ctx, ids, ntl); err != nil {
+		return err
+	}
+	afterCommit(ctx, func() { r.tagIndex.Replace(ids, ntl) })
+	return nil
+}
+
+func (r *indexingNamedTagListRepository) Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	created, err := r.NamedTagListRepository.Upsert(ctx, bucket, namedTagList)
+	if err != nil {
+		return false, err
+	}
+	afterCommit(ctx, func() {
+		if created {
+			r.tagIndex.Put(bucket, namedTagList)
+		} else {
+			r.tagIndex.Replace([]string{namedTagList.ID}, namedTagList)
+		}
+	})
+	return created, nil
+}
+
+func (r *indexingNamedTagListRepository) DeleteAll(ctx context.Context, buckets []string) error {
+	if err := r.NamedTagListRepository.DeleteAll(ctx, buckets); err != nil {
+		return err
+	}
+	afterCommit(ctx, func() { r.tagIndex.DeleteAll(buckets) })
+	return nil
+}
+
+func (r *indexingNamedTagListRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	if err := r.NamedTagListRepository.DeleteByIds(ctx, ids); err != nil {
+		return err
+	}
+	afterCommit(ctx, func() { r.tagIndex.DeleteByIds(ids) })
+	return nil
+}
+
+func (r *indexingNamedTagListRepository) MoveByIds(ctx context.Context, ids []string, bucket string) error {
+	if err := r.NamedTagListRepository.MoveByIds(ctx, ids, bucket); err != nil {
+		return err
+	}
+	afterCommit(ctx, func() { r.tagIndex.Move(ids, bucket) })
+	return nil
+}
+
+// NewIndexingNamedTagListRepository wraps a repository so that every
+// successful write is also applied to the tag index, once its transaction has
+// committed.
+func NewIndexingNamedTagListRepository(
+	namedTagListRepository NamedTagListRepository,
+	tagIndex TagIndex,
+) NamedTagListRepository {
+	return &indexingNamedTagListRepository{
+		namedTagListRepository,
+		tagIndex,
+	}
+}
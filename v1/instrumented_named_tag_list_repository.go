@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+	"time"
+)
+
+type instrumentedNamedTagListRepository struct {
+	NamedTagListRepository
+
+	metrics Metrics
+	now     func() time.Time
+}
+
+func (r *instrumentedNamedTagListRepository) observe(method string, start time.Time, err error) {
+	r.metrics.ObserveRepositoryCall("namedTagLists", method, r.now().Sub(start), err)
+}
+
+func (r *instrumentedNamedTagListRepository) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	start := r.now()
+	namedTagLists, err := r.NamedTagListRepository.FindAll(ctx, buckets)
+	r.observe("FindAll", start, err)
+	return namedTagLists, err
+}
+
+func (r *instrumentedNamedTagListRepository) FindPage(ctx context.Context, buckets []string, after string, limit int) ([]NamedTagList, error) {
+	start := r.now()
+	namedTagLists, err := r.NamedTagListRepository.FindPage(ctx, buckets, after, limit)
+	r.observe("FindPage", start, err)
+	return namedTagLists, err
+}
+
+func (r *instrumentedNamedTagListRepository) FindByIds(ctx context.Context, ids []string) ([]BucketedNamedTagList, error) {
+	start := r.now()
+	namedTagLists, err := r.NamedTagListRepository.FindByIds(ctx, ids)
+	r.observe("FindByIds", start, err)
+	return namedTagLists, err
+}
+
+func (r *instrumentedNamedTagListRepository) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
+	start := r.now()
+	err := r.NamedTagListRepository.Create(ctx, bucket, namedTagList)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *instrumentedNamedTagListRepository) ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error {
+	start := r.now()
+	err := r.NamedTagListRepository.ReplaceByIds(ctx, ids, ntl)
+	r.observe("ReplaceByIds", start, err)
+	return err
+}
+
+func (r *instrumentedNamedTagListRepository) Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	start := r.now()
+	created, err := r.NamedTagListRepository.Upsert(ctx, bucket, namedTagList)
+	r.observe("Upsert", start, err)
+	return created, err
+}
+
+func (r *instrumentedNamedTagListRepository) DeleteAll(ctx context.Context, buckets []string) error {
+	start := r.now()
+	err := r.NamedTagListRepository.DeleteAll(ctx, buckets)
+	r.observe("DeleteAll", start, err)
+	return err
+}
+
+func (r *instrumentedNamedTagListRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	start := r.now()
+	err := r.NamedTagListRepository.DeleteByIds(ctx, ids)
+	r.observe("DeleteByIds", start, err)
+	return err
+}
+
+func (r *instrumentedNamedTagListRepository) MoveByIds(ctx context.Context, ids []string, bucket string) error {
+	start := r.now()
+	err := r.NamedTagListRepository.MoveByIds(ctx, ids, bucket)
+	r.observe("MoveByIds", start, err)
+	return err
+}
+
+// NewInstrumentedNamedTagListRepository wraps a repository so that the
+// latency of every call is recorded in metrics.
+func NewInstrumentedNamedTagListRepository(
+	namedTagListRepository NamedTagListRepository,
+	metrics Metrics,
+) NamedTagListRepository {
+	return &instrumentedNamedTagListRepository{
+		namedTagListRepository,
+		metrics,
+		time.Now,
+	}
+}
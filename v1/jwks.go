@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownSigningKey ...
+var ErrUnknownSigningKey = errors.New("no signing key matches the token")
+
+// KeySet ...
+type KeySet interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks caches the signing keys published at a file path or URL. Keys are
+// reloaded once the cache is older than ttl, and early when a token names a
+// key we have not seen, so that a rotated key is picked up without waiting
+// out the ttl. Reloads, failed or not, are spaced by minRefresh to keep
+// garbage tokens and a broken issuer from hammering it.
+// Only one reload runs at a time and never under the lock, so that a slow
+// issuer holds up no token whose key is already cached.
+type jwks struct {
+	location   string
+	ttl        time.Duration
+	minRefresh time.Duration
+	now        func() time.Time
+	client     *http.Client
+
+	mutex       sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	refreshing  chan struct{}
+}
+
+func (s *jwks) Key(kid string) (crypto.PublicKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	_, known := s.lookup(kid)
+	due := s.keys == nil || now.Sub(s.fetchedAt) >= s.ttl || !known
+	if due && s.refreshing == nil && (s.attemptedAt.IsZero() || now.Sub(s.attemptedAt) >= s.minRefresh) {
+		s.refresh(now)
+	} else if s.keys == nil && s.refreshing != nil {
+		refreshing := s.refreshing
+		s.mutex.Unlock()
+		<-refreshing
+		s.mutex.Lock()
+	}
+
+	key, ok := s.lookup(kid)
+	if !ok && s.keys == nil && s.lastErr != nil {
+		return nil, s.lastErr
+	} else if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	return key, nil
+}
+
+// refresh reloads the keys with the lock released, keeping the old keys when
+// the reload fails. It is called and returns with the lock held.
+func (s *jwks) refresh(now time.Time) {
+	refreshing := make(chan struct{})
+	s.refreshing = refreshing
+	s.attemptedAt = now
+	s.mutex.Unlock()
+
+	keys, err := s.fetch()
+
+	s.mutex.Lock()
+	if err == nil {
+		s.keys = keys
+		s.fetchedAt = s.now()
+	}
+	s.lastErr = err
+	s.refreshing = nil
+	close(refreshing)
+}
+
+// lookup falls back to the only key in the set for tokens without a kid.
+func (s *jwks) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *jwks) fetch() (map[string]crypto.PublicKey, error) {
+	body, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("parse jwks from %s: %s", s.location, err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse jwks key %s from %s: %s", jwk.Kid, s.location, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (s *jwks) read() ([]byte, error) {
+	if !strings.HasPrefix(s.location, "http://") && !strings.HasPrefix(s.location, "https://") {
+		return ioutil.ReadFile(s.location)
+	}
+
+	response, err := s.client.Get(s.location)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks from %s: status %d", s.location, response.StatusCode)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// NewJWKS ...
+func NewJWKS(location string, ttl time.Duration) KeySet {
+	return &jwks{
+		location:   location,
+		ttl:        ttl,
+		minRefresh: time.Minute,
+		now:        time.Now,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
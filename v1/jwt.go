@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	// Register the hashes named by the supported algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ErrInvalidToken ...
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+// jwtLeeway absorbs clock skew between us and the issuer.
+const jwtLeeway = time.Minute
+
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// JWTConfig says which tokens to trust and which claims carry the user ID and
+// the user's bucket scopes. Scopes use the same permission:bucket form as API
+// keys and may be a JSON array or a space separated string.
+type JWTConfig struct {
+	Issuer      string
+	Audience    string
+	UserClaim   string
+	ScopesClaim string
+}
+
+// JWTVerifier ...
+type JWTVerifier interface {
+	Verify(ctx context.Context, token string) (*Caller, error)
+}
+
+type jwtVerifier struct {
+	config JWTConfig
+	keySet KeySet
+	now    func() time.Time
+}
+
+func (v *jwtVerifier) Verify(ctx context.Context, token string) (*Caller, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, header.Alg)
+	}
+
+	key, err := v.keySet.Key(header.Kid)
+	if errors.Is(err, ErrUnknownSigningKey) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	digest := hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, hash, digest.Sum(nil), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims map[string]interface{}
+	if err = decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims[v.config.UserClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.config.UserClaim)
+	}
+	userID, err := ParseID(subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s claim must be a UUID", ErrInvalidToken, v.config.UserClaim)
+	}
+	email, _ := claims["email"].(string)
+	return &Caller{
+		User:   &User{ID: userID, Email: email},
+		Scopes: scopesClaim(claims[v.config.ScopesClaim]),
+	}, nil
+}
+
+// checkClaims trusts no token at all when the issuer or audience is not
+// configured, rather than taking a missing claim for a match.
+func (v *jwtVerifier) checkClaims(claims map[string]interface{}) error {
+	if v.config.Issuer == "" || v.config.Audience == "" {
+		return fmt.Errorf("%w: no issuer and audience are configured", ErrInvalidToken)
+	}
+	if issuer, _ := claims["iss"].(string); issuer != v.config.Issuer {
+		return fmt.Errorf("%w: issuer %s is not trusted", ErrInvalidToken, issuer)
+	}
+
+	audiences := []interface{}{claims["aud"]}
+	if list, ok := claims["aud"].([]interface{}); ok {
+		audiences = list
+	}
+	audienceMatched := false
+	for _, audience := range audiences {
+		audienceMatched = audienceMatched || audience == v.config.Audience
+	}
+	if !audienceMatched {
+		return fmt.Errorf("%w: audience does not include %s", ErrInvalidToken, v.config.Audience)
+	}
+
+	now := v.now()
+	expiry, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	} else if now.After(time.Unix(int64(expiry), 0).Add(jwtLeeway)) {
+		return fmt.Errorf("%w: token has expired", ErrInvalidToken)
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(notBefore), 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	return nil
+}
+
+func verifySignature(algorithm string, key crypto.PublicKey, hash crypto.Hash, digest []byte, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(algorithm, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(algorithm, "ES") || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil || json.Unmarshal(bytes, v) != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	return nil
+}
+
+func scopesClaim(claim interface{}) []string {
+	scopes := []string{}
+	switch claim := claim.(type) {
+	case string:
+		scopes = strings.Fields(claim)
+	case []interface{}:
+		for _, scope := range claim {
+			if scope, ok := scope.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// NewJWTVerifier ...
+func NewJWTVerifier(config JWTConfig, keySet KeySet) JWTVerifier {
+	return &jwtVerifier{
+		config,
+		keySet,
+		time.Now,
+	}
+}
@@ -0,0 +1,309 @@
+package v1
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signJWT(t *testing.T, algorithm string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := jwtAlgorithms[algorithm]
+	digest := hash.New()
+	digest.Write([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(nil)); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]crypto.PublicKey) {
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	document := map[string][]jsonWebKey{"keys": {}}
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			document["keys"] = append(document["keys"], jsonWebKey{Kid: kid, Kty: "RSA", Use: "sig", N: encode(key.N), E: encode(big.NewInt(int64(key.E)))})
+		case *ecdsa.PublicKey:
+			document["keys"] = append(document["keys"], jsonWebKey{Kid: kid, Kty: "EC", Crv: key.Curve.Params().Name, X: encode(key.X), Y: encode(key.Y)})
+		}
+	}
+	bytes, _ := json.Marshal(document)
+	if err := ioutil.WriteFile(path, bytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey})
+
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	verifier := &jwtVerifier{
+		JWTConfig{Issuer: "https://issuer.example.com", Audience: "hashbang", UserClaim: "sub", ScopesClaim: "hashbang_scopes"},
+		NewJWKS(path, time.Hour),
+		func() time.Time { return now },
+	}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss":             "https://issuer.example.com",
+			"aud":             []string{"other", "hashbang"},
+			"sub":             "3E99AA77-615E-4A55-930D-D4C77CFD1B72",
+			"email":           "someone@example.com",
+			"exp":             now.Add(time.Hour).Unix(),
+			"hashbang_scopes": "read:* write:blog-*",
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		for _, token := range []string{
+			signJWT(t, "RS256", "rsa", rsaKey, claims(nil)),
+			signJWT(t, "ES256", "ec", ecKey, claims(nil)),
+		} {
+			got, err := verifier.Verify(context.Background(), token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &Caller{User: &User{ID: "3e99aa77-615e-4a55-930d-d4c77cfd1b72", Email: "someone@example.com"}, Scopes: []string{"read:*", "write:blog-*"}}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+		}
+	})
+
+	t.Run("reject every token without a configured issuer and audience", func(t *testing.T) {
+		for _, config := range []JWTConfig{
+			{Audience: "hashbang", UserClaim: "sub"},
+			{Issuer: "https://issuer.example.com", UserClaim: "sub"},
+		} {
+			unconfigured := &jwtVerifier{config, NewJWKS(path, time.Hour), func() time.Time { return now }}
+			token := signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": config.Issuer, "aud": config.Audience}))
+
+			if _, err := unconfigured.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("%+v: got error %v want %v", config, err, ErrInvalidToken)
+			}
+		}
+	})
+
+	t.Run("scopes claim as array", func(t *testing.T) {
+		got, err := verifier.Verify(context.Background(), signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "hashbang", "hashbang_scopes": []string{"admin:blue"}})))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got.Scopes, []string{"admin:blue"}) {
+			t.Errorf("got scopes %v want [admin:blue]", got.Scopes)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		signed := strings.Split(signJWT(t, "RS256", "rsa", rsaKey, claims(nil)), ".")
+		unsigned := base64.RawURLEncoding.EncodeToString([]byte("{\"alg\":\"none\"}")) + "." + signed[1] + "."
+		for _, scenario := range []struct {
+			token   string
+			wantErr string
+		}{
+			{"not a token", "invalid bearer token: not a JWT"},
+			{unsigned, "invalid bearer token: unsupported algorithm none"},
+			{signed[0] + ".e30." + signed[2], "invalid bearer token: bad signature"},
+			{signJWT(t, "ES256", "ec", otherKey, claims(nil)), "invalid bearer token: bad signature"},
+			{signJWT(t, "ES256", "rsa", ecKey, claims(nil)), "invalid bearer token: bad signature"},
+			{signJWT(t, "ES256", "missing", ecKey, claims(nil)), "invalid bearer token: no signing key matches the token"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})), "invalid bearer token: issuer https://evil.example.com is not trusted"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})), "invalid bearer token: audience does not include hashbang"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})), "invalid bearer token: missing exp claim"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), "invalid bearer token: token has expired"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})), "invalid bearer token: token is not valid yet"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"sub": nil})), "invalid bearer token: missing sub claim"},
+			{signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"sub": "someone"})), "invalid bearer token: sub claim must be a UUID"},
+		} {
+			_, gotErr := verifier.Verify(context.Background(), scenario.token)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr || !errors.Is(gotErr, ErrInvalidToken) {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("accept expiry within leeway", func(t *testing.T) {
+		if _, err := verifier.Verify(context.Background(), signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}))); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"old": &oldKey.PublicKey})
+
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	keySet := &jwks{location: path, ttl: time.Hour, minRefresh: time.Minute, now: func() time.Time { return now }}
+
+	t.Run("loads and caches keys", func(t *testing.T) {
+		got, err := keySet.Key("old")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, &oldKey.PublicKey) {
+			t.Errorf("got %+v want %+v", got, &oldKey.PublicKey)
+		}
+	})
+
+	t.Run("picks up a rotated key once minRefresh has passed", func(t *testing.T) {
+		writeJWKS(t, path, map[string]crypto.PublicKey{"new": &newKey.PublicKey})
+
+		if _, gotErr := keySet.Key("new"); gotErr != ErrUnknownSigningKey {
+			t.Errorf("got error %v want %v", gotErr, ErrUnknownSigningKey)
+		}
+
+		now = now.Add(2 * time.Minute)
+		got, err := keySet.Key("new")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, &newKey.PublicKey) {
+			t.Errorf("got %+v want %+v", got, &newKey.PublicKey)
+		}
+
+		if _, gotErr := keySet.Key("old"); gotErr != ErrUnknownSigningKey {
+			t.Errorf("got error %v want %v", gotErr, ErrUnknownSigningKey)
+		}
+	})
+
+	t.Run("keeps serving cached keys when a reload fails", func(t *testing.T) {
+		if err := ioutil.WriteFile(path, []byte("not json"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(2 * time.Hour)
+		if _, err := keySet.Key("new"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("backs off after a failed reload", func(t *testing.T) {
+		fetches := 0
+		issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer issuer.Close()
+		keySet := &jwks{location: issuer.URL, ttl: time.Hour, minRefresh: time.Minute, now: func() time.Time { return now }, client: issuer.Client()}
+
+		for i := 0; i < 3; i++ {
+			if _, err := keySet.Key("new"); err == nil || err == ErrUnknownSigningKey {
+				t.Errorf("got error %v want the fetch error", err)
+			}
+		}
+		now = now.Add(2 * time.Minute)
+		keySet.Key("new")
+
+		if fetches != 2 {
+			t.Errorf("got %d fetches want 2", fetches)
+		}
+	})
+
+	t.Run("serves cached keys while a reload hangs", func(t *testing.T) {
+		release := make(chan struct{})
+		issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer issuer.Close()
+		defer close(release)
+		keySet := &jwks{location: issuer.URL, ttl: time.Hour, minRefresh: time.Minute, now: func() time.Time { return now }, client: issuer.Client()}
+		keySet.keys = map[string]crypto.PublicKey{"new": &newKey.PublicKey}
+		keySet.fetchedAt = now
+
+		go keySet.Key("unseen")
+		for {
+			keySet.mutex.Lock()
+			refreshing := keySet.refreshing != nil
+			keySet.mutex.Unlock()
+			if refreshing {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if _, err := keySet.Key("new"); err != nil {
+			t.Error(err)
+		}
+	})
+}
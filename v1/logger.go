@@ -1,19 +1,174 @@
 package v1
 
-import "log"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
-// Logger ...
+// Level orders log entries by severity.
+type Level int
+
+// Levels from least to most severe.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel ...
+func ParseLevel(name string) (Level, error) {
+	for level, each := range levelNames {
+		if each == strings.ToLower(name) {
+			return Level(level), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// Log formats.
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// Field is a key and value attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F ...
+func F(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+// Logger writes leveled entries with fields. With returns a logger that adds
+// the given fields to every entry.
 type Logger interface {
-	Error(err error)
+	Debug(message string, fields ...Field)
+	Info(message string, fields ...Field)
+	Warn(message string, fields ...Field)
+	Error(err error, fields ...Field)
+	With(fields ...Field) Logger
 }
 
-type logger struct {}
+type logger struct {
+	out    *lockedWriter
+	format string
+	level  Level
+	fields []Field
+	now    func() time.Time
+}
 
-func (l *logger) Error(err error) {
-	log.Print(err)
+type lockedWriter struct {
+	mutex sync.Mutex
+	out   io.Writer
 }
 
-// NewLogger ...
-func NewLogger() Logger {
-	return &logger{}
-}
\ No newline at end of file
+func (l *logger) Debug(message string, fields ...Field) {
+	l.write(LevelDebug, message, fields)
+}
+
+func (l *logger) Info(message string, fields ...Field) {
+	l.write(LevelInfo, message, fields)
+}
+
+func (l *logger) Warn(message string, fields ...Field) {
+	l.write(LevelWarn, message, fields)
+}
+
+func (l *logger) Error(err error, fields ...Field) {
+	l.write(LevelError, err.Error(), fields)
+}
+
+func (l *logger) With(fields ...Field) Logger {
+	with := *l
+	with.fields = append(append([]Field{}, l.fields...), fields...)
+	return &with
+}
+
+func (l *logger) write(level Level, message string, fields []Field) {
+	if level < l.level {
+		return
+	}
+
+	entry := append([]Field{
+		{"time", l.now().UTC().Format(time.RFC3339Nano)},
+		{"level", level.String()},
+		{"msg", message},
+	}, l.fields...)
+	entry = append(entry, fields...)
+
+	var line string
+	if l.format == FormatJSON {
+		line = formatJSON(entry)
+	} else {
+		line = formatLogfmt(entry)
+	}
+
+	l.out.mutex.Lock()
+	defer l.out.mutex.Unlock()
+	io.WriteString(l.out.out, line+"\n")
+}
+
+func formatJSON(fields []Field) string {
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		key, _ := json.Marshal(field.Key)
+		value, err := json.Marshal(plainValue(field.Value))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(field.Value))
+		}
+		pairs[i] = string(key) + ":" + string(value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatLogfmt(fields []Field) string {
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		value := fmt.Sprint(plainValue(field.Value))
+		if value == "" || strings.ContainsAny(value, " =\"\\\n\t") {
+			value = strconv.Quote(value)
+		}
+		pairs[i] = field.Key + "=" + value
+	}
+	return strings.Join(pairs, " ")
+}
+
+// plainValue turns values that would not read well in either format into
+// something that does.
+func plainValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case error:
+		return value.Error()
+	case time.Duration:
+		return float64(value) / float64(time.Millisecond)
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return value
+}
+
+// NewLogger writes entries at level and above to out as JSON, or as logfmt
+// for any other format.
+func NewLogger(out io.Writer, format string, level Level) Logger {
+	return &logger{
+		out:    &lockedWriter{out: out},
+		format: format,
+		level:  level,
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	now := func() time.Time { return time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC) }
+
+	for _, scenario := range []struct {
+		format string
+		want   string
+	}{
+		{FormatLogfmt, "time=2020-11-01T12:00:00Z level=info msg=\"served request\" component=api route=/namedTagLists latency_ms=1.5 bucket=blue,red\n" +
+			"time=2020-11-01T12:00:00Z level=error msg=boom component=api request_id=\"\"\n"},
+		{FormatJSON, "{\"time\":\"2020-11-01T12:00:00Z\",\"level\":\"info\",\"msg\":\"served request\",\"component\":\"api\",\"route\":\"/namedTagLists\",\"latency_ms\":1.5,\"bucket\":\"blue,red\"}\n" +
+			"{\"time\":\"2020-11-01T12:00:00Z\",\"level\":\"error\",\"msg\":\"boom\",\"component\":\"api\",\"request_id\":\"\"}\n"},
+	} {
+		t.Run("write "+scenario.format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			base := NewLogger(out, scenario.format, LevelInfo)
+			base.(*logger).now = now
+			logger := base.With(F("component", "api"))
+
+			logger.Debug("hidden")
+			logger.Info("served request", F("route", "/namedTagLists"), F("latency_ms", 1500*time.Microsecond), F("bucket", []string{"blue", "red"}))
+			logger.Error(errors.New("boom"), F("request_id", ""))
+
+			if got := out.String(); got != scenario.want {
+				t.Errorf("got %s want %s", got, scenario.want)
+			}
+		})
+	}
+
+	t.Run("parse levels", func(t *testing.T) {
+		if got, err := ParseLevel("WARN"); err != nil || got != LevelWarn {
+			t.Errorf("got %v %v want warn", got, err)
+		}
+
+		if _, err := ParseLevel("loud"); err == nil {
+			t.Error("got no error for an unknown level")
+		}
+	})
+}
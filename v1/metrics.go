@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// latencyBuckets are upper bounds in seconds, as Prometheus client
+// libraries use by default.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects what /metrics reports in the Prometheus text format.
+// Handler instruments the requests it wraps; gauges are read on every scrape.
+type Metrics interface {
+	Handler(next http.Handler) http.Handler
+	ObserveRepositoryCall(repository string, method string, latency time.Duration, err error)
+	Gauge(name string, help string, read func() (float64, error))
+	Counter(name string, help string, read func() (float64, error))
+	WriteTo(w io.Writer) (int64, error)
+}
+
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	sum    float64
+}
+
+// family holds the series of one counter, gauge or histogram by label values.
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	series     map[string]*series
+}
+
+type readFamily struct {
+	name string
+	help string
+	kind string
+	read func() (float64, error)
+}
+
+type metrics struct {
+	now func() time.Time
+
+	mutex        sync.Mutex
+	families     []*family
+	requests     *family
+	durations    *family
+	repository   *family
+	readFamilies []readFamily
+}
+
+func (m *metrics) newFamily(name string, help string, kind string, labelNames ...string) *family {
+	f := &family{name, help, kind, labelNames, map[string]*series{}}
+	m.families = append(m.families, f)
+	return f
+}
+
+// observe adds value to a counter or records it in a histogram.
+func (m *metrics) observe(f *family, value float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := strings.Join(labels, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: labels, counts: make([]uint64, len(latencyBuckets))}
+		f.series[key] = s
+	}
+	if f.kind != "histogram" {
+		s.value += value
+		return
+	}
+	for i, bound := range latencyBuckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.value++
+	s.sum += value
+}
+
+func (m *metrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			start := m.now()
+			recorder := &responseRecorder{ResponseWriter: rw}
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			status := strconv.Itoa(recorder.status)
+			route := routeOf(r.URL.Path)
+			m.observe(m.requests, 1, r.Method, route, status)
+			m.observe(m.durations, m.now().Sub(start).Seconds(), r.Method, route, status)
+		},
+	)
+}
+
+func (m *metrics) ObserveRepositoryCall(repository string, method string, latency time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	m.observe(m.repository, latency.Seconds(), repository, method, outcome)
+}
+
+func (m *metrics) Gauge(name string, help string, read func() (float64, error)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.readFamilies = append(m.readFamilies, readFamily{name, help, "gauge", read})
+}
+
+func (m *metrics) Counter(name string, help string, read func() (float64, error)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.readFamilies = append(m.readFamilies, readFamily{name, help, "counter", read})
+}
+
+// WriteTo writes every family in the order it was registered and each
+// family's series ordered by label values. Gauges that fail to read are left
+// out of the scrape.
+func (m *metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mutex.Lock()
+	readFamilies := append([]readFamily{}, m.readFamilies...)
+	var text strings.Builder
+	for _, f := range m.families {
+		writeFamily(&text, f)
+	}
+	m.mutex.Unlock()
+
+	for _, f := range readFamilies {
+		value, err := f.read()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&text, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", f.name, f.help, f.name, f.kind, f.name, formatFloat(value))
+	}
+
+	n, err := io.WriteString(w, text.String())
+	return int64(n), err
+}
+
+func writeFamily(text *strings.Builder, f *family) {
+	fmt.Fprintf(text, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+
+	keys := []string{}
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		labels := formatLabels(f.labelNames, s.labels)
+		if f.kind != "histogram" {
+			fmt.Fprintf(text, "%s%s %s\n", f.name, labels, formatFloat(s.value))
+			continue
+		}
+		bucketLabelNames := append(append([]string{}, f.labelNames...), "le")
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(text, "%s_bucket%s %d\n", f.name, formatLabels(bucketLabelNames, append(append([]string{}, s.labels...), formatFloat(bound))), s.counts[i])
+		}
+		fmt.Fprintf(text, "%s_bucket%s %s\n", f.name, formatLabels(bucketLabelNames, append(append([]string{}, s.labels...), "+Inf")), formatFloat(s.value))
+		fmt.Fprintf(text, "%s_sum%s %s\n", f.name, labels, formatFloat(s.sum))
+		fmt.Fprintf(text, "%s_count%s %s\n", f.name, labels, formatFloat(s.value))
+	}
+}
+
+var labelEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+
+func formatLabels(names []string, values []string) string {
+	if len(names) < 1 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=\"" + labelEscaper.Replace(values[i]) + "\""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// NewMetrics registers the HTTP and repository families along with a
+// build_info gauge labelled with the build's sha1 and version.
+func NewMetrics(build *Build) Metrics {
+	m := &metrics{now: time.Now}
+	m.requests = m.newFamily("hashbang_http_requests_total", "HTTP requests served.", "counter", "method", "route", "status")
+	m.durations = m.newFamily("hashbang_http_request_duration_seconds", "Time taken to serve HTTP requests.", "histogram", "method", "route", "status")
+	m.repository = m.newFamily("hashbang_repository_call_duration_seconds", "Time taken by repository calls.", "histogram", "repository", "method", "outcome")
+	info := m.newFamily("hashbang_build_info", "The running build, always 1.", "gauge", "sha1", "version")
+	info.series[""] = &series{labels: []string{build.getSha1(), build.getVersion()}, value: 1}
+	return m
+}
+
+// schemaVersionTimeout keeps a slow database from holding up a scrape.
+const schemaVersionTimeout = 2 * time.Second
+
+// InstrumentPool reports the connection pool's stats on every scrape.
+func InstrumentPool(m Metrics, pool *pgxpool.Pool) {
+	m.Gauge("hashbang_db_pool_acquired_connections", "Connections currently in use.", func() (float64, error) {
+		return float64(pool.Stat().AcquiredConns()), nil
+	})
+	m.Gauge("hashbang_db_pool_idle_connections", "Connections currently idle.", func() (float64, error) {
+		return float64(pool.Stat().IdleConns()), nil
+	})
+	m.Gauge("hashbang_db_pool_total_connections", "Connections currently open.", func() (float64, error) {
+		return float64(pool.Stat().TotalConns()), nil
+	})
+	m.Counter("hashbang_db_pool_acquires_total", "Connections acquired from the pool.", func() (float64, error) {
+		return float64(pool.Stat().AcquireCount()), nil
+	})
+	m.Counter("hashbang_db_pool_acquire_wait_seconds_total", "Time spent acquiring connections from the pool.", func() (float64, error) {
+		return pool.Stat().AcquireDuration().Seconds(), nil
+	})
+	m.Gauge("hashbang_schema_version", "Index of the last migration applied to the database.", func() (float64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), schemaVersionTimeout)
+		defer cancel()
+		version, err := SchemaVersion(ctx, pool)
+		return float64(version), err
+	})
+}
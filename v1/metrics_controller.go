@@ -0,0 +1,32 @@
+package v1
+
+import "net/http"
+
+// MetricsController ...
+type MetricsController interface {
+	GetMetrics() http.Handler
+}
+
+type metricsController struct {
+	logger  Logger
+	metrics Metrics
+}
+
+func (c *metricsController) GetMetrics() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			if _, err := c.metrics.WriteTo(rw); err != nil {
+				c.logger.Error(err)
+			}
+		},
+	)
+}
+
+// NewMetricsController ...
+func NewMetricsController(logger Logger, metrics Metrics) MetricsController {
+	return &metricsController{
+		logger,
+		metrics,
+	}
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubNamedTagListRepositoryForMetrics struct {
+	NamedTagListRepository
+}
+
+func (r *stubNamedTagListRepositoryForMetrics) DeleteByIds(ctx context.Context, ids []string) error {
+	return errors.New("no connection")
+}
+
+func TestMetrics(t *testing.T) {
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	registry := NewMetrics(NewBuild("abc123", "v1.2.0", ""))
+	registry.(*metrics).now = clock
+
+	handler := registry.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now = now.Add(30 * time.Millisecond)
+		if r.URL.Path == "/namedTagLists" {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	for _, url := range []string{"/namedTagLists", "/rotations/1/next", "/rotations/2/next", "/wp-login.php", "/.env"} {
+		request, _ := http.NewRequest(http.MethodPost, url, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	repository := &instrumentedNamedTagListRepository{&stubNamedTagListRepositoryForMetrics{}, registry, clock}
+	if err := repository.DeleteByIds(context.Background(), []string{"1"}); err == nil {
+		t.Error("got no error from the wrapped repository")
+	}
+
+	registry.Gauge("hashbang_schema_version", "Index of the last migration applied to the database.", func() (float64, error) {
+		return 19, nil
+	})
+	registry.Gauge("hashbang_unreadable", "A gauge that cannot be read.", func() (float64, error) {
+		return 0, errors.New("no connection")
+	})
+
+	out := &bytes.Buffer{}
+	if _, err := registry.WriteTo(out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"# TYPE hashbang_http_requests_total counter\n" +
+			"hashbang_http_requests_total{method=\"POST\",route=\"/namedTagLists\",status=\"201\"} 1\n" +
+			"hashbang_http_requests_total{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\"} 2\n" +
+			"hashbang_http_requests_total{method=\"POST\",route=\"other\",status=\"200\"} 2\n",
+		"hashbang_http_request_duration_seconds_bucket{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\",le=\"0.025\"} 0\n" +
+			"hashbang_http_request_duration_seconds_bucket{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\",le=\"0.05\"} 2\n",
+		"hashbang_http_request_duration_seconds_bucket{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\",le=\"+Inf\"} 2\n" +
+			"hashbang_http_request_duration_seconds_sum{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\"} 0.06\n" +
+			"hashbang_http_request_duration_seconds_count{method=\"POST\",route=\"/rotations/{id}/next\",status=\"200\"} 2\n",
+		"hashbang_repository_call_duration_seconds_count{repository=\"namedTagLists\",method=\"DeleteByIds\",outcome=\"error\"} 1\n",
+		"# TYPE hashbang_build_info gauge\nhashbang_build_info{sha1=\"abc123\",version=\"v1.2.0\"} 1\n",
+		"# TYPE hashbang_schema_version gauge\nhashbang_schema_version 19\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %s want it to contain %s", got, want)
+		}
+	}
+
+	if strings.Contains(got, "hashbang_unreadable") {
+		t.Errorf("got %s want no unreadable gauge", got)
+	}
+}
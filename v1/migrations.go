@@ -13,8 +13,40 @@ type Migration struct {
 	Sql   string
 }
 
+// migrations run in order, each at most once.
+var migrations = []Migration{
+	{Index: 5, Sql: "create table named_tag_lists (\"name\" text, \"tags\" text[])"},
+	{Index: 6, Sql: "alter table named_tag_lists add column id uuid primary key"},
+	{Index: 7, Sql: "alter table named_tag_lists add column bucket text not null default 'default'"},
+	{Index: 8, Sql: "create index on named_tag_lists (bucket)"},
+	{Index: 9, Sql: "create table rotations (\"id\" uuid primary key, \"bucket\" text not null, \"named_tag_list_ids\" uuid[] not null, \"count\" int not null, \"history\" int not null, index (\"bucket\"))"},
+	{Index: 10, Sql: "create table rotation_issues (\"id\" uuid primary key default gen_random_uuid(), \"rotation_id\" uuid not null references rotations (\"id\") on delete cascade, \"issued_at\" timestamptz not null default now(), \"tags\" text[] not null, index (\"rotation_id\", \"issued_at\"))"},
+	{Index: 11, Sql: "create table tag_metadata (\"bucket\" text not null, \"tag\" text not null, \"notes\" text not null default '', \"category\" text not null default '', \"size_tier\" text not null default '', \"colour\" text not null default '', \"favourite\" bool not null default false, primary key (\"bucket\", \"tag\"))"},
+	{Index: 12, Sql: "create table posts (\"id\" uuid primary key, \"bucket\" text not null, \"named_tag_list_ids\" uuid[] not null, \"tags\" text[] not null, \"posted_at\" timestamptz not null, \"likes\" int not null default 0, \"reach\" int not null default 0, \"saves\" int not null default 0, \"impressions\" int not null default 0, index (\"bucket\", \"posted_at\"))"},
+	{Index: 13, Sql: "create table drafts (\"id\" uuid primary key, \"bucket\" text not null, \"caption\" text not null, \"named_tag_list_ids\" uuid[] not null, \"publish_at\" timestamptz, \"status\" text not null, index (\"bucket\", \"publish_at\"))"},
+	{Index: 14, Sql: "create table api_keys (\"id\" uuid primary key, \"name\" text not null, \"prefix\" text not null, \"hash\" text not null unique, \"scopes\" text[] not null, \"created_at\" timestamptz not null, \"last_used_at\" timestamptz, \"revoked_at\" timestamptz)"},
+	{Index: 15, Sql: "create table users (\"id\" uuid primary key, \"email\" text not null unique, \"password_hash\" text not null, \"created_at\" timestamptz not null)"},
+	{Index: 16, Sql: "create table sessions (\"hash\" text primary key, \"user_id\" uuid not null references users (\"id\") on delete cascade, \"expires_at\" timestamptz not null)"},
+	{Index: 17, Sql: "create table bucket_members (\"bucket\" text not null, \"user_id\" uuid not null references users (\"id\") on delete cascade, \"role\" text not null, primary key (\"bucket\", \"user_id\"), index (\"user_id\"))"},
+	{Index: 18, Sql: "create table shares (\"id\" uuid primary key, \"bucket\" text not null, \"named_tag_list_id\" uuid, \"created_at\" timestamptz not null, \"expires_at\" timestamptz not null, \"revoked_at\" timestamptz, \"views\" int not null default 0, index (\"bucket\"))"},
+	{Index: 19, Sql: "create table audit_events (\"id\" uuid primary key default gen_random_uuid(), \"at\" timestamptz not null default now(), \"actor\" text not null, \"request_id\" text not null, \"client_ip\" text not null, \"action\" text not null, \"bucket\" text not null, \"named_tag_list_ids\" uuid[] not null, \"before\" jsonb not null, \"after\" jsonb not null, index (\"bucket\", \"at\", \"id\"))"},
+	{Index: 20, Sql: "create table idempotency_keys (\"caller\" text not null, \"bucket\" text not null, \"key\" text not null, \"request_hash\" text not null, \"body\" bytes not null, \"expires_at\" timestamptz not null, primary key (\"caller\", \"bucket\", \"key\"))"},
+}
+
+// LatestSchemaVersion returns the index of the last migration this build has.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Index
+}
+
+// SchemaVersion returns the index of the last migration the database has run.
+func SchemaVersion(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	var schemaVersion int
+	err := pool.QueryRow(ctx, "select \"value\" from metadata where \"name\" = 'schemaVersion'").Scan(&schemaVersion)
+	return schemaVersion, err
+}
+
 // Migrate ...
-func Migrate(pool *pgxpool.Pool) error {
+func Migrate(pool *pgxpool.Pool, logger Logger) error {
 	var err error
 	_, err = pool.Exec(context.Background(), "create table if not exists metadata (\"name\" text primary key, \"value\" int)")
 	if err != nil {
@@ -30,18 +62,11 @@ func Migrate(pool *pgxpool.Pool) error {
 	var schemaVersion int
 	err = row.Scan(&schemaVersion)
 
-	migrations := []Migration{
-		{Index: 5, Sql: "create table named_tag_lists (\"name\" text, \"tags\" text[])"},
-		{Index: 6, Sql: "alter table named_tag_lists add column id uuid primary key"},
-		{Index: 7, Sql: "alter table named_tag_lists add column bucket text not null default 'default'"},
-		{Index: 8, Sql: "create index on named_tag_lists (bucket)"},
-	}
-
 	for _, migration := range migrations {
 		if migration.Index <= schemaVersion {
-			fmt.Printf("Skipping migration %d: %s\n", migration.Index, migration.Sql)
+			logger.Debug("skipping migration", F("index", migration.Index), F("sql", migration.Sql))
 		} else {
-			fmt.Printf("Running migration %d: %s\n", migration.Index, migration.Sql)
+			logger.Info("running migration", F("index", migration.Index), F("sql", migration.Sql))
 			_, err = pool.Exec(context.Background(), migration.Sql)
 			if err != nil {
 				return fmt.Errorf("Failed to migrate %d: %s", migration.Index, err)
@@ -52,7 +77,7 @@ func Migrate(pool *pgxpool.Pool) error {
 	_, err = pool.Exec(
 		context.Background(),
 		"update metadata set \"value\" = $1 where \"name\" = 'schemaVersion'",
-		migrations[len(migrations)-1].Index,
+		LatestSchemaVersion(),
 	)
 	if err != nil {
 		return err
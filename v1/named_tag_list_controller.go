@@ -1,8 +1,17 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultNamedTagListPageSize = 100
+	maxNamedTagListPageSize     = 1000
 )
 
 // NamedTagListController ...
@@ -10,6 +19,8 @@ type NamedTagListController interface {
 	GetNamedTagLists() http.Handler
 	CreateNamedTagList() http.Handler
 	ReplaceNamedTagLists() http.Handler
+	UpsertNamedTagList() http.Handler
+	SyncNamedTagLists() http.Handler
 	DeleteNamedTagLists() http.Handler
 }
 
@@ -17,10 +28,13 @@ type namedTagListController struct {
 	logger                 Logger
 	namedTagListRepository NamedTagListRepository
 	namedTagListService    NamedTagListService
+	tagMetadataRepository  TagMetadataRepository
 }
 
+// GetNamedTagLists lists every list in id order, or a page of them when the
+// request gives limit or after, with a Link header while there may be more.
 func (c *namedTagListController) GetNamedTagLists() http.Handler {
-	return http.HandlerFunc(
+	return traceHandler("NamedTagListController.GetNamedTagLists", http.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request) {
 			buckets := r.URL.Query()["bucket"]
 			if len(buckets) < 1 {
@@ -28,25 +42,92 @@ func (c *namedTagListController) GetNamedTagLists() http.Handler {
 				return
 			}
 
+			values := r.URL.Query()
+			after, limit := values.Get("after"), 0
+			if after != "" {
+				var err error
+				if after, err = ParseID(after); err != nil {
+					writeBadRequest(rw, "after must be a named tag list id")
+					return
+				}
+			}
+			if value := values.Get("limit"); value != "" || after != "" {
+				limit = defaultNamedTagListPageSize
+				if value != "" {
+					var err error
+					if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxNamedTagListPageSize {
+						writeBadRequest(rw, "limit must be between 1 and "+strconv.Itoa(maxNamedTagListPageSize))
+						return
+					}
+				}
+			}
+
 			var (
 				namedTagLists []NamedTagList
 				err           error
 			)
-			if namedTagLists, err = c.namedTagListRepository.FindAll(buckets); err != nil {
-				rw.WriteHeader(http.StatusInternalServerError)
-				c.logger.Error(err)
+			if limit < 1 {
+				namedTagLists, err = c.namedTagListRepository.FindAll(r.Context(), buckets)
+			} else {
+				namedTagLists, err = c.namedTagListRepository.FindPage(r.Context(), buckets, after, limit)
 			}
-			bytes, err := json.Marshal(namedTagLists)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			if limit > 0 && len(namedTagLists) == limit {
+				values.Set("after", namedTagLists[len(namedTagLists)-1].ID)
+				rw.Header().Set("Link", "</namedTagLists?"+values.Encode()+">; rel=\"next\"")
+			}
+
+			var body interface{} = namedTagLists
+			if r.URL.Query().Get("embed") == "tagMetadata" {
+				if body, err = c.embedTagMetadata(r.Context(), buckets, namedTagLists); err != nil {
+					writeServerError(rw, r, c.logger, err)
+					return
+				}
+			}
+			bytes, err := json.Marshal(body)
 			if err != nil {
 				panic(err)
 			}
 			rw.Write(bytes)
 		},
-	)
+	))
+}
+
+// embedTagMetadata attaches the metadata of each tag in a list. When a tag has
+// metadata in more than one of the buckets, the first bucket given wins.
+func (c *namedTagListController) embedTagMetadata(ctx context.Context, buckets []string, namedTagLists []NamedTagList) ([]NamedTagListWithTagMetadata, error) {
+	byTag := map[string]TagMetadata{}
+	for i := len(buckets) - 1; i >= 0; i-- {
+		tagMetadata, err := c.tagMetadataRepository.FindAll(ctx, buckets[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range tagMetadata {
+			byTag[m.Tag] = m
+		}
+	}
+
+	embedded := []NamedTagListWithTagMetadata{}
+	for _, namedTagList := range namedTagLists {
+		withTagMetadata := NamedTagListWithTagMetadata{
+			NamedTagList: namedTagList,
+			TagMetadata:  map[string]TagMetadata{},
+		}
+		for _, tag := range namedTagList.Tags {
+			if m, ok := byTag[tag]; ok {
+				withTagMetadata.TagMetadata[tag] = m
+			}
+		}
+		embedded = append(embedded, withTagMetadata)
+	}
+	return embedded, nil
 }
 
 func (c *namedTagListController) CreateNamedTagList() http.Handler {
-	return http.HandlerFunc(
+	return traceHandler("NamedTagListController.CreateNamedTagList", http.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request) {
 			buckets := r.URL.Query()["bucket"]
 			if len(buckets) < 1 {
@@ -57,43 +138,158 @@ func (c *namedTagListController) CreateNamedTagList() http.Handler {
 				return
 			}
 
+			key := r.Header.Get("Idempotency-Key")
+			if len(key) > 255 {
+				writeBadRequest(rw, "Idempotency-Key must be no longer than 255 characters")
+				return
+			}
+
 			defer r.Body.Close()
 			var (
 				namedTagList *NamedTagList
+				replayed     bool
 				err          error
 			)
 			if json.NewDecoder(r.Body).Decode(&namedTagList) != nil {
 				rw.WriteHeader(http.StatusBadRequest)
-			} else if namedTagList, err = c.namedTagListService.Create(buckets[0], *namedTagList); err != nil {
-				rw.WriteHeader(http.StatusInternalServerError)
-				c.logger.Error(err)
+				return
+			} else if key == "" {
+				namedTagList, err = c.namedTagListService.Create(r.Context(), buckets[0], *namedTagList)
 			} else {
+				namedTagList, replayed, err = c.namedTagListService.CreateIdempotently(r.Context(), buckets[0], key, *namedTagList)
+			}
+
+			if errors.Is(err, ErrInvalidID) {
+				writeBadRequest(rw, err.Error())
+			} else if errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrNamedTagListExists) {
+				writeError(rw, http.StatusConflict, err.Error())
+			} else if errors.Is(err, ErrIdempotencyKeyReused) {
+				writeError(rw, http.StatusUnprocessableEntity, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				if replayed {
+					rw.Header().Set("Idempotent-Replayed", "true")
+				}
 				rw.WriteHeader(http.StatusCreated)
 				json.NewEncoder(rw).Encode(namedTagList)
 			}
 		},
-	)
+	))
 }
 
 func (c *namedTagListController) ReplaceNamedTagLists() http.Handler {
-	return http.HandlerFunc(
+	return traceHandler("NamedTagListController.ReplaceNamedTagLists", http.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request) {
 			defer r.Body.Close()
 			var namedTagList *NamedTagList
 			if json.NewDecoder(r.Body).Decode(&namedTagList) != nil {
 				rw.WriteHeader(http.StatusBadRequest)
-			} else if err := c.namedTagListRepository.ReplaceByIds(r.URL.Query()["id"], *namedTagList); err != nil {
-				rw.WriteHeader(500)
-				c.logger.Error(err)
+			} else if err := c.namedTagListService.Replace(r.Context(), r.URL.Query()["id"], *namedTagList); errors.Is(err, ErrQuotaExceeded) {
+				writeError(rw, http.StatusConflict, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
 			} else {
 				rw.WriteHeader(204)
 			}
 		},
-	)
+	))
+}
+
+// UpsertNamedTagList answers 201 when it created the list at
+// /namedTagLists/{id} and 200 when it replaced it.
+func (c *namedTagListController) UpsertNamedTagList() http.Handler {
+	return traceHandler("NamedTagListController.UpsertNamedTagList", http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/namedTagLists/")
+			if path == "" || strings.Contains(path, "/") {
+				http.NotFound(rw, r)
+				return
+			}
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+			id, err := ParseID(path)
+			if err != nil {
+				writeBadRequest(rw, err.Error())
+				return
+			}
+
+			defer r.Body.Close()
+			var namedTagList *NamedTagList
+			if json.NewDecoder(r.Body).Decode(&namedTagList) != nil || namedTagList == nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			} else if bodyID, _ := ParseID(namedTagList.ID); namedTagList.ID != "" && bodyID != id {
+				writeBadRequest(rw, "id in body must match the path or be left out")
+				return
+			}
+			namedTagList.ID = id
+			if namedTagList.Tags == nil {
+				namedTagList.Tags = []string{}
+			}
+
+			created, err := c.namedTagListService.Upsert(r.Context(), bucket, *namedTagList)
+			if errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrNamedTagListInAnotherBucket) {
+				writeError(rw, http.StatusConflict, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				if created {
+					rw.WriteHeader(http.StatusCreated)
+				}
+				json.NewEncoder(rw).Encode(namedTagList)
+			}
+		},
+	))
+}
+
+// SyncNamedTagLists makes the bucket at /buckets/{bucket}/namedTagLists hold
+// the lists in the body and nothing else, or with dryRun=true only reports
+// what that would change.
+func (c *namedTagListController) SyncNamedTagLists() http.Handler {
+	return traceHandler("NamedTagListController.SyncNamedTagLists", http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/buckets/")
+			bucket := strings.TrimSuffix(path, "/namedTagLists")
+			if bucket == path || bucket == "" || strings.Contains(bucket, "/") {
+				http.NotFound(rw, r)
+				return
+			}
+
+			dryRun := false
+			if value := r.URL.Query().Get("dryRun"); value != "" {
+				var err error
+				if dryRun, err = strconv.ParseBool(value); err != nil {
+					writeBadRequest(rw, "dryRun must be true or false")
+					return
+				}
+			}
+
+			defer r.Body.Close()
+			var desired []NamedTagList
+			if json.NewDecoder(r.Body).Decode(&desired) != nil || desired == nil {
+				writeBadRequest(rw, "body must be an array of named tag lists")
+				return
+			}
+
+			result, err := c.namedTagListService.Sync(r.Context(), bucket, desired, dryRun)
+			if errors.Is(err, ErrInvalidSync) {
+				writeBadRequest(rw, err.Error())
+			} else if errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrNamedTagListExists) {
+				writeError(rw, http.StatusConflict, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				json.NewEncoder(rw).Encode(result)
+			}
+		},
+	))
 }
 
 func (c *namedTagListController) DeleteNamedTagLists() http.Handler {
-	return http.HandlerFunc(
+	return traceHandler("NamedTagListController.DeleteNamedTagLists", http.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request) {
 			ids := r.URL.Query()["id"]
 			buckets := r.URL.Query()["bucket"]
@@ -104,18 +300,17 @@ func (c *namedTagListController) DeleteNamedTagLists() http.Handler {
 
 			var err error
 			if len(ids) > 0 {
-				err = c.namedTagListRepository.DeleteByIds(ids)
+				err = c.namedTagListRepository.DeleteByIds(r.Context(), ids)
 			} else {
-				err = c.namedTagListRepository.DeleteAll(buckets)
+				err = c.namedTagListRepository.DeleteAll(r.Context(), buckets)
 			}
 			if err != nil {
-				rw.WriteHeader(500)
-				c.logger.Error(err)
+				writeServerError(rw, r, c.logger, err)
 			} else {
 				rw.WriteHeader(204)
 			}
 		},
-	)
+	))
 }
 
 // NewNamedTagListController ...
@@ -123,15 +318,43 @@ func NewNamedTagListController(
 	logger Logger,
 	namedTagListRepository NamedTagListRepository,
 	namedTagListService NamedTagListService,
+	tagMetadataRepository TagMetadataRepository,
 ) NamedTagListController {
 	return &namedTagListController{
 		logger,
 		namedTagListRepository,
 		namedTagListService,
+		tagMetadataRepository,
 	}
 }
 
 func writeBadRequest(rw http.ResponseWriter, message string) {
-	rw.WriteHeader(http.StatusBadRequest)
+	writeError(rw, http.StatusBadRequest, message)
+}
+
+func writeError(rw http.ResponseWriter, statusCode int, message string) {
+	rw.WriteHeader(statusCode)
 	json.NewEncoder(rw).Encode(map[string]string{"error": message})
 }
+
+// writeServerError answers for a failure that was not the client's fault. A
+// busy or slow database is worth retrying and is reported as such. A client
+// that has already gone gets nginx's 499 so that its abandoned request is not
+// counted as a fault.
+func writeServerError(rw http.ResponseWriter, r *http.Request, logger Logger, err error) {
+	switch {
+	case errors.Is(err, ErrDatabaseBusy):
+		logger.Warn("database busy", F("error", err))
+		rw.Header().Set("Retry-After", "1")
+		writeError(rw, http.StatusServiceUnavailable, ErrDatabaseBusy.Error())
+	case errors.Is(err, ErrQueryTimeout):
+		logger.Warn("query timed out", F("error", err))
+		writeError(rw, http.StatusGatewayTimeout, ErrQueryTimeout.Error())
+	case r.Context().Err() != nil:
+		logger.Debug("request abandoned", F("error", err))
+		rw.WriteHeader(499)
+	default:
+		rw.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+	}
+}
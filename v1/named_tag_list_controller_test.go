@@ -2,6 +2,7 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,22 +23,41 @@ func stubLoggerNew() *stubLogger {
 	}
 }
 
-func (l *stubLogger) Error(err error) {
+func (l *stubLogger) Debug(message string, fields ...Field) {}
+
+func (l *stubLogger) Info(message string, fields ...Field) {}
+
+func (l *stubLogger) Warn(message string, fields ...Field) {}
+
+func (l *stubLogger) Error(err error, fields ...Field) {
 	l.errors = append(l.errors, fmt.Sprint(err))
 }
 
+func (l *stubLogger) With(fields ...Field) Logger {
+	return l
+}
+
 type stubNamedTagListRepositoryForController struct {
 	NamedTagListRepository
 
 	withBuckets      []string
 	withIds          []string
 	withNamedTagList NamedTagList
+	withAfter        string
+	withLimit        int
 	willError        string
 
 	err error
 }
 
-func (r *stubNamedTagListRepositoryForController) FindAll(buckets []string) ([]NamedTagList, error) {
+func (r *stubNamedTagListRepositoryForController) FindPage(ctx context.Context, buckets []string, after string, limit int) ([]NamedTagList, error) {
+	if !reflect.DeepEqual(buckets, r.withBuckets) || after != r.withAfter || limit != r.withLimit {
+		r.err = fmt.Errorf("Stub got buckets %v after %s limit %d want %v after %s limit %d", buckets, after, limit, r.withBuckets, r.withAfter, r.withLimit)
+	}
+	return []NamedTagList{r.withNamedTagList}, nil
+}
+
+func (r *stubNamedTagListRepositoryForController) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
 	requestMatched := reflect.DeepEqual(buckets, r.withBuckets)
 	if !requestMatched {
 		r.err = fmt.Errorf("Stub got buckets %v want %v", buckets, r.withBuckets)
@@ -48,7 +68,7 @@ func (r *stubNamedTagListRepositoryForController) FindAll(buckets []string) ([]N
 	return []NamedTagList{r.withNamedTagList}, nil
 }
 
-func (r *stubNamedTagListRepositoryForController) ReplaceByIds(ids []string, ntl NamedTagList) error {
+func (r *stubNamedTagListRepositoryForController) ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error {
 	requestMatched := reflect.DeepEqual(ids, r.withIds) && reflect.DeepEqual(ntl, r.withNamedTagList)
 	willError := (r.willError == "ReplaceByIds")
 	if !requestMatched {
@@ -60,14 +80,14 @@ func (r *stubNamedTagListRepositoryForController) ReplaceByIds(ids []string, ntl
 	return nil
 }
 
-func (r *stubNamedTagListRepositoryForController) DeleteByIds(ids []string) error {
+func (r *stubNamedTagListRepositoryForController) DeleteByIds(ctx context.Context, ids []string) error {
 	if r.willError == "DeleteByIds" {
 		return errors.New("there was an error")
 	}
 	return nil
 }
 
-func (r *stubNamedTagListRepositoryForController) DeleteAll(buckets []string) error {
+func (r *stubNamedTagListRepositoryForController) DeleteAll(ctx context.Context, buckets []string) error {
 	requestMatched := reflect.DeepEqual(buckets, r.withBuckets)
 	if !requestMatched {
 		r.err = fmt.Errorf("Stub got buckets %v want %v", buckets, r.withBuckets)
@@ -78,15 +98,29 @@ func (r *stubNamedTagListRepositoryForController) DeleteAll(buckets []string) er
 	return nil
 }
 
+type stubNamedTagListRepositoryWithError struct {
+	NamedTagListRepository
+
+	err error
+}
+
+func (r *stubNamedTagListRepositoryWithError) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	return nil, r.err
+}
+
 type stubNamedTagListService struct {
 	withBucket       string
+	withIds          []string
+	withKey          string
 	withNamedTagList NamedTagList
 	willError        string
+	willReplay       bool
+	willCreate       bool
 
 	err error
 }
 
-func (r *stubNamedTagListService) Create(bucket string, ntl NamedTagList) (*NamedTagList, error) {
+func (r *stubNamedTagListService) Create(ctx context.Context, bucket string, ntl NamedTagList) (*NamedTagList, error) {
 	requestMatched := bucket == r.withBucket && reflect.DeepEqual(ntl, r.withNamedTagList)
 	if !requestMatched {
 		r.err = fmt.Errorf("Stub got bucket %s want %v got ntl %+v want %+v", bucket, r.withBucket, ntl, r.withNamedTagList)
@@ -97,6 +131,72 @@ func (r *stubNamedTagListService) Create(bucket string, ntl NamedTagList) (*Name
 	return &r.withNamedTagList, nil
 }
 
+func (r *stubNamedTagListService) CreateIdempotently(ctx context.Context, bucket string, key string, ntl NamedTagList) (*NamedTagList, bool, error) {
+	if key != r.withKey {
+		r.err = fmt.Errorf("Stub got key %s want %s", key, r.withKey)
+	}
+	if r.willError == "CreateIdempotently" {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	created, err := r.Create(ctx, bucket, ntl)
+	return created, r.willReplay, err
+}
+
+func (r *stubNamedTagListService) Replace(ctx context.Context, ids []string, ntl NamedTagList) error {
+	requestMatched := reflect.DeepEqual(ids, r.withIds) && reflect.DeepEqual(ntl, r.withNamedTagList)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got ids %v want %v got ntl %+v want %+v", ids, r.withIds, ntl, r.withNamedTagList)
+	}
+	if requestMatched == (r.willError == "Replace") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+func (r *stubNamedTagListService) Upsert(ctx context.Context, bucket string, ntl NamedTagList) (bool, error) {
+	requestMatched := bucket == r.withBucket && reflect.DeepEqual(ntl, r.withNamedTagList)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got bucket %s want %v got ntl %+v want %+v", bucket, r.withBucket, ntl, r.withNamedTagList)
+	}
+	if requestMatched == (r.willError == "Upsert") {
+		return false, errors.New("there was an error")
+	}
+	return r.willCreate, nil
+}
+
+func (r *stubNamedTagListService) Sync(ctx context.Context, bucket string, desired []NamedTagList, dryRun bool) (*SyncResult, error) {
+	requestMatched := bucket == r.withBucket && reflect.DeepEqual(desired, []NamedTagList{r.withNamedTagList})
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got bucket %s want %v got desired %+v want %+v", bucket, r.withBucket, desired, []NamedTagList{r.withNamedTagList})
+	}
+	if requestMatched == (r.willError == "Sync") {
+		return nil, errors.New("there was an error")
+	}
+	return &SyncResult{DryRun: dryRun, Created: []NamedTagList{r.withNamedTagList}, Updated: []NamedTagList{}, Deleted: []NamedTagList{}}, nil
+}
+
+func (r *stubNamedTagListService) Move(ctx context.Context, id string, bucket string) error {
+	return errors.New("not implemented")
+}
+
+type stubNamedTagListServiceWithError struct {
+	NamedTagListService
+
+	err error
+}
+
+func (s *stubNamedTagListServiceWithError) Create(ctx context.Context, bucket string, ntl NamedTagList) (*NamedTagList, error) {
+	return nil, s.err
+}
+
+func (s *stubNamedTagListServiceWithError) Sync(ctx context.Context, bucket string, desired []NamedTagList, dryRun bool) (*SyncResult, error) {
+	return nil, s.err
+}
+
+func (s *stubNamedTagListServiceWithError) Upsert(ctx context.Context, bucket string, ntl NamedTagList) (bool, error) {
+	return false, s.err
+}
+
 func TestNamedTagListController(t *testing.T) {
 	dummyNamedTagList := NamedTagList{
 		Name: "tag list name",
@@ -115,6 +215,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			repository,
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodGet, "/?bucket=red&bucket=blue", nil)
@@ -146,6 +247,121 @@ func TestNamedTagListController(t *testing.T) {
 		}
 	})
 
+	for _, scenario := range []struct {
+		url       string
+		withAfter string
+		withLimit int
+		wantLink  string
+	}{
+		{"/namedTagLists?bucket=red&limit=1", "", 1, "</namedTagLists?after=2b7e1f3a-9c4d-4e5f-8a6b-7c8d9e0f1a2b&bucket=red&limit=1>; rel=\"next\""},
+		{"/namedTagLists?bucket=red&after=8F6C1B0E-2F3D-4A5B-9C7D-1E2F3A4B5C6D", "8f6c1b0e-2f3d-4a5b-9c7d-1e2f3a4b5c6d", 100, ""},
+	} {
+		t.Run("GET "+scenario.url, func(t *testing.T) {
+			repository := &stubNamedTagListRepositoryForController{
+				withBuckets:      []string{"red"},
+				withNamedTagList: NamedTagList{ID: "2b7e1f3a-9c4d-4e5f-8a6b-7c8d9e0f1a2b", Name: "tag list name", Tags: []string{}},
+				withAfter:        scenario.withAfter,
+				withLimit:        scenario.withLimit,
+			}
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				repository,
+				&stubNamedTagListService{},
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodGet, scenario.url, nil)
+			response := httptest.NewRecorder()
+			controller.GetNamedTagLists().ServeHTTP(response, request)
+
+			if repository.err != nil {
+				t.Error(repository.err)
+			}
+			if gotStatusCode := response.Result().StatusCode; gotStatusCode != 200 {
+				t.Errorf("got status code %d want 200", gotStatusCode)
+			}
+			if gotLink := response.Header().Get("Link"); gotLink != scenario.wantLink {
+				t.Errorf("got link %q want %q", gotLink, scenario.wantLink)
+			}
+		})
+	}
+
+	for _, scenario := range []struct {
+		url      string
+		wantBody string
+	}{
+		{"/namedTagLists?bucket=red&after=1", "{\"error\":\"after must be a named tag list id\"}\n"},
+		{"/namedTagLists?bucket=red&limit=0", "{\"error\":\"limit must be between 1 and 1000\"}\n"},
+	} {
+		t.Run("GET "+scenario.url, func(t *testing.T) {
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				&stubNamedTagListService{},
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodGet, scenario.url, nil)
+			response := httptest.NewRecorder()
+			controller.GetNamedTagLists().ServeHTTP(response, request)
+
+			if gotStatusCode := response.Result().StatusCode; gotStatusCode != 400 {
+				t.Errorf("got status code %d want 400", gotStatusCode)
+			}
+			if gotBody := response.Body.String(); gotBody != scenario.wantBody {
+				t.Errorf("got body %s want %s", gotBody, scenario.wantBody)
+			}
+		})
+	}
+
+	t.Run("GET with embedded tag metadata", func(t *testing.T) {
+		repository := &stubNamedTagListRepositoryForController{
+			withBuckets:      []string{"red", "blue"},
+			withNamedTagList: dummyNamedTagList,
+		}
+		windy := TagMetadata{Tag: "#windy", Category: "weather", Colour: "#00aaff"}
+		controller := NewNamedTagListController(
+			stubLoggerNew(),
+			repository,
+			&stubNamedTagListService{},
+			&stubTagMetadataRepository{
+				withBucket:      "blue",
+				withTagMetadata: []TagMetadata{windy, {Tag: "#unused"}},
+			},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/?bucket=red&bucket=blue&embed=tagMetadata", nil)
+		response := httptest.NewRecorder()
+		controller.GetNamedTagLists().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotNamedTagLists []NamedTagListWithTagMetadata
+		if err := json.NewDecoder(response.Body).Decode(&gotNamedTagLists); err != nil {
+			t.Fatal(err)
+		}
+
+		wantNamedTagLists := []NamedTagListWithTagMetadata{
+			{
+				NamedTagList: dummyNamedTagList,
+				TagMetadata:  map[string]TagMetadata{"#windy": windy},
+			},
+		}
+
+		if !reflect.DeepEqual(gotNamedTagLists, wantNamedTagLists) {
+			t.Errorf("got named tag lists %+v want %+v", gotNamedTagLists, wantNamedTagLists)
+		}
+	})
+
 	t.Run("GET when repository has error", func(t *testing.T) {
 		logger := stubLoggerNew()
 		repository := &stubNamedTagListRepositoryForController{
@@ -156,6 +372,7 @@ func TestNamedTagListController(t *testing.T) {
 			logger,
 			repository,
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodGet, "/?bucket=bucket", nil)
@@ -179,11 +396,68 @@ func TestNamedTagListController(t *testing.T) {
 		}
 	})
 
+	for _, scenario := range []struct {
+		name           string
+		err            error
+		cancel         bool
+		wantStatusCode int
+		wantError      string
+		wantLogged     []string
+	}{
+		{"database busy", fmt.Errorf("%w: context deadline exceeded", ErrDatabaseBusy), false, 503, ErrDatabaseBusy.Error(), []string{}},
+		{"query timed out", fmt.Errorf("%w: timeout", ErrQueryTimeout), false, 504, ErrQueryTimeout.Error(), []string{}},
+		{"client gone", context.Canceled, true, 499, "", []string{}},
+	} {
+		t.Run("GET when "+scenario.name, func(t *testing.T) {
+			logger := stubLoggerNew()
+			controller := NewNamedTagListController(
+				logger,
+				&stubNamedTagListRepositoryWithError{err: scenario.err},
+				&stubNamedTagListService{},
+				&stubTagMetadataRepository{},
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if scenario.cancel {
+				cancel()
+			}
+			defer cancel()
+			request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/?bucket=bucket", nil)
+			response := httptest.NewRecorder()
+			controller.GetNamedTagLists().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, scenario.wantStatusCode)
+			}
+
+			if scenario.wantError != "" {
+				var gotResponseBody map[string]string
+				if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+					t.Fatal(err)
+				}
+				wantResponseBody := map[string]string{"error": scenario.wantError}
+				if !reflect.DeepEqual(gotResponseBody, wantResponseBody) {
+					t.Errorf("got response body %+v want %+v", gotResponseBody, wantResponseBody)
+				}
+			}
+
+			if scenario.wantStatusCode == 503 && response.Header().Get("Retry-After") == "" {
+				t.Error("got no Retry-After header")
+			}
+
+			if !reflect.DeepEqual(logger.errors, scenario.wantLogged) {
+				t.Errorf("got logger.errors %+v want %+v", logger.errors, scenario.wantLogged)
+			}
+		})
+	}
+
 	t.Run("GET with no buckets", func(t *testing.T) {
 		controller := NewNamedTagListController(
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -217,6 +491,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			service,
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -251,11 +526,241 @@ func TestNamedTagListController(t *testing.T) {
 		}
 	})
 
+	t.Run("POST with an idempotency key", func(t *testing.T) {
+		for _, scenario := range []struct {
+			name           string
+			key            string
+			willReplay     bool
+			willError      string
+			wantStatusCode int
+			wantReplayed   string
+			wantBody       string
+		}{
+			{"first", "key", false, "", 201, "", "{\"id\":\"\",\"name\":\"tag list name\",\"tags\":[\"#windy\",\"#tdd\"]}\n"},
+			{"repeat", "key", true, "", 201, "true", "{\"id\":\"\",\"name\":\"tag list name\",\"tags\":[\"#windy\",\"#tdd\"]}\n"},
+			{"reused", "key", false, "CreateIdempotently", 422, "", "{\"error\":\"idempotency key was already used for a different request\"}\n"},
+			{"too long", strings.Repeat("k", 256), false, "", 400, "", "{\"error\":\"Idempotency-Key must be no longer than 255 characters\"}\n"},
+		} {
+			service := &stubNamedTagListService{
+				withBucket:       "bucket",
+				withKey:          "key",
+				withNamedTagList: NamedTagList{Name: "tag list name", Tags: []string{"#windy", "#tdd"}},
+				willReplay:       scenario.willReplay,
+				willError:        scenario.willError,
+			}
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				service,
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodPost, "/?bucket=bucket", strings.NewReader("{\"name\":\"tag list name\",\"tags\":[\"#windy\",\"#tdd\"]}"))
+			request.Header.Set("Idempotency-Key", scenario.key)
+			response := httptest.NewRecorder()
+			controller.CreateNamedTagList().ServeHTTP(response, request)
+
+			if service.err != nil && scenario.wantStatusCode != 400 {
+				t.Errorf("%s: %s", scenario.name, service.err)
+			}
+
+			gotStatusCode := response.Result().StatusCode
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("%s: got status code %d want %d", scenario.name, gotStatusCode, scenario.wantStatusCode)
+			}
+
+			if got := response.Header().Get("Idempotent-Replayed"); got != scenario.wantReplayed {
+				t.Errorf("%s: got Idempotent-Replayed %q want %q", scenario.name, got, scenario.wantReplayed)
+			}
+
+			if gotBody := response.Body.String(); gotBody != scenario.wantBody {
+				t.Errorf("%s: got body %s want %s", scenario.name, gotBody, scenario.wantBody)
+			}
+		}
+	})
+
+	t.Run("POST with a client-supplied id", func(t *testing.T) {
+		for _, scenario := range []struct {
+			err            error
+			wantStatusCode int
+		}{
+			{fmt.Errorf("%w: offline-1", ErrInvalidID), 400},
+			{fmt.Errorf("%w: 3e99aa77-615e-4a55-930d-d4c77cfd1b72", ErrNamedTagListExists), 409},
+		} {
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				&stubNamedTagListServiceWithError{err: scenario.err},
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodPost, "/?bucket=bucket", strings.NewReader("{\"id\":\"offline-1\",\"name\":\"offline\",\"tags\":[]}"))
+			response := httptest.NewRecorder()
+			controller.CreateNamedTagList().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, scenario.wantStatusCode)
+			}
+
+			gotBody := response.Body.String()
+			wantBody := "{\"error\":\"" + scenario.err.Error() + "\"}\n"
+			if gotBody != wantBody {
+				t.Errorf("got body %s want %s", gotBody, wantBody)
+			}
+		}
+	})
+
+	t.Run("PUT upserts by id", func(t *testing.T) {
+		id := "3e99aa77-615e-4a55-930d-d4c77cfd1b72"
+		for _, scenario := range []struct {
+			name           string
+			url            string
+			body           string
+			willCreate     bool
+			wantStatusCode int
+			wantBody       string
+		}{
+			{"created", "/namedTagLists/" + id + "?bucket=bucket", "{\"name\":\"tag list name\",\"tags\":[\"#windy\"]}", true, 201, "{\"id\":\"" + id + "\",\"name\":\"tag list name\",\"tags\":[\"#windy\"]}\n"},
+			{"replaced", "/namedTagLists/" + strings.ToUpper(id) + "?bucket=bucket", "{\"id\":\"" + id + "\",\"name\":\"tag list name\",\"tags\":[\"#windy\"]}", false, 200, "{\"id\":\"" + id + "\",\"name\":\"tag list name\",\"tags\":[\"#windy\"]}\n"},
+			{"id mismatch", "/namedTagLists/" + id + "?bucket=bucket", "{\"id\":\"0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c01\",\"name\":\"tag list name\",\"tags\":[]}", false, 400, "{\"error\":\"id in body must match the path or be left out\"}\n"},
+			{"malformed id", "/namedTagLists/1?bucket=bucket", "{\"name\":\"tag list name\",\"tags\":[]}", false, 400, "{\"error\":\"id must be a uuid: 1\"}\n"},
+			{"no bucket", "/namedTagLists/" + id, "{\"name\":\"tag list name\",\"tags\":[]}", false, 400, "{\"error\":\"bucket query parameter is required\"}\n"},
+			{"nested path", "/namedTagLists/" + id + "/tags?bucket=bucket", "{}", false, 404, "404 page not found\n"},
+		} {
+			service := &stubNamedTagListService{
+				withBucket:       "bucket",
+				withNamedTagList: NamedTagList{ID: id, Name: "tag list name", Tags: []string{"#windy"}},
+				willCreate:       scenario.willCreate,
+			}
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				service,
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodPut, scenario.url, strings.NewReader(scenario.body))
+			response := httptest.NewRecorder()
+			controller.UpsertNamedTagList().ServeHTTP(response, request)
+
+			if service.err != nil && scenario.wantStatusCode < 400 {
+				t.Errorf("%s: %s", scenario.name, service.err)
+			}
+
+			gotStatusCode := response.Result().StatusCode
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("%s: got status code %d want %d", scenario.name, gotStatusCode, scenario.wantStatusCode)
+			}
+
+			if gotBody := response.Body.String(); gotBody != scenario.wantBody {
+				t.Errorf("%s: got body %s want %s", scenario.name, gotBody, scenario.wantBody)
+			}
+		}
+	})
+
+	t.Run("PUT upsert into another bucket", func(t *testing.T) {
+		err := fmt.Errorf("%w: 3e99aa77-615e-4a55-930d-d4c77cfd1b72", ErrNamedTagListInAnotherBucket)
+		controller := NewNamedTagListController(
+			stubLoggerNew(),
+			&stubNamedTagListRepositoryForController{},
+			&stubNamedTagListServiceWithError{err: err},
+			&stubTagMetadataRepository{},
+		)
+
+		request, _ := http.NewRequest(http.MethodPut, "/namedTagLists/3e99aa77-615e-4a55-930d-d4c77cfd1b72?bucket=bucket", strings.NewReader("{\"name\":\"tag list name\"}"))
+		response := httptest.NewRecorder()
+		controller.UpsertNamedTagList().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 409
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("PUT syncs a bucket", func(t *testing.T) {
+		body := "[{\"name\":\"tag list name\",\"tags\":[\"#windy\"]}]"
+		created := "{\"created\":[{\"id\":\"\",\"name\":\"tag list name\",\"tags\":[\"#windy\"]}],\"updated\":[],\"deleted\":[],\"unchanged\":0}\n"
+		for _, scenario := range []struct {
+			name           string
+			url            string
+			body           string
+			wantStatusCode int
+			wantBody       string
+		}{
+			{"sync", "/buckets/bucket/namedTagLists", body, 200, "{\"dryRun\":false," + created[1:]},
+			{"dry run", "/buckets/bucket/namedTagLists?dryRun=true", body, 200, "{\"dryRun\":true," + created[1:]},
+			{"bad dry run", "/buckets/bucket/namedTagLists?dryRun=maybe", body, 400, "{\"error\":\"dryRun must be true or false\"}\n"},
+			{"not an array", "/buckets/bucket/namedTagLists", "{}", 400, "{\"error\":\"body must be an array of named tag lists\"}\n"},
+			{"no bucket", "/buckets//namedTagLists", body, 404, "404 page not found\n"},
+			{"bucket with a slash", "/buckets/blue/red/namedTagLists", body, 404, "404 page not found\n"},
+			{"other path", "/buckets/bucket/tags", body, 404, "404 page not found\n"},
+		} {
+			service := &stubNamedTagListService{
+				withBucket:       "bucket",
+				withNamedTagList: NamedTagList{Name: "tag list name", Tags: []string{"#windy"}},
+			}
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				service,
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodPut, scenario.url, strings.NewReader(scenario.body))
+			response := httptest.NewRecorder()
+			controller.SyncNamedTagLists().ServeHTTP(response, request)
+
+			if service.err != nil && scenario.wantStatusCode < 400 {
+				t.Errorf("%s: %s", scenario.name, service.err)
+			}
+
+			gotStatusCode := response.Result().StatusCode
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("%s: got status code %d want %d", scenario.name, gotStatusCode, scenario.wantStatusCode)
+			}
+
+			if gotBody := response.Body.String(); gotBody != scenario.wantBody {
+				t.Errorf("%s: got body %s want %s", scenario.name, gotBody, scenario.wantBody)
+			}
+		}
+	})
+
+	t.Run("PUT sync when service has error", func(t *testing.T) {
+		for _, scenario := range []struct {
+			err            error
+			wantStatusCode int
+		}{
+			{fmt.Errorf("%w: list 1: id 1 appears more than once", ErrInvalidSync), 400},
+			{ErrQuotaExceeded, 409},
+			{ErrNamedTagListExists, 409},
+			{errors.New("there was an error"), 500},
+		} {
+			controller := NewNamedTagListController(
+				stubLoggerNew(),
+				&stubNamedTagListRepositoryForController{},
+				&stubNamedTagListServiceWithError{err: scenario.err},
+				&stubTagMetadataRepository{},
+			)
+
+			request, _ := http.NewRequest(http.MethodPut, "/buckets/bucket/namedTagLists", strings.NewReader("[]"))
+			response := httptest.NewRecorder()
+			controller.SyncNamedTagLists().ServeHTTP(response, request)
+
+			if gotStatusCode := response.Result().StatusCode; gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("%v: got status code %d want %d", scenario.err, gotStatusCode, scenario.wantStatusCode)
+			}
+		}
+	})
+
 	t.Run("POST when request body malformed", func(t *testing.T) {
 		controller := NewNamedTagListController(
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("{\"garbalooy\":\"gook"))
@@ -281,6 +786,7 @@ func TestNamedTagListController(t *testing.T) {
 			logger,
 			&stubNamedTagListRepositoryForController{},
 			service,
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -314,6 +820,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -349,6 +856,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -380,14 +888,15 @@ func TestNamedTagListController(t *testing.T) {
 	})
 
 	t.Run("PUT", func(t *testing.T) {
-		stubRepository := &stubNamedTagListRepositoryForController{
+		service := &stubNamedTagListService{
 			withIds:          []string{"deadbeef-dead-beef-dead-beefdeadbeef"},
 			withNamedTagList: dummyNamedTagList,
 		}
 		controller := NewNamedTagListController(
 			stubLoggerNew(),
-			stubRepository,
-			&stubNamedTagListService{},
+			&stubNamedTagListRepositoryForController{},
+			service,
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -410,8 +919,8 @@ func TestNamedTagListController(t *testing.T) {
 			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
 		}
 
-		if stubRepository.err != nil {
-			t.Error(stubRepository.err)
+		if service.err != nil {
+			t.Error(service.err)
 		}
 	})
 
@@ -420,6 +929,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader("{\"garbalooy\":\"gook"))
@@ -434,17 +944,18 @@ func TestNamedTagListController(t *testing.T) {
 		}
 	})
 
-	t.Run("PUT when repository has error", func(t *testing.T) {
-		stubRepository := &stubNamedTagListRepositoryForController{
+	t.Run("PUT when service has error", func(t *testing.T) {
+		service := &stubNamedTagListService{
 			withIds:          []string{"deadbeef-dead-beef-dead-beefdeadbeef"},
 			withNamedTagList: dummyNamedTagList,
-			willError:        "ReplaceByIds",
+			willError:        "Replace",
 		}
 		logger := stubLoggerNew()
 		controller := NewNamedTagListController(
 			logger,
-			stubRepository,
-			&stubNamedTagListService{},
+			&stubNamedTagListRepositoryForController{},
+			service,
+			&stubTagMetadataRepository{},
 		)
 
 		requestBody, err := json.Marshal(dummyNamedTagList)
@@ -467,8 +978,8 @@ func TestNamedTagListController(t *testing.T) {
 			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
 		}
 
-		if stubRepository.err != nil {
-			t.Error(stubRepository.err)
+		if service.err != nil {
+			t.Error(service.err)
 		}
 
 		wantErrorf := []string{"there was an error"}
@@ -482,6 +993,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			&stubNamedTagListRepositoryForController{},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists?id=0b491dfc-3969-4ae3-83dd-83fae3b0f56e", nil)
@@ -504,6 +1016,7 @@ func TestNamedTagListController(t *testing.T) {
 				willError: "DeleteByIds",
 			},
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists?id=0b491dfc-3969-4ae3-83dd-83fae3b0f56e", nil)
@@ -531,6 +1044,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			repository,
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists?bucket=bucket", nil)
@@ -559,6 +1073,7 @@ func TestNamedTagListController(t *testing.T) {
 			logger,
 			repository,
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists?bucket=bucket", nil)
@@ -588,6 +1103,7 @@ func TestNamedTagListController(t *testing.T) {
 			stubLoggerNew(),
 			repository,
 			&stubNamedTagListService{},
+			&stubTagMetadataRepository{},
 		)
 
 		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists", nil)
@@ -2,33 +2,77 @@ package v1
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-// NamedTagListRepository ...
+// NamedTagListRepository writes are recorded in the audit log, attributed to
+// the caller and request found in their context.
 type NamedTagListRepository interface {
-	FindAll(buckets []string) ([]NamedTagList, error)
-	Create(bucket string, namedTagList NamedTagList) error
-	ReplaceByIds(ids []string, ntl NamedTagList) error
-	DeleteAll(buckets []string) error
-	DeleteByIds(ids []string) error
+	FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error)
+	FindPage(ctx context.Context, buckets []string, after string, limit int) ([]NamedTagList, error)
+	FindByIds(ctx context.Context, ids []string) ([]BucketedNamedTagList, error)
+	Create(ctx context.Context, bucket string, namedTagList NamedTagList) error
+	ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error
+	Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error)
+	DeleteAll(ctx context.Context, buckets []string) error
+	DeleteByIds(ctx context.Context, ids []string) error
+	MoveByIds(ctx context.Context, ids []string, bucket string) error
+}
+
+// ErrNamedTagListInAnotherBucket means an upsert named a list that some other
+// bucket holds.
+var ErrNamedTagListInAnotherBucket = errors.New("named tag list belongs to another bucket")
+
+// BucketedNamedTagList is a named tag list along with the bucket holding it.
+type BucketedNamedTagList struct {
+	NamedTagList
+	Bucket string
 }
 
 type namedTagListRepository struct {
-	pool *pgxpool.Pool
+	db database
 }
 
-func (r *namedTagListRepository) FindAll(buckets []string) ([]NamedTagList, error) {
-	var (
-		rows pgx.Rows
-		err  error
-	)
+func (r *namedTagListRepository) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListRepository.FindAll", F("buckets", buckets))
+	namedTagLists, err := r.findAll(ctx, "FindAll", buckets, "", 0)
+	span.End(err)
+	return namedTagLists, err
+}
 
-	if rows, err = r.pool.Query(context.Background(), "select \"id\", \"name\", \"tags\" from named_tag_lists where bucket = ANY($1)", buckets); err != nil {
+// FindPage returns up to limit lists in id order, starting after the list
+// with id after unless it is empty.
+func (r *namedTagListRepository) FindPage(ctx context.Context, buckets []string, after string, limit int) ([]NamedTagList, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListRepository.FindPage", F("buckets", buckets), F("after", after))
+	namedTagLists, err := r.findAll(ctx, "FindPage", buckets, after, limit)
+	span.End(err)
+	return namedTagLists, err
+}
+
+// findAll orders lists by id, so that time-ordered ids list the oldest first.
+// A zero limit means no limit.
+func (r *namedTagListRepository) findAll(ctx context.Context, operation string, buckets []string, after string, limit int) ([]NamedTagList, error) {
+	sql := "select \"id\", \"name\", \"tags\" from named_tag_lists where bucket = ANY($1)"
+	args := []interface{}{buckets}
+	if after != "" {
+		args = append(args, after)
+		sql += " and \"id\" > $2"
+	}
+	sql += " order by \"id\""
+	if limit > 0 {
+		sql += " limit " + strconv.Itoa(limit)
+	}
+
+	rows, err := r.db.query(ctx, operation, sql, args...)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	namedTagLists := []NamedTagList{}
 
@@ -40,53 +84,175 @@ func (r *namedTagListRepository) FindAll(buckets []string) ([]NamedTagList, erro
 		namedTagLists = append(namedTagLists, namedTagList)
 	}
 
-	return namedTagLists, nil
+	return namedTagLists, rows.Err()
 }
 
-func (r *namedTagListRepository) Create(bucket string, namedTagList NamedTagList) error {
-	_, err := r.pool.Exec(
-		context.Background(),
-		"insert into named_tag_lists (\"id\", \"name\", \"tags\", \"bucket\") values ($1, $2, $3, $4)",
-		namedTagList.ID,
-		namedTagList.Name,
-		namedTagList.Tags,
-		bucket,
-	)
-	return err
+// FindByIds returns the lists found in id order, leaving out ids that match
+// none.
+func (r *namedTagListRepository) FindByIds(ctx context.Context, ids []string) ([]BucketedNamedTagList, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListRepository.FindByIds", F("ids", ids))
+	namedTagLists, err := scanBucketed(r.db.query(ctx, "FindByIds", selectBucketed+"\"id\" = ANY($1) order by \"id\"", ids))
+	span.End(err)
+	return namedTagLists, err
 }
 
-func (r *namedTagListRepository) ReplaceByIds(ids []string, ntl NamedTagList) error {
-	_, err := r.pool.Exec(
-		context.Background(),
-		"update named_tag_lists set \"name\" = $1, \"tags\" = $2 where \"id\" = ANY($3)",
-		ntl.Name,
-		ntl.Tags,
-		ids,
-	)
-	return err
+func (r *namedTagListRepository) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
+	return r.audited(ctx, "Create", AuditCreate, func(ctx context.Context, tx pgx.Tx, log *auditLog) error {
+		if err := tracedExec(
+			ctx,
+			tx,
+			"insert into named_tag_lists (\"id\", \"name\", \"tags\", \"bucket\") values ($1, $2, $3, $4)",
+			namedTagList.ID,
+			namedTagList.Name,
+			namedTagList.Tags,
+			bucket,
+		); err != nil {
+			return err
+		}
+		log.after(bucket, namedTagList)
+		return nil
+	})
 }
 
-func (r *namedTagListRepository) DeleteAll(buckets []string) error {
-	_, err := r.pool.Exec(
-		context.Background(),
-		"delete from named_tag_lists where \"bucket\" = ANY($1)",
-		buckets,
-	)
-	return err
+func (r *namedTagListRepository) ReplaceByIds(ctx context.Context, ids []string, ntl NamedTagList) error {
+	return r.audited(ctx, "ReplaceByIds", AuditReplace, func(ctx context.Context, tx pgx.Tx, log *auditLog) error {
+		before, err := findForUpdate(ctx, tx, "\"id\" = ANY($1)", ids)
+		if err != nil {
+			return err
+		}
+		if err = tracedExec(
+			ctx,
+			tx,
+			"update named_tag_lists set \"name\" = $1, \"tags\" = $2 where \"id\" = ANY($3)",
+			ntl.Name,
+			ntl.Tags,
+			ids,
+		); err != nil {
+			return err
+		}
+		for _, each := range before {
+			log.before(each.Bucket, each.NamedTagList)
+			log.after(each.Bucket, NamedTagList{each.ID, ntl.Name, ntl.Tags})
+		}
+		return nil
+	})
+}
+
+// Upsert creates the list in bucket, or replaces it when bucket already holds
+// it, and reports whether it was created.
+func (r *namedTagListRepository) Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	var created bool
+	err := r.audited(ctx, "Upsert", AuditReplace, func(ctx context.Context, tx pgx.Tx, log *auditLog) error {
+		before, err := findForUpdate(ctx, tx, "\"id\" = $1", namedTagList.ID)
+		if err != nil {
+			return err
+		} else if len(before) > 0 && before[0].Bucket != bucket {
+			return fmt.Errorf("%w: %s", ErrNamedTagListInAnotherBucket, namedTagList.ID)
+		}
+		if err = tracedExec(
+			ctx,
+			tx,
+			"upsert into named_tag_lists (\"id\", \"name\", \"tags\", \"bucket\") values ($1, $2, $3, $4)",
+			namedTagList.ID,
+			namedTagList.Name,
+			namedTagList.Tags,
+			bucket,
+		); err != nil {
+			return err
+		}
+		created = len(before) < 1
+		if created {
+			log.action = AuditCreate
+		} else {
+			log.before(bucket, before[0].NamedTagList)
+		}
+		log.after(bucket, namedTagList)
+		return nil
+	})
+	return created, err
+}
+
+func (r *namedTagListRepository) DeleteAll(ctx context.Context, buckets []string) error {
+	return r.deleteWhere(ctx, "DeleteAll", "\"bucket\" = ANY($1)", buckets)
+}
+
+func (r *namedTagListRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	return r.deleteWhere(ctx, "DeleteByIds", "\"id\" = ANY($1)", ids)
 }
 
-func (r *namedTagListRepository) DeleteByIds(ids []string) error {
-	_, err := r.pool.Exec(
-		context.Background(),
-		"delete from named_tag_lists where \"id\" = ANY($1)",
-		ids,
-	)
+// MoveByIds puts the lists in bucket, leaving alone any already there.
+func (r *namedTagListRepository) MoveByIds(ctx context.Context, ids []string, bucket string) error {
+	return r.audited(ctx, "MoveByIds", AuditMove, func(ctx context.Context, tx pgx.Tx, log *auditLog) error {
+		before, err := findForUpdate(ctx, tx, "\"id\" = ANY($1) and \"bucket\" != $2", ids, bucket)
+		if err != nil {
+			return err
+		}
+		if err = tracedExec(ctx, tx, "update named_tag_lists set \"bucket\" = $1 where \"id\" = ANY($2)", bucket, ids); err != nil {
+			return err
+		}
+		for _, each := range before {
+			log.before(each.Bucket, each.NamedTagList)
+			log.after(bucket, each.NamedTagList)
+		}
+		return nil
+	})
+}
+
+func (r *namedTagListRepository) deleteWhere(ctx context.Context, method string, condition string, arg []string) error {
+	return r.audited(ctx, method, AuditDelete, func(ctx context.Context, tx pgx.Tx, log *auditLog) error {
+		before, err := findForUpdate(ctx, tx, condition, arg)
+		if err != nil {
+			return err
+		}
+		if err = tracedExec(ctx, tx, "delete from named_tag_lists where "+condition, arg); err != nil {
+			return err
+		}
+		for _, each := range before {
+			log.before(each.Bucket, each.NamedTagList)
+		}
+		return nil
+	})
+}
+
+// audited runs write in a span and a transaction that also appends its audit
+// events.
+func (r *namedTagListRepository) audited(ctx context.Context, method string, action string, write func(context.Context, pgx.Tx, *auditLog) error) error {
+	ctx, span := StartSpan(ctx, "NamedTagListRepository."+method)
+	err := r.db.write(ctx, method, func(ctx context.Context, tx pgx.Tx) error {
+		log := newAuditLog(ctx, action)
+		if err := write(ctx, tx, log); err != nil {
+			return err
+		}
+		return log.write(tx)
+	})
+	span.End(err)
 	return err
 }
 
-// NewNamedTagListRepository ...
-func NewNamedTagListRepository(pool *pgxpool.Pool) NamedTagListRepository {
-	return &namedTagListRepository{
-		pool: pool,
+const selectBucketed = "select \"id\", \"name\", \"tags\", \"bucket\" from named_tag_lists where "
+
+func findForUpdate(ctx context.Context, tx pgx.Tx, condition string, args ...interface{}) ([]BucketedNamedTagList, error) {
+	return scanBucketed(tracedQuery(ctx, tx, selectBucketed+condition+" order by \"id\" for update", args...))
+}
+
+func scanBucketed(rows pgx.Rows, err error) ([]BucketedNamedTagList, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	namedTagLists := []BucketedNamedTagList{}
+	for rows.Next() {
+		var each BucketedNamedTagList
+		if err = rows.Scan(&each.ID, &each.Name, &each.Tags, &each.Bucket); err != nil {
+			return nil, err
+		}
+		namedTagLists = append(namedTagLists, each)
 	}
+	return namedTagLists, rows.Err()
+}
+
+// NewNamedTagListRepository ...
+func NewNamedTagListRepository(pool *pgxpool.Pool, timeouts Timeouts) NamedTagListRepository {
+	return &namedTagListRepository{database{pool, timeouts}}
 }
@@ -2,11 +2,14 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/arctair/go-assertutil"
 	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -17,10 +20,10 @@ func TestNamedTagListRepository(t *testing.T) {
 
 	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
 	assertutil.NotError(t, err)
-	assertutil.NotError(t, Migrate(pool))
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
 
 	t.Run("get empty named tag lists", func(t *testing.T) {
-		got, _ := NewNamedTagListRepository(pool).FindAll([]string{"bucket"})
+		got, _ := NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"bucket"})
 		want := []NamedTagList{}
 
 		if !reflect.DeepEqual(got, want) {
@@ -29,7 +32,8 @@ func TestNamedTagListRepository(t *testing.T) {
 	})
 
 	t.Run("create named tag lists", func(t *testing.T) {
-		if err := NewNamedTagListRepository(pool).Create(
+		if err := NewNamedTagListRepository(pool, Timeouts{}).Create(
+			context.Background(),
 			"blue",
 			NamedTagList{
 				ID:   "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
@@ -43,7 +47,8 @@ func TestNamedTagListRepository(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := NewNamedTagListRepository(pool).Create(
+		if err := NewNamedTagListRepository(pool, Timeouts{}).Create(
+			context.Background(),
 			"blue",
 			NamedTagList{
 				ID:   "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4",
@@ -57,7 +62,8 @@ func TestNamedTagListRepository(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := NewNamedTagListRepository(pool).Create(
+		if err := NewNamedTagListRepository(pool, Timeouts{}).Create(
+			context.Background(),
 			"red",
 			NamedTagList{
 				ID:   "a5a5acbf-1541-4fd8-bf9a-343b75b8550f",
@@ -74,7 +80,7 @@ func TestNamedTagListRepository(t *testing.T) {
 
 	t.Run("get named tag lists does not include results from other buckets", func(t *testing.T) {
 		var got []NamedTagList
-		if got, err = NewNamedTagListRepository(pool).FindAll([]string{"blue"}); err != nil {
+		if got, err = NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"}); err != nil {
 			t.Fatal(err)
 		}
 		want := []NamedTagList{
@@ -100,7 +106,7 @@ func TestNamedTagListRepository(t *testing.T) {
 			t.Errorf("got %+v want %+v", got, want)
 		}
 
-		if got, err = NewNamedTagListRepository(pool).FindAll([]string{"red"}); err != nil {
+		if got, err = NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"red"}); err != nil {
 			t.Fatal(err)
 		}
 		want = []NamedTagList{
@@ -120,7 +126,8 @@ func TestNamedTagListRepository(t *testing.T) {
 	})
 
 	t.Run("replace named tag list by id", func(t *testing.T) {
-		if err := NewNamedTagListRepository(pool).ReplaceByIds(
+		if err := NewNamedTagListRepository(pool, Timeouts{}).ReplaceByIds(
+			context.Background(),
 			[]string{"7fe6ca35-d868-48a9-94d4-6e7f7db450ea"},
 			NamedTagList{
 				ID:   "do not update",
@@ -133,7 +140,7 @@ func TestNamedTagListRepository(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		got, _ := NewNamedTagListRepository(pool).FindAll([]string{"blue"})
+		got, _ := NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
 		want := []NamedTagList{
 			{
 				ID:   "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
@@ -158,11 +165,11 @@ func TestNamedTagListRepository(t *testing.T) {
 	})
 
 	t.Run("delete named tag list by id", func(t *testing.T) {
-		if err := NewNamedTagListRepository(pool).DeleteByIds([]string{"7fe6ca35-d868-48a9-94d4-6e7f7db450ea"}); err != nil {
+		if err := NewNamedTagListRepository(pool, Timeouts{}).DeleteByIds(context.Background(), []string{"7fe6ca35-d868-48a9-94d4-6e7f7db450ea"}); err != nil {
 			t.Fatal(err)
 		}
 
-		got, _ := NewNamedTagListRepository(pool).FindAll([]string{"blue"})
+		got, _ := NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
 		want := []NamedTagList{
 			{
 				ID:   "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4",
@@ -180,18 +187,18 @@ func TestNamedTagListRepository(t *testing.T) {
 	})
 
 	t.Run("delete all named tag lists", func(t *testing.T) {
-		if err := NewNamedTagListRepository(pool).DeleteAll([]string{"blue"}); err != nil {
+		if err := NewNamedTagListRepository(pool, Timeouts{}).DeleteAll(context.Background(), []string{"blue"}); err != nil {
 			t.Fatal(err)
 		}
 
-		got, _ := NewNamedTagListRepository(pool).FindAll([]string{"blue"})
+		got, _ := NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
 		want := []NamedTagList{}
 
 		if !reflect.DeepEqual(got, want) {
 			t.Errorf("got %+v want %+v", got, want)
 		}
 
-		if got, err = NewNamedTagListRepository(pool).FindAll([]string{"red"}); err != nil {
+		if got, err = NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"red"}); err != nil {
 			t.Fatal(err)
 		}
 		want = []NamedTagList{
@@ -209,4 +216,209 @@ func TestNamedTagListRepository(t *testing.T) {
 			t.Errorf("got %+v want %+v", got, want)
 		}
 	})
+
+	t.Run("a cancelled query releases its connection", func(t *testing.T) {
+		lock := lockNamedTagList(t, pool, "a5a5acbf-1541-4fd8-bf9a-343b75b8550f")
+		defer lock.Rollback(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		gotErr := NewNamedTagListRepository(pool, Timeouts{}).DeleteByIds(ctx, []string{"a5a5acbf-1541-4fd8-bf9a-343b75b8550f"})
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+		if got, want := pool.Stat().AcquiredConns(), int32(1); got != want {
+			t.Errorf("got %d acquired connections want %d", got, want)
+		}
+	})
+
+	t.Run("a query that runs out of time releases its connection", func(t *testing.T) {
+		lock := lockNamedTagList(t, pool, "a5a5acbf-1541-4fd8-bf9a-343b75b8550f")
+		defer lock.Rollback(context.Background())
+
+		gotErr := NewNamedTagListRepository(
+			pool,
+			Timeouts{Default: time.Hour, ByOperation: map[string]time.Duration{"DeleteByIds": 100 * time.Millisecond}},
+		).DeleteByIds(context.Background(), []string{"a5a5acbf-1541-4fd8-bf9a-343b75b8550f"})
+		if !errors.Is(gotErr, ErrQueryTimeout) {
+			t.Errorf("got %v want %v", gotErr, ErrQueryTimeout)
+		}
+		if got, want := pool.Stat().AcquiredConns(), int32(1); got != want {
+			t.Errorf("got %d acquired connections want %d", got, want)
+		}
+	})
+
+	t.Run("no connection before the deadline means the database is busy", func(t *testing.T) {
+		config, err := pgxpool.ParseConfig(testServer.PGURL().String())
+		assertutil.NotError(t, err)
+		config.MaxConns = 1
+		single, err := pgxpool.ConnectConfig(context.Background(), config)
+		assertutil.NotError(t, err)
+		defer single.Close()
+
+		held, err := single.Acquire(context.Background())
+		assertutil.NotError(t, err)
+		defer held.Release()
+
+		_, gotErr := NewNamedTagListRepository(single, Timeouts{Default: 100 * time.Millisecond}).FindAll(context.Background(), []string{"red"})
+		if !errors.Is(gotErr, ErrDatabaseBusy) {
+			t.Errorf("got %v want %v", gotErr, ErrDatabaseBusy)
+		}
+	})
+
+	t.Run("named tag lists survive interrupted deletes", func(t *testing.T) {
+		got, err := NewNamedTagListRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"red"})
+		assertutil.NotError(t, err)
+		if len(got) != 1 {
+			t.Errorf("got %+v want one named tag list", got)
+		}
+	})
+
+	t.Run("a unit of work that fails writes nothing", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		committed := false
+
+		gotErr := NewUnitOfWork(pool, Timeouts{}).Do(context.Background(), func(ctx context.Context) error {
+			if err := repository.Create(ctx, "green", NamedTagList{ID: "0d5b4a64-8c1c-4d5c-9d4a-3b9b4e0f6a11", Name: "first", Tags: []string{}}); err != nil {
+				return err
+			}
+			afterCommit(ctx, func() { committed = true })
+			if err := repository.Create(ctx, "green", NamedTagList{ID: "0d5b4a64-8c1c-4d5c-9d4a-3b9b4e0f6a11", Name: "duplicate", Tags: []string{}}); err != nil {
+				return err
+			}
+			return nil
+		})
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		got, err := repository.FindAll(context.Background(), []string{"green"})
+		assertutil.NotError(t, err)
+		if len(got) != 0 || committed {
+			t.Errorf("got %+v and committed %t want nothing", got, committed)
+		}
+	})
+
+	t.Run("a unit of work starts over after a serialization failure", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		attempts, committed := 0, 0
+
+		err := NewUnitOfWork(pool, Timeouts{}).Do(context.Background(), func(ctx context.Context) error {
+			attempts++
+			if err := repository.Create(ctx, "green", NamedTagList{ID: "5f0c7f5e-51d6-4f3e-a0a4-2b8f7d1d2c33", Name: "retried", Tags: []string{}}); err != nil {
+				return err
+			}
+			afterCommit(ctx, func() { committed++ })
+			_, err := transactionFrom(ctx).tx.Exec(ctx, "select crdb_internal.force_retry('50ms')")
+			return err
+		})
+		assertutil.NotError(t, err)
+
+		got, err := repository.FindAll(context.Background(), []string{"green"})
+		assertutil.NotError(t, err)
+		if attempts < 2 || committed != 1 || len(got) != 1 {
+			t.Errorf("got %d attempts, %d commits and %+v want a retry, one commit and one list", attempts, committed, got)
+		}
+	})
+
+	t.Run("find and move named tag lists by id", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		namedTagList := NamedTagList{ID: "8c2f4f0e-2b7a-4a3e-9a52-6c1de4f5b8a1", Name: "moving", Tags: []string{"#a"}}
+		assertutil.NotError(t, repository.Create(context.Background(), "yellow", namedTagList))
+
+		assertutil.NotError(t, repository.MoveByIds(context.Background(), []string{namedTagList.ID}, "purple"))
+
+		got, err := repository.FindByIds(context.Background(), []string{namedTagList.ID, "00000000-0000-0000-0000-000000000000"})
+		assertutil.NotError(t, err)
+		want := []BucketedNamedTagList{{namedTagList, "purple"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("a savepoint that fails undoes only its own writes", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		unitOfWork := NewUnitOfWork(pool, Timeouts{})
+		kept := NamedTagList{ID: "3a7d1c2e-9f4b-4c6a-8e1d-5b2a9c0f7e64", Name: "kept", Tags: []string{}}
+
+		var failed error
+		err := unitOfWork.Do(context.Background(), func(ctx context.Context) (err error) {
+			if err = repository.Create(ctx, "orange", kept); err != nil {
+				return err
+			}
+			failed, err = unitOfWork.Savepoint(ctx, func(ctx context.Context) error {
+				if err := repository.Create(ctx, "orange", NamedTagList{ID: "9e4b2d1a-7c3f-4e5a-b6d8-1f0a2c3e4b57", Name: "undone", Tags: []string{}}); err != nil {
+					return err
+				}
+				return repository.Create(ctx, "orange", kept)
+			})
+			return err
+		})
+		assertutil.NotError(t, err)
+		if failed == nil {
+			t.Fatal("got no error from the savepoint")
+		}
+
+		got, err := repository.FindAll(context.Background(), []string{"orange"})
+		assertutil.NotError(t, err)
+		if want := []NamedTagList{kept}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("upsert creates and then replaces", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		namedTagList := NamedTagList{ID: "6b1f0e2d-4c3a-4b5e-8f7d-9a0c1e2d3f41", Name: "synced", Tags: []string{"#a"}}
+
+		created, err := repository.Upsert(context.Background(), "teal", namedTagList)
+		assertutil.NotError(t, err)
+		namedTagList.Tags = []string{"#b"}
+		replaced, err := repository.Upsert(context.Background(), "teal", namedTagList)
+		assertutil.NotError(t, err)
+		if !created || replaced {
+			t.Errorf("got created %t then %t want true then false", created, replaced)
+		}
+
+		_, gotErr := repository.Upsert(context.Background(), "navy", namedTagList)
+		if !errors.Is(gotErr, ErrNamedTagListInAnotherBucket) {
+			t.Errorf("got error %v want %v", gotErr, ErrNamedTagListInAnotherBucket)
+		}
+
+		got, err := repository.FindAll(context.Background(), []string{"teal", "navy"})
+		assertutil.NotError(t, err)
+		if want := []NamedTagList{namedTagList}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("page through named tag lists in id order", func(t *testing.T) {
+		repository := NewNamedTagListRepository(pool, Timeouts{})
+		want := []NamedTagList{
+			{ID: "01890a5d-ac96-774b-bcce-b302099a8057", Name: "first", Tags: []string{}},
+			{ID: "01890a5d-ac97-7a1c-8d2e-3f405162738a", Name: "second", Tags: []string{}},
+			{ID: "01890a5d-ac98-7b2d-9e3f-405162738495", Name: "third", Tags: []string{}},
+		}
+		for _, i := range []int{2, 0, 1} {
+			assertutil.NotError(t, repository.Create(context.Background(), "purple", want[i]))
+		}
+
+		first, err := repository.FindPage(context.Background(), []string{"purple"}, "", 2)
+		assertutil.NotError(t, err)
+		rest, err := repository.FindPage(context.Background(), []string{"purple"}, first[len(first)-1].ID, 2)
+		assertutil.NotError(t, err)
+		if got := append(first, rest...); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
+
+// lockNamedTagList holds a row lock in a transaction of its own so that
+// writes to the row wait until the transaction ends.
+func lockNamedTagList(t *testing.T, pool *pgxpool.Pool, id string) pgx.Tx {
+	tx, err := pool.Begin(context.Background())
+	assertutil.NotError(t, err)
+	_, err = tx.Exec(context.Background(), "select \"id\" from named_tag_lists where \"id\" = $1 for update", id)
+	assertutil.NotError(t, err)
+	return tx
 }
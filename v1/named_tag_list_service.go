@@ -1,27 +1,275 @@
 package v1
 
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrQuotaExceeded ...
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrNamedTagListExists means a client-supplied id is taken.
+var ErrNamedTagListExists = errors.New("a named tag list with this id already exists")
+
+// ErrIdempotencyKeyReused means an Idempotency-Key came back with a request
+// other than the one it was first sent with.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// StorageQuota caps what a single bucket may hold. A zero field means no cap.
+type StorageQuota struct {
+	MaxNamedTagLists int
+	MaxTags          int
+}
+
 // NamedTagListService ...
 type NamedTagListService interface {
-	Create(bucket string, namedTagList NamedTagList) (*NamedTagList, error)
+	Create(ctx context.Context, bucket string, namedTagList NamedTagList) (*NamedTagList, error)
+	CreateIdempotently(ctx context.Context, bucket string, key string, namedTagList NamedTagList) (*NamedTagList, bool, error)
+	Replace(ctx context.Context, ids []string, namedTagList NamedTagList) error
+	Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error)
+	Move(ctx context.Context, id string, bucket string) error
+	Sync(ctx context.Context, bucket string, desired []NamedTagList, dryRun bool) (*SyncResult, error)
 }
 
 type namedTagListService struct {
 	namedTagListRepository NamedTagListRepository
 	uuidGenerator          UUIDGenerator
+	bucketRepository       BucketRepository
+	quota                  StorageQuota
+	unitOfWork             UnitOfWork
+	idempotencyRepository  IdempotencyRepository
+}
+
+// Create keeps an id the client chose, so long as it is a free UUID, and
+// makes one up otherwise.
+func (s *namedTagListService) Create(ctx context.Context, bucket string, namedTagList NamedTagList) (*NamedTagList, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListService.Create", F("bucket", bucket))
+	var created *NamedTagList
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) (err error) {
+		created, err = s.create(ctx, bucket, namedTagList)
+		return err
+	})
+	span.End(err)
+	return created, err
+}
+
+func (s *namedTagListService) create(ctx context.Context, bucket string, namedTagList NamedTagList) (*NamedTagList, error) {
+	if namedTagList.ID == "" {
+		namedTagList.ID = s.uuidGenerator.Generate()
+	} else if id, err := ParseID(namedTagList.ID); err != nil {
+		return nil, err
+	} else if existing, err := s.namedTagListRepository.FindByIds(ctx, []string{id}); err != nil {
+		return nil, err
+	} else if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNamedTagListExists, id)
+	} else {
+		namedTagList.ID = id
+	}
+
+	if err := s.checkQuota(ctx, bucket, nil, namedTagList); err != nil {
+		return nil, err
+	}
+	return &namedTagList, s.namedTagListRepository.Create(ctx, bucket, namedTagList)
+}
+
+// CreateIdempotently creates a list once per key, caller and bucket. Repeats
+// of the request get the list first created back, along with true. A request
+// that loses a race for the key is undone and replays the winner's response.
+func (s *namedTagListService) CreateIdempotently(ctx context.Context, bucket string, key string, namedTagList NamedTagList) (*NamedTagList, bool, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListService.CreateIdempotently", F("bucket", bucket))
+	var (
+		created  *NamedTagList
+		replayed bool
+	)
+	do := func() error {
+		return s.unitOfWork.Do(ctx, func(ctx context.Context) (err error) {
+			created, replayed, err = s.createIdempotently(ctx, bucket, key, namedTagList)
+			return err
+		})
+	}
+	err := do()
+	if errors.Is(err, ErrIdempotencyKeyInUse) {
+		err = do()
+	}
+	span.End(err)
+	return created, replayed, err
+}
+
+func (s *namedTagListService) createIdempotently(ctx context.Context, bucket string, key string, namedTagList NamedTagList) (*NamedTagList, bool, error) {
+	request, err := json.Marshal(namedTagList)
+	if err != nil {
+		return nil, false, err
+	}
+	sum := sha256.Sum256(request)
+	requestHash := hex.EncodeToString(sum[:])
+	caller := CallerFromContext(ctx).Actor()
+
+	previous, err := s.idempotencyRepository.Find(ctx, caller, bucket, key)
+	if err != nil {
+		return nil, false, err
+	} else if previous != nil && previous.RequestHash != requestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	} else if previous != nil {
+		var replayed NamedTagList
+		return &replayed, true, json.Unmarshal(previous.Body, &replayed)
+	}
+
+	created, err := s.create(ctx, bucket, namedTagList)
+	if err != nil {
+		return nil, false, err
+	}
+	body, err := json.Marshal(created)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, false, s.idempotencyRepository.Save(ctx, IdempotentResponse{caller, bucket, key, requestHash, body})
+}
+
+func (s *namedTagListService) Replace(ctx context.Context, ids []string, namedTagList NamedTagList) error {
+	ctx, span := StartSpan(ctx, "NamedTagListService.Replace", F("ids", ids))
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		return s.replace(ctx, ids, namedTagList)
+	})
+	span.End(err)
+	return err
 }
 
-func (s *namedTagListService) Create(bucket string, namedTagList NamedTagList) (*NamedTagList, error) {
-	namedTagList.ID = s.uuidGenerator.Generate()
-	return &namedTagList, s.namedTagListRepository.Create(bucket, namedTagList)
+func (s *namedTagListService) replace(ctx context.Context, ids []string, namedTagList NamedTagList) error {
+	if s.quota.MaxTags > 0 {
+		buckets, err := s.bucketRepository.FindBucketsByIds(ctx, "namedTagLists", ids)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			if err = s.checkQuota(ctx, bucket, ids, namedTagList); err != nil {
+				return err
+			}
+		}
+	}
+	return s.namedTagListRepository.ReplaceByIds(ctx, ids, namedTagList)
+}
+
+// Upsert creates the list with the id it is given, or replaces the list in
+// bucket with that id, and reports whether it created one.
+func (s *namedTagListService) Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	ctx, span := StartSpan(ctx, "NamedTagListService.Upsert", F("bucket", bucket), F("id", namedTagList.ID))
+	var created bool
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) (err error) {
+		created, err = s.upsert(ctx, bucket, namedTagList)
+		return err
+	})
+	span.End(err)
+	return created, err
+}
+
+func (s *namedTagListService) upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	id, err := ParseID(namedTagList.ID)
+	if err != nil {
+		return false, err
+	}
+	namedTagList.ID = id
+
+	existing, err := s.namedTagListRepository.FindByIds(ctx, []string{id})
+	if err != nil {
+		return false, err
+	}
+	var replacing []string
+	if len(existing) > 0 && existing[0].Bucket != bucket {
+		return false, fmt.Errorf("%w: %s", ErrNamedTagListInAnotherBucket, id)
+	} else if len(existing) > 0 {
+		replacing = []string{id}
+	}
+	if err = s.checkQuota(ctx, bucket, replacing, namedTagList); err != nil {
+		return false, err
+	}
+	return s.namedTagListRepository.Upsert(ctx, bucket, namedTagList)
+}
+
+// Move holds the list to the destination bucket's quota as though it were
+// being created there.
+func (s *namedTagListService) Move(ctx context.Context, id string, bucket string) error {
+	ctx, span := StartSpan(ctx, "NamedTagListService.Move", F("id", id), F("bucket", bucket))
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		return s.move(ctx, id, bucket)
+	})
+	span.End(err)
+	return err
+}
+
+func (s *namedTagListService) move(ctx context.Context, id string, bucket string) error {
+	found, err := s.namedTagListRepository.FindByIds(ctx, []string{id})
+	if err != nil {
+		return err
+	} else if len(found) < 1 {
+		return fmt.Errorf("%w: %s", ErrNamedTagListNotFound, id)
+	} else if found[0].Bucket == bucket {
+		return nil
+	} else if err = s.checkQuota(ctx, bucket, nil, found[0].NamedTagList); err != nil {
+		return err
+	}
+	return s.namedTagListRepository.MoveByIds(ctx, []string{id}, bucket)
+}
+
+// checkQuota works out what bucket would hold once namedTagList replaces the
+// lists with the given ids, or is added when there are none. It reads in the
+// caller's unit of work so that concurrent writes cannot both squeeze under
+// the quota.
+func (s *namedTagListService) checkQuota(ctx context.Context, bucket string, replacing []string, namedTagList NamedTagList) error {
+	if s.quota.MaxNamedTagLists < 1 && s.quota.MaxTags < 1 {
+		return nil
+	}
+
+	existing, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return err
+	}
+
+	replaced := map[string]bool{}
+	for _, id := range replacing {
+		replaced[id] = true
+	}
+	namedTagLists, tags, copies := 0, 0, 0
+	for _, each := range existing {
+		if replaced[each.ID] {
+			copies++
+		} else {
+			namedTagLists++
+			tags += len(each.Tags)
+		}
+	}
+	if len(replacing) < 1 {
+		copies = 1
+	}
+	namedTagLists += copies
+	tags += copies * len(namedTagList.Tags)
+
+	if s.quota.MaxNamedTagLists > 0 && namedTagLists > s.quota.MaxNamedTagLists {
+		return fmt.Errorf("%w: bucket %s may hold at most %d named tag lists", ErrQuotaExceeded, bucket, s.quota.MaxNamedTagLists)
+	} else if s.quota.MaxTags > 0 && tags > s.quota.MaxTags {
+		return fmt.Errorf("%w: bucket %s may hold at most %d tags across its named tag lists", ErrQuotaExceeded, bucket, s.quota.MaxTags)
+	}
+	return nil
 }
 
 // NewNamedTagListService ...
 func NewNamedTagListService(
 	namedTagListRepository NamedTagListRepository,
 	uuidGenerator UUIDGenerator,
+	bucketRepository BucketRepository,
+	quota StorageQuota,
+	unitOfWork UnitOfWork,
+	idempotencyRepository IdempotencyRepository,
 ) NamedTagListService {
 	return &namedTagListService{
 		namedTagListRepository,
 		uuidGenerator,
+		bucketRepository,
+		quota,
+		unitOfWork,
+		idempotencyRepository,
 	}
 }
@@ -1,10 +1,17 @@
 package v1
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/arctair/go-assertutil"
 )
 
 type stubNamedTagListRepositoryForService struct {
@@ -21,7 +28,7 @@ func (r *stubNamedTagListRepositoryForService) CreateOld(namedTagList NamedTagLi
 	return errors.New("do not call")
 }
 
-func (r *stubNamedTagListRepositoryForService) Create(bucket string, namedTagList NamedTagList) error {
+func (r *stubNamedTagListRepositoryForService) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
 	requestMatched := bucket == r.withBucket && reflect.DeepEqual(namedTagList, r.withNamedTagList)
 	if !requestMatched {
 		r.err = fmt.Errorf("Stub got bucket %s want %s got named tag list %+v want %+v", bucket, r.withBucket, namedTagList, r.withNamedTagList)
@@ -32,6 +39,43 @@ func (r *stubNamedTagListRepositoryForService) Create(bucket string, namedTagLis
 	return nil
 }
 
+type stubNamedTagListRepositoryForQuota struct {
+	NamedTagListRepository
+
+	withNamedTagLists []NamedTagList
+
+	created           bool
+	replaced          bool
+	outsideUnitOfWork bool
+}
+
+func (r *stubNamedTagListRepositoryForQuota) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	return r.withNamedTagLists, nil
+}
+
+func (r *stubNamedTagListRepositoryForQuota) Create(ctx context.Context, bucket string, namedTagList NamedTagList) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	r.created = true
+	return nil
+}
+
+func (r *stubNamedTagListRepositoryForQuota) ReplaceByIds(ctx context.Context, ids []string, namedTagList NamedTagList) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	r.replaced = true
+	return nil
+}
+
+type stubNamedTagListRepositoryForUpsert struct {
+	stubNamedTagListRepositoryForBatch
+}
+
+func (r *stubNamedTagListRepositoryForUpsert) Upsert(ctx context.Context, bucket string, namedTagList NamedTagList) (bool, error) {
+	_, replaced := r.withNamedTagLists[namedTagList.ID]
+	r.withNamedTagLists[namedTagList.ID] = BucketedNamedTagList{namedTagList, bucket}
+	return !replaced, nil
+}
+
 type stubUUIDGenerator struct {
 	response string
 }
@@ -40,6 +84,57 @@ func (r *stubUUIDGenerator) Generate() string {
 	return r.response
 }
 
+// stubUnitOfWork runs work once in a context that records it was inside a
+// unit of work.
+type stubUnitOfWork struct {
+	units int
+}
+
+type stubUnitOfWorkKey struct{}
+
+func (u *stubUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.units++
+	return fn(context.WithValue(ctx, stubUnitOfWorkKey{}, u.units))
+}
+
+func (u *stubUnitOfWork) Savepoint(ctx context.Context, fn func(ctx context.Context) error) (error, error) {
+	return fn(ctx), nil
+}
+
+func inStubUnitOfWork(ctx context.Context) bool {
+	return ctx.Value(stubUnitOfWorkKey{}) != nil
+}
+
+// stubIdempotencyRepository keeps responses in memory and never expires them.
+type stubIdempotencyRepository struct {
+	withResponses map[string]IdempotentResponse
+	racedBy       *IdempotentResponse
+
+	outsideUnitOfWork bool
+}
+
+func (r *stubIdempotencyRepository) Find(ctx context.Context, caller string, bucket string, key string) (*IdempotentResponse, error) {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	if response, ok := r.withResponses[caller+"/"+bucket+"/"+key]; ok {
+		return &response, nil
+	}
+	return nil, nil
+}
+
+func (r *stubIdempotencyRepository) Save(ctx context.Context, response IdempotentResponse) error {
+	r.outsideUnitOfWork = r.outsideUnitOfWork || !inStubUnitOfWork(ctx)
+	if r.withResponses == nil {
+		r.withResponses = map[string]IdempotentResponse{}
+	}
+	if r.racedBy != nil {
+		response, r.racedBy = *r.racedBy, nil
+		r.withResponses[response.Caller+"/"+response.Bucket+"/"+response.Key] = response
+		return ErrIdempotencyKeyInUse
+	}
+	r.withResponses[response.Caller+"/"+response.Bucket+"/"+response.Key] = response
+	return nil
+}
+
 func TestNamedTagListService(t *testing.T) {
 	request := NamedTagList{
 		Name: "tag list name",
@@ -66,13 +161,17 @@ func TestNamedTagListService(t *testing.T) {
 			&stubUUIDGenerator{
 				response: "3e99aa77-615e-4a55-930d-d4c77cfd1b72",
 			},
+			&stubBucketRepositoryForAuthenticator{},
+			StorageQuota{},
+			&stubUnitOfWork{},
+			&stubIdempotencyRepository{},
 		)
 
 		var (
 			gotResponse *NamedTagList
 			err         error
 		)
-		if gotResponse, err = service.Create("bucket", request); err != nil {
+		if gotResponse, err = service.Create(context.Background(), "bucket", request); err != nil {
 			t.Fatal(err)
 		}
 
@@ -94,9 +193,13 @@ func TestNamedTagListService(t *testing.T) {
 		service := NewNamedTagListService(
 			repository,
 			&stubUUIDGenerator{},
+			&stubBucketRepositoryForAuthenticator{},
+			StorageQuota{},
+			&stubUnitOfWork{},
+			&stubIdempotencyRepository{},
 		)
 
-		_, gotErr := service.Create("bucket", request)
+		_, gotErr := service.Create(context.Background(), "bucket", request)
 
 		if repository.err != nil {
 			t.Error(repository.err)
@@ -112,4 +215,260 @@ func TestNamedTagListService(t *testing.T) {
 			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
 		}
 	})
+	t.Run("quota", func(t *testing.T) {
+		existing := []NamedTagList{
+			{ID: "1", Tags: []string{"#a", "#b", "#c"}},
+			{ID: "2", Tags: []string{"#d"}},
+		}
+		bucketRepository := &stubBucketRepositoryForAuthenticator{
+			withBuckets: map[string]string{"namedTagLists/1": "bucket"},
+		}
+
+		for _, scenario := range []struct {
+			name    string
+			quota   StorageQuota
+			replace bool
+			tags    int
+			wantErr string
+		}{
+			{"create within quota", StorageQuota{3, 6}, false, 2, ""},
+			{"create past list quota", StorageQuota{2, 0}, false, 0, "quota exceeded: bucket bucket may hold at most 2 named tag lists"},
+			{"create past tag quota", StorageQuota{0, 6}, false, 3, "quota exceeded: bucket bucket may hold at most 6 tags across its named tag lists"},
+			{"replace within quota", StorageQuota{2, 6}, true, 5, ""},
+			{"replace past tag quota", StorageQuota{2, 6}, true, 6, "quota exceeded: bucket bucket may hold at most 6 tags across its named tag lists"},
+		} {
+			repository := &stubNamedTagListRepositoryForQuota{withNamedTagLists: existing}
+			service := NewNamedTagListService(repository, &stubUUIDGenerator{}, bucketRepository, scenario.quota, &stubUnitOfWork{}, &stubIdempotencyRepository{})
+			namedTagList := NamedTagList{Tags: make([]string, scenario.tags)}
+
+			var gotErr error
+			if scenario.replace {
+				gotErr = service.Replace(context.Background(), []string{"1"}, namedTagList)
+			} else {
+				_, gotErr = service.Create(context.Background(), "bucket", namedTagList)
+			}
+
+			if scenario.wantErr == "" && gotErr != nil {
+				t.Errorf("%s: got error %v want none", scenario.name, gotErr)
+			} else if scenario.wantErr != "" && (gotErr == nil || gotErr.Error() != scenario.wantErr) {
+				t.Errorf("%s: got error %v want %s", scenario.name, gotErr, scenario.wantErr)
+			}
+
+			if wrote := repository.created || repository.replaced; wrote != (scenario.wantErr == "") {
+				t.Errorf("%s: got written %t want %t", scenario.name, wrote, scenario.wantErr == "")
+			}
+
+			if repository.outsideUnitOfWork {
+				t.Errorf("%s: got quota check and write outside one unit of work", scenario.name)
+			}
+		}
+	})
+	t.Run("create idempotently", func(t *testing.T) {
+		repository := &stubNamedTagListRepositoryForQuota{}
+		idempotencyRepository := &stubIdempotencyRepository{}
+		service := NewNamedTagListService(
+			repository,
+			&stubUUIDGenerator{response: "3e99aa77-615e-4a55-930d-d4c77cfd1b72"},
+			&stubBucketRepositoryForAuthenticator{},
+			StorageQuota{},
+			&stubUnitOfWork{},
+			idempotencyRepository,
+		)
+		ctx := WithCaller(context.Background(), &Caller{APIKey: &APIKey{ID: "1"}})
+		want := &NamedTagList{ID: "3e99aa77-615e-4a55-930d-d4c77cfd1b72", Name: request.Name, Tags: request.Tags}
+
+		got, replayed, err := service.CreateIdempotently(ctx, "bucket", "key", request)
+		assertutil.NotError(t, err)
+		if !reflect.DeepEqual(got, want) || replayed || !repository.created {
+			t.Errorf("got %+v replayed %t created %t want %+v created", got, replayed, repository.created, want)
+		}
+
+		repository.created = false
+		got, replayed, err = service.CreateIdempotently(ctx, "bucket", "key", request)
+		assertutil.NotError(t, err)
+		if !reflect.DeepEqual(got, want) || !replayed || repository.created {
+			t.Errorf("got %+v replayed %t created %t want %+v replayed", got, replayed, repository.created, want)
+		}
+
+		_, _, gotErr := service.CreateIdempotently(ctx, "bucket", "key", NamedTagList{Name: "another name", Tags: request.Tags})
+		if !errors.Is(gotErr, ErrIdempotencyKeyReused) || repository.created {
+			t.Errorf("got error %v created %t want %v", gotErr, repository.created, ErrIdempotencyKeyReused)
+		}
+
+		_, replayed, err = service.CreateIdempotently(WithCaller(context.Background(), &Caller{APIKey: &APIKey{ID: "2"}}), "bucket", "key", request)
+		assertutil.NotError(t, err)
+		if replayed || !repository.created {
+			t.Errorf("got replayed %t created %t for another caller want created", replayed, repository.created)
+		}
+
+		if repository.outsideUnitOfWork || idempotencyRepository.outsideUnitOfWork {
+			t.Error("got key lookup and write outside one unit of work")
+		}
+	})
+	t.Run("replay the response of a request that won the key", func(t *testing.T) {
+		body, _ := json.Marshal(request)
+		sum := sha256.Sum256(body)
+		idempotencyRepository := &stubIdempotencyRepository{racedBy: &IdempotentResponse{
+			Caller:      "apiKey:1",
+			Bucket:      "bucket",
+			Key:         "key",
+			RequestHash: hex.EncodeToString(sum[:]),
+			Body:        []byte("{\"id\":\"5b0f5e4e-1d3c-4b8e-9a4f-7c2d6e8f0a13\",\"name\":\"tag list name\",\"tags\":[\"#windy\",\"#tdd\"]}"),
+		}}
+		service := NewNamedTagListService(
+			&stubNamedTagListRepositoryForQuota{},
+			&stubUUIDGenerator{response: "3e99aa77-615e-4a55-930d-d4c77cfd1b72"},
+			&stubBucketRepositoryForAuthenticator{},
+			StorageQuota{},
+			&stubUnitOfWork{},
+			idempotencyRepository,
+		)
+
+		got, replayed, err := service.CreateIdempotently(WithCaller(context.Background(), &Caller{APIKey: &APIKey{ID: "1"}}), "bucket", "key", request)
+		assertutil.NotError(t, err)
+		if want := (&NamedTagList{ID: "5b0f5e4e-1d3c-4b8e-9a4f-7c2d6e8f0a13", Name: request.Name, Tags: request.Tags}); !reflect.DeepEqual(got, want) || !replayed {
+			t.Errorf("got %+v replayed %t want %+v replayed", got, replayed, want)
+		}
+	})
+	t.Run("create with a client-supplied id", func(t *testing.T) {
+		repository := &stubNamedTagListRepositoryForBatch{
+			withNamedTagLists: map[string]BucketedNamedTagList{
+				"3e99aa77-615e-4a55-930d-d4c77cfd1b72": {NamedTagList{ID: "3e99aa77-615e-4a55-930d-d4c77cfd1b72"}, "bucket"},
+			},
+		}
+		service := NewNamedTagListService(
+			repository,
+			&stubUUIDGenerator{response: "generated"},
+			&stubBucketRepositoryForAuthenticator{},
+			StorageQuota{},
+			&stubUnitOfWork{},
+			&stubIdempotencyRepository{},
+		)
+
+		for _, scenario := range []struct {
+			id      string
+			wantID  string
+			wantErr error
+		}{
+			{"", "generated", nil},
+			{"0A6E1C3E-3F4B-4D2A-9C1E-7B5D2F8A6C01", "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c01", nil},
+			{"offline-1", "", ErrInvalidID},
+			{"3e99aa77-615e-4a55-930d-d4c77cfd1b72", "", ErrNamedTagListExists},
+		} {
+			got, err := service.Create(context.Background(), "bucket", NamedTagList{ID: scenario.id, Name: "offline", Tags: []string{}})
+			if !errors.Is(err, scenario.wantErr) {
+				t.Errorf("%q: got error %v want %v", scenario.id, err, scenario.wantErr)
+			} else if err == nil && got.ID != scenario.wantID {
+				t.Errorf("%q: got id %s want %s", scenario.id, got.ID, scenario.wantID)
+			}
+		}
+
+		if len(repository.withNamedTagLists) != 3 {
+			t.Errorf("got %d named tag lists want 3", len(repository.withNamedTagLists))
+		}
+	})
+	t.Run("upsert", func(t *testing.T) {
+		id := "3e99aa77-615e-4a55-930d-d4c77cfd1b72"
+		for _, scenario := range []struct {
+			name        string
+			bucket      string
+			quota       StorageQuota
+			wantCreated bool
+			wantErr     error
+		}{
+			{"replace in its bucket", "blue", StorageQuota{MaxNamedTagLists: 1}, false, nil},
+			{"create elsewhere", "green", StorageQuota{}, true, nil},
+			{"create past quota", "green", StorageQuota{MaxNamedTagLists: 1}, false, ErrQuotaExceeded},
+			{"replace in another bucket", "red", StorageQuota{}, false, ErrNamedTagListInAnotherBucket},
+		} {
+			existing := map[string]BucketedNamedTagList{
+				"0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c01": {NamedTagList{ID: "0a6e1c3e-3f4b-4d2a-9c1e-7b5d2f8a6c01"}, "green"},
+			}
+			if scenario.bucket != "green" {
+				existing[id] = BucketedNamedTagList{NamedTagList{ID: id, Name: "before"}, "blue"}
+			}
+			repository := &stubNamedTagListRepositoryForUpsert{stubNamedTagListRepositoryForBatch{withNamedTagLists: existing}}
+			service := NewNamedTagListService(repository, &stubUUIDGenerator{}, &stubBucketRepositoryForAuthenticator{}, scenario.quota, &stubUnitOfWork{}, &stubIdempotencyRepository{})
+
+			gotCreated, gotErr := service.Upsert(context.Background(), scenario.bucket, NamedTagList{ID: strings.ToUpper(id), Name: "after", Tags: []string{}})
+
+			if !errors.Is(gotErr, scenario.wantErr) || gotCreated != scenario.wantCreated {
+				t.Errorf("%s: got created %t and error %v want %t and %v", scenario.name, gotCreated, gotErr, scenario.wantCreated, scenario.wantErr)
+			}
+			if got := repository.withNamedTagLists[id]; gotErr == nil && (got.Bucket != scenario.bucket || got.Name != "after") {
+				t.Errorf("%s: got %+v", scenario.name, got)
+			}
+		}
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		kept := "3e99aa77-615e-4a55-930d-d4c77cfd1b72"
+		renamed := "5a0bd8cb-3dd2-4e2b-b4c1-5e2a3a9e2f10"
+		byName := "7c1e3b7f-4f44-4b8c-a6d2-06b9b8a3e8d1"
+		stale := "9d6b8a51-2f0e-4e6b-8d1c-6a2f8f4b5c33"
+		other := "b2f1c0a9-8e7d-4c6b-9a5f-4e3d2c1b0a99"
+		repository := &stubNamedTagListRepositoryForBatch{withNamedTagLists: map[string]BucketedNamedTagList{
+			kept:    {NamedTagList{ID: kept, Name: "kept", Tags: []string{"#a"}}, "blue"},
+			renamed: {NamedTagList{ID: renamed, Name: "before", Tags: []string{}}, "blue"},
+			byName:  {NamedTagList{ID: byName, Name: "by name", Tags: []string{}}, "blue"},
+			stale:   {NamedTagList{ID: stale, Name: "stale", Tags: []string{}}, "blue"},
+			other:   {NamedTagList{ID: other, Name: "stale", Tags: []string{}}, "red"},
+		}}
+		unitOfWork := &stubUnitOfWork{}
+		service := NewNamedTagListService(repository, &stubUUIDGenerator{"new"}, &stubBucketRepositoryForAuthenticator{}, StorageQuota{}, unitOfWork, &stubIdempotencyRepository{})
+
+		got, err := service.Sync(context.Background(), "blue", []NamedTagList{
+			{ID: strings.ToUpper(kept), Name: "kept", Tags: []string{"#a"}},
+			{ID: renamed, Name: "after"},
+			{Name: "by name", Tags: []string{"#b"}},
+			{Name: "created", Tags: []string{"#c"}},
+		}, false)
+		assertutil.NotError(t, err)
+
+		want := &SyncResult{
+			Created:   []NamedTagList{{ID: "new", Name: "created", Tags: []string{"#c"}}},
+			Updated:   []NamedTagList{{ID: renamed, Name: "after", Tags: []string{}}, {ID: byName, Name: "by name", Tags: []string{"#b"}}},
+			Deleted:   []NamedTagList{{ID: stale, Name: "stale", Tags: []string{}}},
+			Unchanged: 1,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+		if _, ok := repository.withNamedTagLists[stale]; ok {
+			t.Errorf("got stale list still stored")
+		}
+		if _, ok := repository.withNamedTagLists[other]; !ok {
+			t.Errorf("got list in another bucket deleted")
+		}
+		if repository.outsideUnitOfWork || unitOfWork.units != 1 {
+			t.Errorf("got %d units of work and work outside one %t", unitOfWork.units, repository.outsideUnitOfWork)
+		}
+	})
+
+	t.Run("sync dry run", func(t *testing.T) {
+		repository := &stubNamedTagListRepositoryForBatch{withNamedTagLists: map[string]BucketedNamedTagList{}}
+		service := NewNamedTagListService(repository, &stubUUIDGenerator{"new"}, &stubBucketRepositoryForAuthenticator{}, StorageQuota{}, &stubUnitOfWork{}, &stubIdempotencyRepository{})
+
+		got, err := service.Sync(context.Background(), "blue", []NamedTagList{{Name: "created"}}, true)
+		assertutil.NotError(t, err)
+		if !got.DryRun || len(got.Created) != 1 {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("sync refuses an id twice", func(t *testing.T) {
+		id := "3e99aa77-615e-4a55-930d-d4c77cfd1b72"
+		repository := &stubNamedTagListRepositoryForBatch{withNamedTagLists: map[string]BucketedNamedTagList{}}
+		service := NewNamedTagListService(repository, &stubUUIDGenerator{}, &stubBucketRepositoryForAuthenticator{}, StorageQuota{}, &stubUnitOfWork{}, &stubIdempotencyRepository{})
+
+		for _, desired := range [][]NamedTagList{
+			{{ID: id}, {ID: strings.ToUpper(id)}},
+			{{ID: "1"}},
+		} {
+			_, err := service.Sync(context.Background(), "blue", desired, false)
+			if !errors.Is(err, ErrInvalidSync) {
+				t.Errorf("%+v: got error %v want %v", desired, err, ErrInvalidSync)
+			}
+		}
+	})
 }
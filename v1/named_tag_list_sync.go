@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSync ...
+var ErrInvalidSync = errors.New("invalid sync")
+
+// errDryRun rolls back a sync that was only asked what it would change.
+var errDryRun = errors.New("dry run")
+
+// SyncResult sums up what a sync changed, or would have changed in a dry run.
+// Lists created in a dry run carry ids that were never stored.
+type SyncResult struct {
+	DryRun    bool           `json:"dryRun"`
+	Created   []NamedTagList `json:"created"`
+	Updated   []NamedTagList `json:"updated"`
+	Deleted   []NamedTagList `json:"deleted"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// Sync makes bucket hold exactly the desired lists. A desired list with an id
+// matches the stored list with that id; one without matches a stored list of
+// the same name. Matches that differ are updated, desired lists that match
+// nothing are created and stored lists that nothing matches are deleted, all
+// in one unit of work. A dry run does the same work and then undoes it.
+func (s *namedTagListService) Sync(ctx context.Context, bucket string, desired []NamedTagList, dryRun bool) (*SyncResult, error) {
+	desired, err := validateSync(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := StartSpan(ctx, "NamedTagListService.Sync", F("bucket", bucket), F("dryRun", dryRun))
+	var result *SyncResult
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) (err error) {
+		if result, err = s.sync(ctx, bucket, desired); err == nil && dryRun {
+			err = errDryRun
+		}
+		return err
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+		result.DryRun = true
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *namedTagListService) sync(ctx context.Context, bucket string, desired []NamedTagList) (*SyncResult, error) {
+	existing, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]NamedTagList{}
+	for _, each := range existing {
+		byID[each.ID] = each
+	}
+	claimed := map[string]bool{}
+	for _, each := range desired {
+		if _, ok := byID[each.ID]; ok {
+			claimed[each.ID] = true
+		}
+	}
+	byName := map[string][]NamedTagList{}
+	for _, each := range existing {
+		if !claimed[each.ID] {
+			byName[each.Name] = append(byName[each.Name], each)
+		}
+	}
+
+	result := &SyncResult{Created: []NamedTagList{}, Updated: []NamedTagList{}, Deleted: []NamedTagList{}}
+	var creates, updates []NamedTagList
+	for _, want := range desired {
+		current, matched := byID[want.ID]
+		if !matched && want.ID == "" && len(byName[want.Name]) > 0 {
+			current, matched = byName[want.Name][0], true
+			byName[want.Name] = byName[want.Name][1:]
+			claimed[current.ID] = true
+		}
+
+		if !matched {
+			creates = append(creates, want)
+			continue
+		}
+		want.ID = current.ID
+		if want.Name == current.Name && sameTags(want.Tags, current.Tags) {
+			result.Unchanged++
+		} else {
+			updates = append(updates, want)
+		}
+	}
+
+	var deletes []string
+	for _, each := range existing {
+		if !claimed[each.ID] {
+			deletes = append(deletes, each.ID)
+			result.Deleted = append(result.Deleted, each)
+		}
+	}
+	if len(deletes) > 0 {
+		if err = s.namedTagListRepository.DeleteByIds(ctx, deletes); err != nil {
+			return nil, err
+		}
+	}
+	for _, each := range updates {
+		if err = s.replace(ctx, []string{each.ID}, each); err != nil {
+			return nil, err
+		}
+		result.Updated = append(result.Updated, each)
+	}
+	for _, each := range creates {
+		created, err := s.create(ctx, bucket, each)
+		if err != nil {
+			return nil, err
+		}
+		result.Created = append(result.Created, *created)
+	}
+	return result, nil
+}
+
+// validateSync puts ids in canonical form and refuses a desired state that
+// names the same id twice.
+func validateSync(desired []NamedTagList) ([]NamedTagList, error) {
+	valid := make([]NamedTagList, len(desired))
+	seen := map[string]bool{}
+	for i, each := range desired {
+		if each.ID != "" {
+			id, err := ParseID(each.ID)
+			if err != nil {
+				return nil, fmt.Errorf("%w: list %d: %v", ErrInvalidSync, i, err)
+			} else if seen[id] {
+				return nil, fmt.Errorf("%w: list %d: id %s appears more than once", ErrInvalidSync, i, id)
+			}
+			seen[id] = true
+			each.ID = id
+		}
+		each.Tags = tagsOrEmpty(each.Tags)
+		valid[i] = each
+	}
+	return valid, nil
+}
+
+func sameTags(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Post records how a post performed with the tags it was published with.
+type Post struct {
+	ID              string    `json:"id"`
+	NamedTagListIDs []string  `json:"namedTagListIds"`
+	Tags            []string  `json:"tags"`
+	PostedAt        time.Time `json:"postedAt"`
+	Likes           int       `json:"likes"`
+	Reach           int       `json:"reach"`
+	Saves           int       `json:"saves"`
+	Impressions     int       `json:"impressions"`
+}
+
+// PostMetrics ...
+var PostMetrics = map[string]func(Post) float64{
+	"likes":       func(p Post) float64 { return float64(p.Likes) },
+	"reach":       func(p Post) float64 { return float64(p.Reach) },
+	"saves":       func(p Post) float64 { return float64(p.Saves) },
+	"impressions": func(p Post) float64 { return float64(p.Impressions) },
+}
+
+// Performance summarises a metric over a set of posts. The confidence
+// interval is the 95% interval of the mean and is omitted for fewer than two
+// posts.
+type Performance struct {
+	Posts              int       `json:"posts"`
+	Mean               float64   `json:"mean"`
+	Median             float64   `json:"median"`
+	ConfidenceInterval []float64 `json:"confidenceInterval"`
+}
+
+// NamedTagListPerformance ...
+type NamedTagListPerformance struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Performance
+}
+
+// TagPerformance ...
+type TagPerformance struct {
+	Tag string `json:"tag"`
+	Performance
+}
+
+// NewPerformance ...
+func NewPerformance(values []float64) Performance {
+	n := len(values)
+	if n == 0 {
+		return Performance{}
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+	performance := Performance{Posts: n, Mean: sum / float64(n)}
+	if n%2 == 1 {
+		performance.Median = sorted[n/2]
+	} else {
+		performance.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	if n > 1 {
+		squares := 0.0
+		for _, value := range sorted {
+			squares += (value - performance.Mean) * (value - performance.Mean)
+		}
+		margin := studentT975(n-1) * math.Sqrt(squares/float64(n-1)) / math.Sqrt(float64(n))
+		performance.ConfidenceInterval = []float64{performance.Mean - margin, performance.Mean + margin}
+	}
+	return performance
+}
+
+var studentT975Table = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func studentT975(degreesOfFreedom int) float64 {
+	if degreesOfFreedom <= len(studentT975Table) {
+		return studentT975Table[degreesOfFreedom-1]
+	}
+	return 1.960
+}
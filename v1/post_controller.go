@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// PostController ...
+type PostController interface {
+	GetPosts() http.Handler
+	CreatePost() http.Handler
+	ImportPosts() http.Handler
+	DeletePosts() http.Handler
+	GetNamedTagListRanking() http.Handler
+	GetTagRanking() http.Handler
+}
+
+type postController struct {
+	logger         Logger
+	postRepository PostRepository
+	postService    PostService
+}
+
+func (c *postController) GetPosts() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			buckets := r.URL.Query()["bucket"]
+			if len(buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			}
+
+			posts, err := c.postRepository.FindAll(r.Context(), buckets)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(posts)
+		},
+	)
+}
+
+func (c *postController) CreatePost() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var (
+				post *Post
+				err  error
+			)
+			if json.NewDecoder(r.Body).Decode(&post) != nil || post == nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if post, err = c.postService.Create(r.Context(), bucket, *post); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(post)
+			}
+		},
+	)
+}
+
+func (c *postController) ImportPosts() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			if posts, err := c.postService.Import(r.Context(), bucket, r.Body); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(posts)
+			}
+		},
+	)
+}
+
+func (c *postController) DeletePosts() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) < 1 {
+				writeBadRequest(rw, "id query parameter is required")
+				return
+			}
+
+			if err := c.postRepository.DeleteByIds(r.Context(), ids); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+func (c *postController) GetNamedTagListRanking() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, metric, by, ok := rankingParameters(rw, r)
+			if !ok {
+				return
+			}
+
+			if ranking, err := c.postService.RankNamedTagLists(r.Context(), bucket, metric, by); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				json.NewEncoder(rw).Encode(ranking)
+			}
+		},
+	)
+}
+
+func (c *postController) GetTagRanking() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, metric, by, ok := rankingParameters(rw, r)
+			if !ok {
+				return
+			}
+
+			if ranking, err := c.postService.RankTags(r.Context(), bucket, metric, by); err != nil {
+				c.writeServiceError(rw, r, err)
+			} else {
+				json.NewEncoder(rw).Encode(ranking)
+			}
+		},
+	)
+}
+
+func (c *postController) writeServiceError(rw http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrInvalidPost) || errors.Is(err, ErrUnknownNamedTagList) {
+		writeBadRequest(rw, err.Error())
+	} else {
+		writeServerError(rw, r, c.logger, err)
+	}
+}
+
+// NewPostController ...
+func NewPostController(
+	logger Logger,
+	postRepository PostRepository,
+	postService PostService,
+) PostController {
+	return &postController{
+		logger,
+		postRepository,
+		postService,
+	}
+}
+
+func rankingParameters(rw http.ResponseWriter, r *http.Request) (string, string, string, bool) {
+	bucket, ok := singleBucket(rw, r)
+	if !ok {
+		return "", "", "", false
+	}
+
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = "reach"
+	} else if _, ok := PostMetrics[metric]; !ok {
+		writeBadRequest(rw, "metric must be likes, reach, saves or impressions")
+		return "", "", "", false
+	}
+
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "mean"
+	} else if by != "mean" && by != "median" {
+		writeBadRequest(rw, "by must be mean or median")
+		return "", "", "", false
+	}
+
+	return bucket, metric, by, true
+}
@@ -0,0 +1,355 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubPostRepositoryForController struct {
+	PostRepository
+
+	withBuckets []string
+	withIds     []string
+	willError   string
+
+	err error
+}
+
+func (r *stubPostRepositoryForController) FindAll(ctx context.Context, buckets []string) ([]Post, error) {
+	requestMatched := reflect.DeepEqual(buckets, r.withBuckets)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got buckets %v want %v", buckets, r.withBuckets)
+	}
+	if requestMatched == (r.willError == "FindAll") {
+		return nil, errors.New("there was an error")
+	}
+	return []Post{{ID: "1", NamedTagListIDs: []string{}, Tags: []string{"#windy"}, Reach: 100}}, nil
+}
+
+func (r *stubPostRepositoryForController) DeleteByIds(ctx context.Context, ids []string) error {
+	requestMatched := reflect.DeepEqual(ids, r.withIds)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got ids %v want %v", ids, r.withIds)
+	}
+	if requestMatched == (r.willError == "DeleteByIds") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+type stubPostService struct {
+	withBucket string
+	withPost   Post
+	withCSV    string
+	withMetric string
+	withBy     string
+	willError  error
+
+	err error
+}
+
+func (s *stubPostService) Create(ctx context.Context, bucket string, post Post) (*Post, error) {
+	if bucket != s.withBucket || !reflect.DeepEqual(post, s.withPost) {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got post %+v want %+v", bucket, s.withBucket, post, s.withPost)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	post.ID = "1"
+	return &post, nil
+}
+
+func (s *stubPostService) Import(ctx context.Context, bucket string, reader io.Reader) ([]Post, error) {
+	body, _ := ioutil.ReadAll(reader)
+	if bucket != s.withBucket || string(body) != s.withCSV {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got csv %q want %q", bucket, s.withBucket, body, s.withCSV)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return []Post{{ID: "1"}}, nil
+}
+
+func (s *stubPostService) RankNamedTagLists(ctx context.Context, bucket string, metric string, by string) ([]NamedTagListPerformance, error) {
+	if bucket != s.withBucket || metric != s.withMetric || by != s.withBy {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got metric %s want %s got by %s want %s", bucket, s.withBucket, metric, s.withMetric, by, s.withBy)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return []NamedTagListPerformance{{ID: "1", Name: "beach", Performance: Performance{Posts: 1, Mean: 100, Median: 100}}}, nil
+}
+
+func (s *stubPostService) RankTags(ctx context.Context, bucket string, metric string, by string) ([]TagPerformance, error) {
+	if bucket != s.withBucket || metric != s.withMetric || by != s.withBy {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got metric %s want %s got by %s want %s", bucket, s.withBucket, metric, s.withMetric, by, s.withBy)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return []TagPerformance{{Tag: "#windy", Performance: Performance{Posts: 1, Mean: 100, Median: 100}}}, nil
+}
+
+func TestPostController(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		repository := &stubPostRepositoryForController{withBuckets: []string{"bucket"}}
+		controller := NewPostController(stubLoggerNew(), repository, &stubPostService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/posts?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetPosts().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotPosts []Post
+		if err := json.NewDecoder(response.Body).Decode(&gotPosts); err != nil {
+			t.Fatal(err)
+		}
+
+		wantPosts := []Post{{ID: "1", NamedTagListIDs: []string{}, Tags: []string{"#windy"}, Reach: 100}}
+
+		if !reflect.DeepEqual(gotPosts, wantPosts) {
+			t.Errorf("got posts %+v want %+v", gotPosts, wantPosts)
+		}
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		service := &stubPostService{
+			withBucket: "bucket",
+			withPost:   Post{NamedTagListIDs: []string{"2"}, Likes: 5},
+		}
+		controller := NewPostController(stubLoggerNew(), &stubPostRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/posts?bucket=bucket", strings.NewReader("{\"namedTagListIds\":[\"2\"],\"likes\":5}"))
+		response := httptest.NewRecorder()
+		controller.CreatePost().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST with unknown list", func(t *testing.T) {
+		controller := NewPostController(
+			stubLoggerNew(),
+			&stubPostRepositoryForController{},
+			&stubPostService{willError: fmt.Errorf("%w: %s", ErrUnknownNamedTagList, "2")},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/posts?bucket=bucket", strings.NewReader("{\"namedTagListIds\":[\"2\"]}"))
+		response := httptest.NewRecorder()
+		controller.CreatePost().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST import", func(t *testing.T) {
+		service := &stubPostService{
+			withBucket: "bucket",
+			withCSV:    "tags,likes\n#tdd,5\n",
+		}
+		controller := NewPostController(stubLoggerNew(), &stubPostRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/posts/import?bucket=bucket", strings.NewReader("tags,likes\n#tdd,5\n"))
+		response := httptest.NewRecorder()
+		controller.ImportPosts().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST with negative metric", func(t *testing.T) {
+		for _, scenario := range []struct {
+			path    string
+			body    string
+			handler func(PostController) http.Handler
+		}{
+			{"/posts?bucket=bucket", "{\"tags\":[\"#tdd\"],\"likes\":-1}", PostController.CreatePost},
+			{"/posts/import?bucket=bucket", "tags,likes\n#tdd,-1\n", PostController.ImportPosts},
+		} {
+			controller := NewPostController(
+				stubLoggerNew(),
+				&stubPostRepositoryForController{},
+				&stubPostService{willError: fmt.Errorf("%w: likes must not be negative", ErrInvalidPost)},
+			)
+
+			request, _ := http.NewRequest(http.MethodPost, scenario.path, strings.NewReader(scenario.body))
+			response := httptest.NewRecorder()
+			scenario.handler(controller).ServeHTTP(response, request)
+
+			if gotStatusCode := response.Result().StatusCode; gotStatusCode != 400 {
+				t.Errorf("%s: got status code %d want 400", scenario.path, gotStatusCode)
+			}
+			if gotBody, wantBody := response.Body.String(), "{\"error\":\"invalid post: likes must not be negative\"}\n"; gotBody != wantBody {
+				t.Errorf("%s: got body %s want %s", scenario.path, gotBody, wantBody)
+			}
+		}
+	})
+
+	t.Run("POST import when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewPostController(
+			logger,
+			&stubPostRepositoryForController{},
+			&stubPostService{willError: errors.New("there was an error")},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/posts/import?bucket=bucket", strings.NewReader(""))
+		response := httptest.NewRecorder()
+		controller.ImportPosts().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		repository := &stubPostRepositoryForController{withIds: []string{"1"}}
+		controller := NewPostController(stubLoggerNew(), repository, &stubPostService{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/posts?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.DeletePosts().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET named tag list ranking", func(t *testing.T) {
+		service := &stubPostService{withBucket: "bucket", withMetric: "reach", withBy: "mean"}
+		controller := NewPostController(stubLoggerNew(), &stubPostRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodGet, "/analytics/namedTagLists?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetNamedTagListRanking().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotRanking []NamedTagListPerformance
+		if err := json.NewDecoder(response.Body).Decode(&gotRanking); err != nil {
+			t.Fatal(err)
+		}
+
+		wantRanking := []NamedTagListPerformance{{ID: "1", Name: "beach", Performance: Performance{Posts: 1, Mean: 100, Median: 100}}}
+
+		if !reflect.DeepEqual(gotRanking, wantRanking) {
+			t.Errorf("got ranking %+v want %+v", gotRanking, wantRanking)
+		}
+	})
+
+	t.Run("GET tag ranking", func(t *testing.T) {
+		service := &stubPostService{withBucket: "bucket", withMetric: "likes", withBy: "median"}
+		controller := NewPostController(stubLoggerNew(), &stubPostRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodGet, "/analytics/tags?bucket=bucket&metric=likes&by=median", nil)
+		response := httptest.NewRecorder()
+		controller.GetTagRanking().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET ranking with bad query parameters", func(t *testing.T) {
+		for _, scenario := range []struct {
+			query            string
+			wantResponseBody map[string]string
+		}{
+			{query: "", wantResponseBody: map[string]string{"error": "bucket query parameter is required"}},
+			{query: "bucket=bucket&metric=shares", wantResponseBody: map[string]string{"error": "metric must be likes, reach, saves or impressions"}},
+			{query: "bucket=bucket&by=mode", wantResponseBody: map[string]string{"error": "by must be mean or median"}},
+		} {
+			controller := NewPostController(stubLoggerNew(), &stubPostRepositoryForController{}, &stubPostService{})
+
+			request, _ := http.NewRequest(http.MethodGet, "/analytics/tags?"+scenario.query, nil)
+			response := httptest.NewRecorder()
+			controller.GetTagRanking().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			var gotResponseBody map[string]string
+			if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotResponseBody, scenario.wantResponseBody) {
+				t.Errorf("got response body %+v want %+v", gotResponseBody, scenario.wantResponseBody)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// PostRepository ...
+type PostRepository interface {
+	FindAll(ctx context.Context, buckets []string) ([]Post, error)
+	Create(ctx context.Context, bucket string, posts []Post) error
+	DeleteByIds(ctx context.Context, ids []string) error
+}
+
+type postRepository struct {
+	db database
+}
+
+func (r *postRepository) FindAll(ctx context.Context, buckets []string) ([]Post, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindAll", "select \"id\", \"named_tag_list_ids\", \"tags\", \"posted_at\", \"likes\", \"reach\", \"saves\", \"impressions\" from posts where \"bucket\" = ANY($1) order by \"posted_at\"", buckets); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []Post{}
+
+	var post Post
+	for rows.Next() {
+		if err = rows.Scan(&post.ID, &post.NamedTagListIDs, &post.Tags, &post.PostedAt, &post.Likes, &post.Reach, &post.Saves, &post.Impressions); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (r *postRepository) Create(ctx context.Context, bucket string, posts []Post) error {
+	batch := &pgx.Batch{}
+	for _, post := range posts {
+		batch.Queue(
+			"insert into posts (\"id\", \"bucket\", \"named_tag_list_ids\", \"tags\", \"posted_at\", \"likes\", \"reach\", \"saves\", \"impressions\") values ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+			post.ID,
+			bucket,
+			post.NamedTagListIDs,
+			post.Tags,
+			post.PostedAt,
+			post.Likes,
+			post.Reach,
+			post.Saves,
+			post.Impressions,
+		)
+	}
+	return r.db.write(ctx, "Create", func(ctx context.Context, tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *postRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteByIds",
+		"delete from posts where \"id\" = ANY($1)",
+		ids,
+	)
+}
+
+// NewPostRepository ...
+func NewPostRepository(pool *pgxpool.Pool, timeouts Timeouts) PostRepository {
+	return &postRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestPostRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	older := Post{
+		ID:              "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		NamedTagListIDs: []string{"39abb8d4-3ac2-4f6f-ae5c-40e4382893d4"},
+		Tags:            []string{"#windy", "#tdd"},
+		PostedAt:        time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC),
+		Likes:           1,
+		Reach:           2,
+		Saves:           3,
+		Impressions:     4,
+	}
+	newer := Post{
+		ID:              "a5a5acbf-1541-4fd8-bf9a-343b75b8550f",
+		NamedTagListIDs: []string{},
+		Tags:            []string{"#beach"},
+		PostedAt:        time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("create posts", func(t *testing.T) {
+		assertutil.NotError(t, NewPostRepository(pool, Timeouts{}).Create(context.Background(), "blue", []Post{newer, older}))
+
+		got, err := NewPostRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
+		assertutil.NotError(t, err)
+		for i := range got {
+			got[i].PostedAt = got[i].PostedAt.UTC()
+		}
+		want := []Post{older, newer}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if got, err = NewPostRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"red"}); err != nil {
+			t.Fatal(err)
+		}
+		want = []Post{}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("delete post by id", func(t *testing.T) {
+		assertutil.NotError(t, NewPostRepository(pool, Timeouts{}).DeleteByIds(context.Background(), []string{older.ID}))
+
+		got, err := NewPostRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
+		assertutil.NotError(t, err)
+		for i := range got {
+			got[i].PostedAt = got[i].PostedAt.UTC()
+		}
+		want := []Post{newer}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
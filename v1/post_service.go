@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPost ...
+var ErrInvalidPost = errors.New("invalid post")
+
+// PostService ...
+type PostService interface {
+	Create(ctx context.Context, bucket string, post Post) (*Post, error)
+	Import(ctx context.Context, bucket string, reader io.Reader) ([]Post, error)
+	RankNamedTagLists(ctx context.Context, bucket string, metric string, by string) ([]NamedTagListPerformance, error)
+	RankTags(ctx context.Context, bucket string, metric string, by string) ([]TagPerformance, error)
+}
+
+type postService struct {
+	postRepository         PostRepository
+	namedTagListRepository NamedTagListRepository
+	uuidGenerator          UUIDGenerator
+}
+
+func (s *postService) Create(ctx context.Context, bucket string, post Post) (*Post, error) {
+	posts, err := s.prepare(ctx, bucket, []Post{post})
+	if err != nil {
+		return nil, err
+	}
+	return &posts[0], s.postRepository.Create(ctx, bucket, posts)
+}
+
+// Import reads posts from CSV with a header row. The recognised columns are
+// namedTagListIds and tags (both space separated), postedAt (RFC 3339),
+// likes, reach, saves and impressions.
+func (s *postService) Import(ctx context.Context, bucket string, reader io.Reader) ([]Post, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPost, err)
+	} else if len(records) < 1 {
+		return nil, fmt.Errorf("%w: header row is required", ErrInvalidPost)
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		columns[strings.TrimSpace(column)] = i
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	posts := []Post{}
+	for line, record := range records[1:] {
+		post := Post{
+			NamedTagListIDs: strings.Fields(field(record, "namedTagListIds")),
+			Tags:            strings.Fields(field(record, "tags")),
+		}
+		if value := field(record, "postedAt"); value != "" {
+			if post.PostedAt, err = time.Parse(time.RFC3339, value); err != nil {
+				return nil, fmt.Errorf("%w: line %d: postedAt must be RFC 3339", ErrInvalidPost, line+2)
+			}
+		}
+		for column, metric := range map[string]*int{
+			"likes":       &post.Likes,
+			"reach":       &post.Reach,
+			"saves":       &post.Saves,
+			"impressions": &post.Impressions,
+		} {
+			if value := field(record, column); value != "" {
+				if *metric, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("%w: line %d: %s must be an integer", ErrInvalidPost, line+2, column)
+				}
+			}
+		}
+		posts = append(posts, post)
+	}
+
+	if posts, err = s.prepare(ctx, bucket, posts); err != nil {
+		return nil, err
+	}
+	return posts, s.postRepository.Create(ctx, bucket, posts)
+}
+
+// prepare checks posts, assigns ids and snapshots the current tags of the referenced lists
+// for any post that does not carry its own tags.
+func (s *postService) prepare(ctx context.Context, bucket string, posts []Post) ([]Post, error) {
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]NamedTagList{}
+	for _, namedTagList := range namedTagLists {
+		byID[namedTagList.ID] = namedTagList
+	}
+
+	for i := range posts {
+		post := &posts[i]
+		if len(post.NamedTagListIDs) < 1 && len(post.Tags) < 1 {
+			return nil, fmt.Errorf("%w: namedTagListIds or tags are required", ErrInvalidPost)
+		}
+		for _, metric := range []struct {
+			name  string
+			value int
+		}{{"likes", post.Likes}, {"reach", post.Reach}, {"saves", post.Saves}, {"impressions", post.Impressions}} {
+			if metric.value < 0 {
+				return nil, fmt.Errorf("%w: %s must not be negative", ErrInvalidPost, metric.name)
+			}
+		}
+		if post.NamedTagListIDs == nil {
+			post.NamedTagListIDs = []string{}
+		}
+		snapshot := []string{}
+		for _, id := range post.NamedTagListIDs {
+			namedTagList, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownNamedTagList, id)
+			}
+			snapshot = append(snapshot, namedTagList.Tags...)
+		}
+		if len(post.Tags) < 1 {
+			post.Tags = uniqueTags(snapshot)
+		}
+		if post.PostedAt.IsZero() {
+			post.PostedAt = time.Now().UTC()
+		}
+		post.ID = s.uuidGenerator.Generate()
+	}
+	return posts, nil
+}
+
+func (s *postService) RankNamedTagLists(ctx context.Context, bucket string, metric string, by string) ([]NamedTagListPerformance, error) {
+	value, ok := PostMetrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown metric %s", ErrInvalidPost, metric)
+	}
+
+	posts, err := s.postRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, namedTagList := range namedTagLists {
+		names[namedTagList.ID] = namedTagList.Name
+	}
+
+	values := map[string][]float64{}
+	for _, post := range posts {
+		for _, id := range uniqueTags(post.NamedTagListIDs) {
+			values[id] = append(values[id], value(post))
+		}
+	}
+
+	ranking := []NamedTagListPerformance{}
+	for id, v := range values {
+		ranking = append(ranking, NamedTagListPerformance{ID: id, Name: names[id], Performance: NewPerformance(v)})
+	}
+	sort.Slice(ranking, func(a, b int) bool {
+		return ranksAbove(ranking[a].Performance, ranking[b].Performance, by, ranking[a].ID < ranking[b].ID)
+	})
+	return ranking, nil
+}
+
+func (s *postService) RankTags(ctx context.Context, bucket string, metric string, by string) ([]TagPerformance, error) {
+	value, ok := PostMetrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown metric %s", ErrInvalidPost, metric)
+	}
+
+	posts, err := s.postRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string][]float64{}
+	for _, post := range posts {
+		for _, tag := range uniqueTags(post.Tags) {
+			values[tag] = append(values[tag], value(post))
+		}
+	}
+
+	ranking := []TagPerformance{}
+	for tag, v := range values {
+		ranking = append(ranking, TagPerformance{Tag: tag, Performance: NewPerformance(v)})
+	}
+	sort.Slice(ranking, func(a, b int) bool {
+		return ranksAbove(ranking[a].Performance, ranking[b].Performance, by, ranking[a].Tag < ranking[b].Tag)
+	})
+	return ranking, nil
+}
+
+// NewPostService ...
+func NewPostService(
+	postRepository PostRepository,
+	namedTagListRepository NamedTagListRepository,
+	uuidGenerator UUIDGenerator,
+) PostService {
+	return &postService{
+		postRepository,
+		namedTagListRepository,
+		uuidGenerator,
+	}
+}
+
+func ranksAbove(a, b Performance, by string, tieBreak bool) bool {
+	av, bv := a.Mean, b.Mean
+	if by == "median" {
+		av, bv = a.Median, b.Median
+	}
+	if av != bv {
+		return av > bv
+	}
+	return tieBreak
+}
@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubPostRepositoryForService struct {
+	PostRepository
+
+	withPosts []Post
+	willError bool
+
+	created []Post
+}
+
+func (r *stubPostRepositoryForService) FindAll(ctx context.Context, buckets []string) ([]Post, error) {
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	return r.withPosts, nil
+}
+
+func (r *stubPostRepositoryForService) Create(ctx context.Context, bucket string, posts []Post) error {
+	r.created = posts
+	if r.willError {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+func TestPostService(t *testing.T) {
+	postedAt := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	namedTagListRepository := &stubNamedTagListRepositoryForRotation{
+		withBucket: "bucket",
+		withNamedTagLists: []NamedTagList{
+			{ID: "1", Name: "beach", Tags: []string{"#beach", "#windy"}},
+			{ID: "2", Name: "code", Tags: []string{"#tdd", "#windy"}},
+		},
+	}
+
+	t.Run("create snapshots list tags", func(t *testing.T) {
+		repository := &stubPostRepositoryForService{}
+		service := NewPostService(repository, namedTagListRepository, &stubUUIDGenerator{response: "3"})
+
+		got, err := service.Create(context.Background(), "bucket", Post{NamedTagListIDs: []string{"1", "2"}, PostedAt: postedAt, Reach: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Post{
+			ID:              "3",
+			NamedTagListIDs: []string{"1", "2"},
+			Tags:            []string{"#beach", "#windy", "#tdd"},
+			PostedAt:        postedAt,
+			Reach:           100,
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if !reflect.DeepEqual(repository.created, []Post{*want}) {
+			t.Errorf("got created %+v want %+v", repository.created, []Post{*want})
+		}
+	})
+
+	t.Run("create with unknown list", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{}, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Create(context.Background(), "bucket", Post{NamedTagListIDs: []string{"9"}})
+
+		if !errors.Is(gotErr, ErrUnknownNamedTagList) {
+			t.Errorf("got error %v want %v", gotErr, ErrUnknownNamedTagList)
+		}
+	})
+
+	t.Run("create without lists or tags", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{}, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Create(context.Background(), "bucket", Post{})
+
+		if !errors.Is(gotErr, ErrInvalidPost) {
+			t.Errorf("got error %v want %v", gotErr, ErrInvalidPost)
+		}
+	})
+
+	t.Run("create with negative metric", func(t *testing.T) {
+		repository := &stubPostRepositoryForService{}
+		service := NewPostService(repository, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Create(context.Background(), "bucket", Post{Tags: []string{"#tdd"}, Saves: -1})
+
+		wantErr := "invalid post: saves must not be negative"
+
+		if !errors.Is(gotErr, ErrInvalidPost) || gotErr.Error() != wantErr {
+			t.Errorf("got error %v want %s", gotErr, wantErr)
+		}
+		if repository.created != nil {
+			t.Errorf("got created %+v want none", repository.created)
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		repository := &stubPostRepositoryForService{}
+		service := NewPostService(repository, namedTagListRepository, &stubUUIDGenerator{response: "3"})
+
+		got, err := service.Import(context.Background(), "bucket", strings.NewReader(
+			"postedAt,namedTagListIds,tags,likes,reach\n"+
+				"2020-11-01T12:00:00Z,1,,5,100\n"+
+				"2020-11-01T12:00:00Z,,#tdd #go,7,\n",
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Post{
+			{ID: "3", NamedTagListIDs: []string{"1"}, Tags: []string{"#beach", "#windy"}, PostedAt: postedAt, Likes: 5, Reach: 100},
+			{ID: "3", NamedTagListIDs: []string{}, Tags: []string{"#tdd", "#go"}, PostedAt: postedAt, Likes: 7},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if !reflect.DeepEqual(repository.created, want) {
+			t.Errorf("got created %+v want %+v", repository.created, want)
+		}
+	})
+
+	t.Run("import with malformed metric", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{}, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Import(context.Background(), "bucket", strings.NewReader("tags,likes\n#tdd,lots\n"))
+
+		wantErr := "invalid post: line 2: likes must be an integer"
+
+		if gotErr == nil || gotErr.Error() != wantErr {
+			t.Errorf("got error %v want %s", gotErr, wantErr)
+		}
+	})
+
+	t.Run("import with negative metric", func(t *testing.T) {
+		repository := &stubPostRepositoryForService{}
+		service := NewPostService(repository, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.Import(context.Background(), "bucket", strings.NewReader("tags,likes,reach\n#tdd,5,100\n#go,3,-100\n"))
+
+		wantErr := "invalid post: reach must not be negative"
+
+		if !errors.Is(gotErr, ErrInvalidPost) || gotErr.Error() != wantErr {
+			t.Errorf("got error %v want %s", gotErr, wantErr)
+		}
+		if repository.created != nil {
+			t.Errorf("got created %+v want none", repository.created)
+		}
+	})
+
+	posts := []Post{
+		{NamedTagListIDs: []string{"1"}, Tags: []string{"#beach", "#windy"}, Reach: 100},
+		{NamedTagListIDs: []string{"1"}, Tags: []string{"#beach", "#windy"}, Reach: 300},
+		{NamedTagListIDs: []string{"2"}, Tags: []string{"#tdd", "#windy"}, Reach: 150},
+	}
+
+	t.Run("rank named tag lists", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{withPosts: posts}, namedTagListRepository, &stubUUIDGenerator{})
+
+		got, err := service.RankNamedTagLists(context.Background(), "bucket", "reach", "mean")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []NamedTagListPerformance{
+			{ID: "1", Name: "beach", Performance: NewPerformance([]float64{100, 300})},
+			{ID: "2", Name: "code", Performance: NewPerformance([]float64{150})},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("rank tags", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{withPosts: posts}, namedTagListRepository, &stubUUIDGenerator{})
+
+		got, err := service.RankTags(context.Background(), "bucket", "reach", "median")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagPerformance{
+			{Tag: "#beach", Performance: NewPerformance([]float64{100, 300})},
+			{Tag: "#tdd", Performance: NewPerformance([]float64{150})},
+			{Tag: "#windy", Performance: NewPerformance([]float64{100, 300, 150})},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("rank when repository has error", func(t *testing.T) {
+		service := NewPostService(&stubPostRepositoryForService{willError: true}, namedTagListRepository, &stubUUIDGenerator{})
+
+		_, gotErr := service.RankTags(context.Background(), "bucket", "reach", "mean")
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
+
+func TestPerformance(t *testing.T) {
+	got := NewPerformance([]float64{300, 100, 200})
+
+	if got.Posts != 3 || got.Mean != 200 || got.Median != 200 {
+		t.Errorf("got %+v want 3 posts with mean 200 and median 200", got)
+	}
+
+	margin := 4.303 * 100 / math.Sqrt(3)
+	want := []float64{200 - margin, 200 + margin}
+
+	if !reflect.DeepEqual(got.ConfidenceInterval, want) {
+		t.Errorf("got confidence interval %v want %v", got.ConfidenceInterval, want)
+	}
+
+	if got := NewPerformance([]float64{5}); got.ConfidenceInterval != nil {
+		t.Errorf("got confidence interval %v want nil", got.ConfidenceInterval)
+	}
+}
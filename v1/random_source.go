@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RandomSource ...
+type RandomSource interface {
+	Intn(n int) int
+}
+
+type randomSource struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+func (s *randomSource) Intn(n int) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.rand.Intn(n)
+}
+
+// NewRandomSource ...
+func NewRandomSource() RandomSource {
+	return &randomSource{
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
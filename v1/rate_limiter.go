@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket holding up to Burst requests and refilling at
+// PerSecond.
+type RateLimit struct {
+	Burst     int
+	PerSecond float64
+}
+
+// RateLimiter ...
+type RateLimiter interface {
+	Handler(next http.Handler) http.Handler
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter gives every caller separate read and write budgets. Callers are
+// told apart by API key or user, and anonymous requests by client IP. With
+// byAddress every request is told apart by client IP, so that the limiter can
+// sit in front of authentication.
+type rateLimiter struct {
+	read              RateLimit
+	write             RateLimit
+	trustForwardedFor bool
+	byAddress         bool
+	now               func() time.Time
+
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func (l *rateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			limit, budget := l.write, "write"
+			if readOnly(r) {
+				limit, budget = l.read, "read"
+			}
+
+			remaining, wait := l.take(budget+" "+l.clientKey(r), limit)
+
+			rw.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			rw.Header().Set("RateLimit-Remaining", strconv.Itoa(int(remaining)))
+			rw.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(limit.Burst)-remaining)/limit.PerSecond))))
+			if wait > 0 {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeError(rw, http.StatusTooManyRequests, "rate limit exceeded for "+budget+" requests")
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		},
+	)
+}
+
+// take spends a token if there is one, returning the tokens left, or else
+// how long until the next token is due.
+func (l *rateLimiter) take(key string, limit RateLimit) (float64, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{float64(limit.Burst), now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*limit.PerSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return bucket.tokens, time.Duration((1 - bucket.tokens) / limit.PerSecond * float64(time.Second))
+	}
+	bucket.tokens--
+	return bucket.tokens, 0
+}
+
+// sweep forgets buckets that have had time to refill completely, since a new
+// bucket starts out full anyway. This keeps one-off clients from piling up.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(float64(time.Second) * math.Max(
+		float64(l.read.Burst)/l.read.PerSecond,
+		float64(l.write.Burst)/l.write.PerSecond,
+	))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (l *rateLimiter) clientKey(r *http.Request) string {
+	if caller := CallerFromContext(r.Context()); caller != nil && !l.byAddress {
+		if caller.APIKey != nil {
+			return "key:" + caller.APIKey.ID
+		} else if caller.User != nil {
+			return "user:" + caller.User.ID
+		}
+	}
+
+	return "ip:" + clientIP(r, l.trustForwardedFor)
+}
+
+// NewRateLimiter ...
+func NewRateLimiter(read RateLimit, write RateLimit, trustForwardedFor bool) RateLimiter {
+	return &rateLimiter{
+		read:              read,
+		write:             write,
+		trustForwardedFor: trustForwardedFor,
+		now:               time.Now,
+		buckets:           map[string]*tokenBucket{},
+	}
+}
+
+// NewAddressRateLimiter limits every request by client IP alone, whoever it
+// claims to be, so that requests with bad credentials cost nothing past it.
+func NewAddressRateLimiter(limit RateLimit, trustForwardedFor bool) RateLimiter {
+	return &rateLimiter{
+		read:              limit,
+		write:             limit,
+		trustForwardedFor: trustForwardedFor,
+		byAddress:         true,
+		now:               time.Now,
+		buckets:           map[string]*tokenBucket{},
+	}
+}
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	limiter := &rateLimiter{
+		read:    RateLimit{Burst: 3, PerSecond: 1},
+		write:   RateLimit{Burst: 1, PerSecond: 0.5},
+		now:     func() time.Time { return now },
+		buckets: map[string]*tokenBucket{},
+	}
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(method string, remoteAddr string, caller *Caller) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest(method, "/namedTagLists?bucket=blue", nil)
+		request.RemoteAddr = remoteAddr
+		if caller != nil {
+			request = request.WithContext(WithCaller(request.Context(), caller))
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("spends the read budget then refuses", func(t *testing.T) {
+		for _, wantRemaining := range []string{"2", "1", "0"} {
+			response := serve(http.MethodGet, "10.0.0.1:1234", nil)
+
+			if response.Code != 200 || response.Header().Get("RateLimit-Remaining") != wantRemaining {
+				t.Errorf("got status code %d remaining %s want 200 remaining %s", response.Code, response.Header().Get("RateLimit-Remaining"), wantRemaining)
+			}
+		}
+
+		response := serve(http.MethodGet, "10.0.0.1:5678", nil)
+
+		if response.Code != 429 {
+			t.Errorf("got status code %d want 429", response.Code)
+		}
+
+		for header, want := range map[string]string{"RateLimit-Limit": "3", "RateLimit-Remaining": "0", "RateLimit-Reset": "3", "Retry-After": "1"} {
+			if got := response.Header().Get(header); got != want {
+				t.Errorf("got %s %s want %s", header, got, want)
+			}
+		}
+
+		if gotBody, wantBody := response.Body.String(), "{\"error\":\"rate limit exceeded for read requests\"}\n"; gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("keeps a separate write budget", func(t *testing.T) {
+		if response := serve(http.MethodPost, "10.0.0.1:1234", nil); response.Code != 200 {
+			t.Errorf("got status code %d want 200", response.Code)
+		}
+
+		response := serve(http.MethodPost, "10.0.0.1:1234", nil)
+
+		if response.Code != 429 || response.Header().Get("Retry-After") != "2" {
+			t.Errorf("got status code %d retry after %s want 429 retry after 2", response.Code, response.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("charges suggestions to the read budget", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/suggest?bucket=blue", nil)
+		request.RemoteAddr = "10.0.0.3:1234"
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+
+		if response.Code != 200 || response.Header().Get("RateLimit-Limit") != "3" {
+			t.Errorf("got status code %d limit %s want 200 limit 3", response.Code, response.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("keys callers apart from their address", func(t *testing.T) {
+		for _, caller := range []*Caller{{APIKey: &APIKey{ID: "1"}}, {User: &User{ID: "1"}}} {
+			if response := serve(http.MethodGet, "10.0.0.1:1234", caller); response.Code != 200 {
+				t.Errorf("got status code %d for %+v want 200", response.Code, caller)
+			}
+		}
+
+		if response := serve(http.MethodGet, "10.0.0.2:1234", nil); response.Code != 200 {
+			t.Errorf("got status code %d want 200", response.Code)
+		}
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		now = now.Add(time.Second)
+
+		if response := serve(http.MethodGet, "10.0.0.1:1234", nil); response.Code != 200 {
+			t.Errorf("got status code %d want 200", response.Code)
+		}
+	})
+
+	t.Run("forgets idle clients", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		serve(http.MethodGet, "10.0.0.3:1234", nil)
+
+		if len(limiter.buckets) != 1 {
+			t.Errorf("got %d buckets want 1", len(limiter.buckets))
+		}
+	})
+
+	t.Run("trusts X-Forwarded-For only when told to", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7")
+
+		if got := limiter.clientKey(request); got != "ip:10.0.0.1" {
+			t.Errorf("got %s want ip:10.0.0.1", got)
+		}
+
+		limiter.trustForwardedFor = true
+		if got := limiter.clientKey(request); got != "ip:203.0.113.7" {
+			t.Errorf("got %s want ip:203.0.113.7", got)
+		}
+	})
+
+	t.Run("believes only the entry the proxy appended", func(t *testing.T) {
+		for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2"} {
+			request, _ := http.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Add("X-Forwarded-For", forwardedFor)
+			request.Header.Add("X-Forwarded-For", "203.0.113.7")
+
+			if got := limiter.clientKey(request); got != "ip:203.0.113.7" {
+				t.Errorf("%s: got %s want ip:203.0.113.7", forwardedFor, got)
+			}
+		}
+	})
+
+	t.Run("limits by address in front of authentication", func(t *testing.T) {
+		addressLimiter := NewAddressRateLimiter(RateLimit{Burst: 1, PerSecond: 1}, false).(*rateLimiter)
+		addressLimiter.now = func() time.Time { return now }
+		handler := addressLimiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		codes := []int{}
+		for _, caller := range []*Caller{{APIKey: &APIKey{ID: "1"}}, {APIKey: &APIKey{ID: "2"}}} {
+			request, _ := http.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = "10.0.0.9:1234"
+			request = request.WithContext(WithCaller(request.Context(), caller))
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+			codes = append(codes, response.Code)
+		}
+
+		if codes[0] != 200 || codes[1] != 429 {
+			t.Errorf("got status codes %v want [200 429]", codes)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type requestInfoContextKey struct{}
+
+// RequestInfo identifies a request in logs and audit events.
+type RequestInfo struct {
+	ID       string
+	ClientIP string
+}
+
+// RequestInfoFromContext returns the zero RequestInfo outside a tagged request.
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoContextKey{}).(RequestInfo)
+	return info
+}
+
+// WithRequestInfo ...
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoContextKey{}, info)
+}
+
+// RequestTagger ...
+type RequestTagger interface {
+	Handler(next http.Handler) http.Handler
+}
+
+type requestTagger struct {
+	trustForwardedFor bool
+}
+
+// Handler keeps the X-Request-ID a proxy assigned, or makes one up, and
+// echoes it on the response so that callers can quote it back.
+func (t *requestTagger) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get("X-Request-ID")
+			if !validRequestID(id) {
+				bytes := make([]byte, 16)
+				rand.Read(bytes)
+				id = hex.EncodeToString(bytes)
+			}
+			rw.Header().Set("X-Request-ID", id)
+
+			info := RequestInfo{id, clientIP(r, t.trustForwardedFor)}
+			next.ServeHTTP(rw, r.WithContext(WithRequestInfo(r.Context(), info)))
+		},
+	)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// clientIP takes the address from X-Forwarded-For only when trustForwardedFor
+// is set because the header is otherwise the client's to forge. Even then only
+// the last entry, the one the trusted proxy appended, is believed; anything
+// before it came from the client.
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); trustForwardedFor && len(forwardedFor) > 0 {
+		entries := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+		if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+			return last
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// NewRequestTagger ...
+func NewRequestTagger(trustForwardedFor bool) RequestTagger {
+	return &requestTagger{trustForwardedFor}
+}
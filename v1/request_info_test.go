@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestTagger(t *testing.T) {
+	var got RequestInfo
+	handler := NewRequestTagger(false).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = RequestInfoFromContext(r.Context())
+	}))
+
+	t.Run("keep a request id from upstream", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Request-ID", "abc")
+		request.Header.Set("X-Forwarded-For", "203.0.113.7")
+		response := httptest.NewRecorder()
+
+		handler.ServeHTTP(response, request)
+
+		if want := (RequestInfo{"abc", "10.0.0.1"}); got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if gotHeader := response.Header().Get("X-Request-ID"); gotHeader != "abc" {
+			t.Errorf("got X-Request-ID %s want abc", gotHeader)
+		}
+	})
+
+	t.Run("replace a missing or malformed request id", func(t *testing.T) {
+		for _, id := range []string{"", "has space"} {
+			request, _ := http.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("X-Request-ID", id)
+			response := httptest.NewRecorder()
+
+			handler.ServeHTTP(response, request)
+
+			if len(got.ID) != 32 || response.Header().Get("X-Request-ID") != got.ID {
+				t.Errorf("got id %q and header %q want a generated id in both", got.ID, response.Header().Get("X-Request-ID"))
+			}
+		}
+	})
+}
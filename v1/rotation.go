@@ -0,0 +1,17 @@
+package v1
+
+// Rotation draws Count tags per post from the union of a pool of named tag
+// lists while avoiding the tags issued in the last History posts.
+type Rotation struct {
+	ID              string   `json:"id"`
+	Bucket          string   `json:"bucket"`
+	NamedTagListIDs []string `json:"namedTagListIds"`
+	Count           int      `json:"count"`
+	History         int      `json:"history"`
+}
+
+// RotationIssue ...
+type RotationIssue struct {
+	Tags    []string `json:"tags"`
+	Overlap int      `json:"overlap"`
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RotationController ...
+type RotationController interface {
+	GetRotations() http.Handler
+	CreateRotation() http.Handler
+	DeleteRotations() http.Handler
+	GetNext() http.Handler
+}
+
+type rotationController struct {
+	logger             Logger
+	rotationRepository RotationRepository
+	rotationService    RotationService
+}
+
+func (c *rotationController) GetRotations() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			buckets := r.URL.Query()["bucket"]
+			if len(buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			}
+
+			rotations, err := c.rotationRepository.FindAll(r.Context(), buckets)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(rotations)
+		},
+	)
+}
+
+func (c *rotationController) CreateRotation() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var (
+				rotation *Rotation
+				err      error
+			)
+			if json.NewDecoder(r.Body).Decode(&rotation) != nil || rotation == nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if len(rotation.NamedTagListIDs) < 1 {
+				writeBadRequest(rw, "namedTagListIds must not be empty")
+			} else if rotation.Count < 1 {
+				writeBadRequest(rw, "count must be a positive integer")
+			} else if rotation.History < 0 {
+				writeBadRequest(rw, "history must be a non-negative integer")
+			} else if rotation, err = c.rotationService.Create(r.Context(), bucket, *rotation); errors.Is(err, ErrUnknownNamedTagList) {
+				writeBadRequest(rw, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(rotation)
+			}
+		},
+	)
+}
+
+func (c *rotationController) DeleteRotations() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) < 1 {
+				writeBadRequest(rw, "id query parameter is required")
+				return
+			}
+
+			if err := c.rotationRepository.DeleteByIds(r.Context(), ids); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+func (c *rotationController) GetNext() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/rotations/")
+			if !strings.HasSuffix(path, "/next") || path == "/next" {
+				http.NotFound(rw, r)
+				return
+			}
+
+			issue, err := c.rotationService.Next(r.Context(), strings.TrimSuffix(path, "/next"))
+			if err == ErrRotationNotFound {
+				writeError(rw, http.StatusNotFound, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				json.NewEncoder(rw).Encode(issue)
+			}
+		},
+	)
+}
+
+// NewRotationController ...
+func NewRotationController(
+	logger Logger,
+	rotationRepository RotationRepository,
+	rotationService RotationService,
+) RotationController {
+	return &rotationController{
+		logger,
+		rotationRepository,
+		rotationService,
+	}
+}
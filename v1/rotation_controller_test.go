@@ -0,0 +1,316 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubRotationRepositoryForController struct {
+	RotationRepository
+
+	withBuckets []string
+	withIds     []string
+	willError   string
+
+	err error
+}
+
+func (r *stubRotationRepositoryForController) FindAll(ctx context.Context, buckets []string) ([]Rotation, error) {
+	requestMatched := reflect.DeepEqual(buckets, r.withBuckets)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got buckets %v want %v", buckets, r.withBuckets)
+	}
+	if requestMatched == (r.willError == "FindAll") {
+		return nil, errors.New("there was an error")
+	}
+	return []Rotation{{ID: "1", Bucket: "bucket", NamedTagListIDs: []string{"2"}, Count: 3, History: 4}}, nil
+}
+
+func (r *stubRotationRepositoryForController) DeleteByIds(ctx context.Context, ids []string) error {
+	requestMatched := reflect.DeepEqual(ids, r.withIds)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got ids %v want %v", ids, r.withIds)
+	}
+	if requestMatched == (r.willError == "DeleteByIds") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+type stubRotationService struct {
+	withBucket   string
+	withRotation Rotation
+	withID       string
+	willError    error
+
+	err error
+}
+
+func (s *stubRotationService) Create(ctx context.Context, bucket string, rotation Rotation) (*Rotation, error) {
+	if bucket != s.withBucket || !reflect.DeepEqual(rotation, s.withRotation) {
+		s.err = fmt.Errorf("Stub got bucket %s want %s got rotation %+v want %+v", bucket, s.withBucket, rotation, s.withRotation)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	rotation.ID = "1"
+	rotation.Bucket = bucket
+	return &rotation, nil
+}
+
+func (s *stubRotationService) Next(ctx context.Context, id string) (*RotationIssue, error) {
+	if id != s.withID {
+		s.err = fmt.Errorf("Stub got id %s want %s", id, s.withID)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &RotationIssue{Tags: []string{"#windy"}, Overlap: 0}, nil
+}
+
+func TestRotationController(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		repository := &stubRotationRepositoryForController{withBuckets: []string{"bucket"}}
+		controller := NewRotationController(stubLoggerNew(), repository, &stubRotationService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/rotations?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetRotations().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotRotations []Rotation
+		if err := json.NewDecoder(response.Body).Decode(&gotRotations); err != nil {
+			t.Fatal(err)
+		}
+
+		wantRotations := []Rotation{{ID: "1", Bucket: "bucket", NamedTagListIDs: []string{"2"}, Count: 3, History: 4}}
+
+		if !reflect.DeepEqual(gotRotations, wantRotations) {
+			t.Errorf("got rotations %+v want %+v", gotRotations, wantRotations)
+		}
+	})
+
+	t.Run("GET when repository has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewRotationController(
+			logger,
+			&stubRotationRepositoryForController{withBuckets: []string{"bucket"}, willError: "FindAll"},
+			&stubRotationService{},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/rotations?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetRotations().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		service := &stubRotationService{
+			withBucket:   "bucket",
+			withRotation: Rotation{NamedTagListIDs: []string{"2"}, Count: 3, History: 4},
+		}
+		controller := NewRotationController(stubLoggerNew(), &stubRotationRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/rotations?bucket=bucket", strings.NewReader("{\"namedTagListIds\":[\"2\"],\"count\":3,\"history\":4}"))
+		response := httptest.NewRecorder()
+		controller.CreateRotation().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotRotation Rotation
+		if err := json.NewDecoder(response.Body).Decode(&gotRotation); err != nil {
+			t.Fatal(err)
+		}
+
+		wantRotation := Rotation{ID: "1", Bucket: "bucket", NamedTagListIDs: []string{"2"}, Count: 3, History: 4}
+
+		if !reflect.DeepEqual(gotRotation, wantRotation) {
+			t.Errorf("got rotation %+v want %+v", gotRotation, wantRotation)
+		}
+	})
+
+	t.Run("POST with invalid rotation", func(t *testing.T) {
+		for _, scenario := range []struct {
+			body             string
+			wantResponseBody map[string]string
+		}{
+			{body: "{\"count\":3}", wantResponseBody: map[string]string{"error": "namedTagListIds must not be empty"}},
+			{body: "{\"namedTagListIds\":[\"2\"]}", wantResponseBody: map[string]string{"error": "count must be a positive integer"}},
+			{body: "{\"namedTagListIds\":[\"2\"],\"count\":3,\"history\":-1}", wantResponseBody: map[string]string{"error": "history must be a non-negative integer"}},
+		} {
+			controller := NewRotationController(stubLoggerNew(), &stubRotationRepositoryForController{}, &stubRotationService{})
+
+			request, _ := http.NewRequest(http.MethodPost, "/rotations?bucket=bucket", strings.NewReader(scenario.body))
+			response := httptest.NewRecorder()
+			controller.CreateRotation().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			var gotResponseBody map[string]string
+			if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotResponseBody, scenario.wantResponseBody) {
+				t.Errorf("got response body %+v want %+v", gotResponseBody, scenario.wantResponseBody)
+			}
+		}
+	})
+
+	t.Run("POST with a list from another bucket", func(t *testing.T) {
+		service := &stubRotationService{
+			withBucket:   "bucket",
+			withRotation: Rotation{NamedTagListIDs: []string{"2"}, Count: 3},
+			willError:    fmt.Errorf("%w: 2", ErrUnknownNamedTagList),
+		}
+		controller := NewRotationController(stubLoggerNew(), &stubRotationRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/rotations?bucket=bucket", strings.NewReader("{\"namedTagListIds\":[\"2\"],\"count\":3}"))
+		response := httptest.NewRecorder()
+		controller.CreateRotation().ServeHTTP(response, request)
+
+		if gotStatusCode := response.Result().StatusCode; gotStatusCode != 400 {
+			t.Errorf("got status code %d want 400", gotStatusCode)
+		}
+		if gotBody, wantBody := response.Body.String(), "{\"error\":\"named tag list is not in bucket: 2\"}\n"; gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		repository := &stubRotationRepositoryForController{withIds: []string{"1"}}
+		controller := NewRotationController(stubLoggerNew(), repository, &stubRotationService{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/rotations?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.DeleteRotations().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET next", func(t *testing.T) {
+		service := &stubRotationService{withID: "1"}
+		controller := NewRotationController(stubLoggerNew(), &stubRotationRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodGet, "/rotations/1/next", nil)
+		response := httptest.NewRecorder()
+		controller.GetNext().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotIssue RotationIssue
+		if err := json.NewDecoder(response.Body).Decode(&gotIssue); err != nil {
+			t.Fatal(err)
+		}
+
+		wantIssue := RotationIssue{Tags: []string{"#windy"}}
+
+		if !reflect.DeepEqual(gotIssue, wantIssue) {
+			t.Errorf("got issue %+v want %+v", gotIssue, wantIssue)
+		}
+	})
+
+	t.Run("GET next when rotation does not exist", func(t *testing.T) {
+		controller := NewRotationController(
+			stubLoggerNew(),
+			&stubRotationRepositoryForController{},
+			&stubRotationService{withID: "1", willError: ErrRotationNotFound},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/rotations/1/next", nil)
+		response := httptest.NewRecorder()
+		controller.GetNext().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 404
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET next when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewRotationController(
+			logger,
+			&stubRotationRepositoryForController{},
+			&stubRotationService{withID: "1", willError: errors.New("there was an error")},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/rotations/1/next", nil)
+		response := httptest.NewRecorder()
+		controller.GetNext().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
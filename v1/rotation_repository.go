@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// RotationRepository ...
+type RotationRepository interface {
+	FindAll(ctx context.Context, buckets []string) ([]Rotation, error)
+	FindByID(ctx context.Context, id string) (*Rotation, error)
+	Create(ctx context.Context, rotation Rotation) error
+	DeleteByIds(ctx context.Context, ids []string) error
+	FindRecentIssues(ctx context.Context, id string, limit int) ([][]string, error)
+	CreateIssue(ctx context.Context, id string, tags []string) error
+}
+
+type rotationRepository struct {
+	db database
+}
+
+func (r *rotationRepository) FindAll(ctx context.Context, buckets []string) ([]Rotation, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindAll", "select \"id\", \"bucket\", \"named_tag_list_ids\", \"count\", \"history\" from rotations where \"bucket\" = ANY($1)", buckets); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rotations := []Rotation{}
+
+	var rotation Rotation
+	for rows.Next() {
+		if err = rows.Scan(&rotation.ID, &rotation.Bucket, &rotation.NamedTagListIDs, &rotation.Count, &rotation.History); err != nil {
+			return nil, err
+		}
+		rotations = append(rotations, rotation)
+	}
+
+	return rotations, rows.Err()
+}
+
+func (r *rotationRepository) FindByID(ctx context.Context, id string) (*Rotation, error) {
+	var rotation Rotation
+	err := r.db.queryRow(
+		ctx,
+		"FindByID",
+		"select \"id\", \"bucket\", \"named_tag_list_ids\", \"count\", \"history\" from rotations where \"id\" = $1",
+		id,
+	).Scan(&rotation.ID, &rotation.Bucket, &rotation.NamedTagListIDs, &rotation.Count, &rotation.History)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+func (r *rotationRepository) Create(ctx context.Context, rotation Rotation) error {
+	return r.db.exec(
+		ctx,
+		"Create",
+		"insert into rotations (\"id\", \"bucket\", \"named_tag_list_ids\", \"count\", \"history\") values ($1, $2, $3, $4, $5)",
+		rotation.ID,
+		rotation.Bucket,
+		rotation.NamedTagListIDs,
+		rotation.Count,
+		rotation.History,
+	)
+}
+
+func (r *rotationRepository) DeleteByIds(ctx context.Context, ids []string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteByIds",
+		"delete from rotations where \"id\" = ANY($1)",
+		ids,
+	)
+}
+
+func (r *rotationRepository) FindRecentIssues(ctx context.Context, id string, limit int) ([][]string, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindRecentIssues", "select \"tags\" from rotation_issues where \"rotation_id\" = $1 order by \"issued_at\" desc limit $2", id, limit); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	issues := [][]string{}
+	for rows.Next() {
+		var tags []string
+		if err = rows.Scan(&tags); err != nil {
+			return nil, err
+		}
+		issues = append(issues, tags)
+	}
+
+	return issues, rows.Err()
+}
+
+func (r *rotationRepository) CreateIssue(ctx context.Context, id string, tags []string) error {
+	return r.db.exec(
+		ctx,
+		"CreateIssue",
+		"insert into rotation_issues (\"rotation_id\", \"tags\") values ($1, $2)",
+		id,
+		tags,
+	)
+}
+
+// NewRotationRepository ...
+func NewRotationRepository(pool *pgxpool.Pool, timeouts Timeouts) RotationRepository {
+	return &rotationRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestRotationRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	rotation := Rotation{
+		ID:              "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		Bucket:          "blue",
+		NamedTagListIDs: []string{"39abb8d4-3ac2-4f6f-ae5c-40e4382893d4"},
+		Count:           30,
+		History:         2,
+	}
+
+	t.Run("create rotation", func(t *testing.T) {
+		assertutil.NotError(t, NewRotationRepository(pool, Timeouts{}).Create(context.Background(), rotation))
+
+		got, err := NewRotationRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
+		assertutil.NotError(t, err)
+		want := []Rotation{rotation}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if got, err = NewRotationRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"red"}); err != nil {
+			t.Fatal(err)
+		}
+		want = []Rotation{}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("find rotation by id", func(t *testing.T) {
+		got, err := NewRotationRepository(pool, Timeouts{}).FindByID(context.Background(), rotation.ID)
+		assertutil.NotError(t, err)
+
+		if !reflect.DeepEqual(got, &rotation) {
+			t.Errorf("got %+v want %+v", got, &rotation)
+		}
+
+		if got, err = NewRotationRepository(pool, Timeouts{}).FindByID(context.Background(), "a5a5acbf-1541-4fd8-bf9a-343b75b8550f"); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != nil {
+			t.Errorf("got %+v want nil", got)
+		}
+	})
+
+	t.Run("recent issues are newest first", func(t *testing.T) {
+		repository := NewRotationRepository(pool, Timeouts{})
+		assertutil.NotError(t, repository.CreateIssue(context.Background(), rotation.ID, []string{"#one"}))
+		assertutil.NotError(t, repository.CreateIssue(context.Background(), rotation.ID, []string{"#two"}))
+		assertutil.NotError(t, repository.CreateIssue(context.Background(), rotation.ID, []string{"#three"}))
+
+		got, err := repository.FindRecentIssues(context.Background(), rotation.ID, 2)
+		assertutil.NotError(t, err)
+		want := [][]string{{"#three"}, {"#two"}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("issues at the same moment are both kept", func(t *testing.T) {
+		repository := NewRotationRepository(pool, Timeouts{})
+		assertutil.NotError(t, NewUnitOfWork(pool, Timeouts{}).Do(context.Background(), func(ctx context.Context) error {
+			if err := repository.CreateIssue(ctx, rotation.ID, []string{"#four"}); err != nil {
+				return err
+			}
+			return repository.CreateIssue(ctx, rotation.ID, []string{"#five"})
+		}))
+
+		got, err := repository.FindRecentIssues(context.Background(), rotation.ID, 5)
+		assertutil.NotError(t, err)
+		if len(got) != 5 {
+			t.Errorf("got %d issues want 5", len(got))
+		}
+	})
+
+	t.Run("delete rotation by id", func(t *testing.T) {
+		assertutil.NotError(t, NewRotationRepository(pool, Timeouts{}).DeleteByIds(context.Background(), []string{rotation.ID}))
+
+		got, err := NewRotationRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
+		assertutil.NotError(t, err)
+		want := []Rotation{}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	uuid "github.com/google/uuid"
+)
+
+// ErrRotationNotFound ...
+var ErrRotationNotFound = errors.New("rotation not found")
+
+// RotationService ...
+type RotationService interface {
+	Create(ctx context.Context, bucket string, rotation Rotation) (*Rotation, error)
+	Next(ctx context.Context, id string) (*RotationIssue, error)
+}
+
+type rotationService struct {
+	rotationRepository     RotationRepository
+	namedTagListRepository NamedTagListRepository
+	uuidGenerator          UUIDGenerator
+	randomSource           RandomSource
+}
+
+// Create only takes lists from the rotation's own bucket, so that a rotation
+// cannot draw on lists its caller may not read.
+func (s *rotationService) Create(ctx context.Context, bucket string, rotation Rotation) (*Rotation, error) {
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+	if err != nil {
+		return nil, err
+	}
+	inBucket := map[string]bool{}
+	for _, namedTagList := range namedTagLists {
+		inBucket[namedTagList.ID] = true
+	}
+	for _, id := range rotation.NamedTagListIDs {
+		if !inBucket[id] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNamedTagList, id)
+		}
+	}
+
+	rotation.ID = s.uuidGenerator.Generate()
+	rotation.Bucket = bucket
+	return &rotation, s.rotationRepository.Create(ctx, rotation)
+}
+
+// Next shuffles the pool and then prefers the tags that appeared least in the
+// recent issues, weighting more recent issues more heavily.
+func (s *rotationService) Next(ctx context.Context, id string) (*RotationIssue, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrRotationNotFound
+	}
+
+	rotation, err := s.rotationRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if rotation == nil {
+		return nil, ErrRotationNotFound
+	}
+
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{rotation.Bucket})
+	if err != nil {
+		return nil, err
+	}
+	inPool := map[string]bool{}
+	for _, id := range rotation.NamedTagListIDs {
+		inPool[id] = true
+	}
+	pool := []string{}
+	for _, namedTagList := range namedTagLists {
+		if inPool[namedTagList.ID] {
+			pool = append(pool, namedTagList.Tags...)
+		}
+	}
+	pool = uniqueTags(pool)
+
+	recent := [][]string{}
+	if rotation.History > 0 {
+		if recent, err = s.rotationRepository.FindRecentIssues(ctx, rotation.ID, rotation.History); err != nil {
+			return nil, err
+		}
+	}
+	penalties := map[string]int{}
+	for age, tags := range recent {
+		for _, tag := range uniqueTags(tags) {
+			penalties[tag] += len(recent) - age
+		}
+	}
+
+	for i := len(pool) - 1; i > 0; i-- {
+		j := s.randomSource.Intn(i + 1)
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+	sort.SliceStable(pool, func(a, b int) bool {
+		return penalties[pool[a]] < penalties[pool[b]]
+	})
+	if len(pool) > rotation.Count {
+		pool = pool[:rotation.Count]
+	}
+
+	issue := &RotationIssue{Tags: pool}
+	for _, tags := range recent {
+		if overlap := sharedTagCount(pool, tags); overlap > issue.Overlap {
+			issue.Overlap = overlap
+		}
+	}
+
+	return issue, s.rotationRepository.CreateIssue(ctx, rotation.ID, issue.Tags)
+}
+
+// NewRotationService ...
+func NewRotationService(
+	rotationRepository RotationRepository,
+	namedTagListRepository NamedTagListRepository,
+	uuidGenerator UUIDGenerator,
+	randomSource RandomSource,
+) RotationService {
+	return &rotationService{
+		rotationRepository,
+		namedTagListRepository,
+		uuidGenerator,
+		randomSource,
+	}
+}
+
+func sharedTagCount(a, b []string) int {
+	set := map[string]bool{}
+	for _, tag := range a {
+		set[tag] = true
+	}
+	shared := 0
+	for _, tag := range uniqueTags(b) {
+		if set[tag] {
+			shared++
+		}
+	}
+	return shared
+}
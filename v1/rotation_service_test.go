@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type stubRotationRepositoryForService struct {
+	RotationRepository
+
+	withRotation     Rotation
+	withRecentIssues [][]string
+	willError        string
+
+	created Rotation
+	issued  []string
+	err     error
+}
+
+func (r *stubRotationRepositoryForService) Create(ctx context.Context, rotation Rotation) error {
+	r.created = rotation
+	if r.willError == "Create" {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+func (r *stubRotationRepositoryForService) FindByID(ctx context.Context, id string) (*Rotation, error) {
+	if r.willError == "FindByID" {
+		return nil, errors.New("there was an error")
+	}
+	if id != r.withRotation.ID {
+		return nil, nil
+	}
+	return &r.withRotation, nil
+}
+
+func (r *stubRotationRepositoryForService) FindRecentIssues(ctx context.Context, id string, limit int) ([][]string, error) {
+	if id != r.withRotation.ID || limit != r.withRotation.History {
+		r.err = fmt.Errorf("Stub got id %s want %s got limit %d want %d", id, r.withRotation.ID, limit, r.withRotation.History)
+	}
+	return r.withRecentIssues, nil
+}
+
+func (r *stubRotationRepositoryForService) CreateIssue(ctx context.Context, id string, tags []string) error {
+	r.issued = tags
+	return nil
+}
+
+type stubNamedTagListRepositoryForRotation struct {
+	NamedTagListRepository
+
+	withBucket        string
+	withNamedTagLists []NamedTagList
+
+	err error
+}
+
+func (r *stubNamedTagListRepositoryForRotation) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	if !reflect.DeepEqual(buckets, []string{r.withBucket}) {
+		r.err = fmt.Errorf("Stub got buckets %v want %v", buckets, []string{r.withBucket})
+	}
+	return r.withNamedTagLists, nil
+}
+
+type stubRandomSource struct{}
+
+func (s *stubRandomSource) Intn(n int) int {
+	return 0
+}
+
+func TestRotationService(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		repository := &stubRotationRepositoryForService{}
+		namedTagListRepository := &stubNamedTagListRepositoryForRotation{
+			withBucket:        "bucket",
+			withNamedTagLists: []NamedTagList{{ID: "1", Name: "beach", Tags: []string{"#sand"}}},
+		}
+		service := NewRotationService(
+			repository,
+			namedTagListRepository,
+			&stubUUIDGenerator{response: "3e99aa77-615e-4a55-930d-d4c77cfd1b72"},
+			&stubRandomSource{},
+		)
+
+		got, err := service.Create(context.Background(), "bucket", Rotation{NamedTagListIDs: []string{"1"}, Count: 2, History: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if namedTagListRepository.err != nil {
+			t.Error(namedTagListRepository.err)
+		}
+		want := &Rotation{
+			ID:              "3e99aa77-615e-4a55-930d-d4c77cfd1b72",
+			Bucket:          "bucket",
+			NamedTagListIDs: []string{"1"},
+			Count:           2,
+			History:         3,
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if !reflect.DeepEqual(repository.created, *want) {
+			t.Errorf("got created %+v want %+v", repository.created, *want)
+		}
+	})
+
+	t.Run("create with a list from another bucket", func(t *testing.T) {
+		repository := &stubRotationRepositoryForService{}
+		service := NewRotationService(
+			repository,
+			&stubNamedTagListRepositoryForRotation{
+				withBucket:        "bucket",
+				withNamedTagLists: []NamedTagList{{ID: "1", Name: "beach", Tags: []string{"#sand"}}},
+			},
+			&stubUUIDGenerator{response: "3e99aa77-615e-4a55-930d-d4c77cfd1b72"},
+			&stubRandomSource{},
+		)
+
+		_, gotErr := service.Create(context.Background(), "bucket", Rotation{NamedTagListIDs: []string{"1", "2"}, Count: 2})
+		if !errors.Is(gotErr, ErrUnknownNamedTagList) || repository.created.ID != "" {
+			t.Errorf("got error %v created %+v want %v", gotErr, repository.created, ErrUnknownNamedTagList)
+		}
+	})
+
+	t.Run("next avoids recently issued tags", func(t *testing.T) {
+		repository := &stubRotationRepositoryForService{
+			withRotation: Rotation{
+				ID:              "3e99aa77-615e-4a55-930d-d4c77cfd1b72",
+				Bucket:          "bucket",
+				NamedTagListIDs: []string{"1", "2"},
+				Count:           3,
+				History:         2,
+			},
+			withRecentIssues: [][]string{
+				{"#a", "#b"},
+				{"#b", "#c"},
+			},
+		}
+		namedTagListRepository := &stubNamedTagListRepositoryForRotation{
+			withBucket: "bucket",
+			withNamedTagLists: []NamedTagList{
+				{ID: "1", Tags: []string{"#a", "#b", "#c"}},
+				{ID: "2", Tags: []string{"#c", "#d"}},
+				{ID: "3", Tags: []string{"#e"}},
+			},
+		}
+		service := NewRotationService(
+			repository,
+			namedTagListRepository,
+			&stubUUIDGenerator{},
+			&stubRandomSource{},
+		)
+
+		got, err := service.Next(context.Background(), "3e99aa77-615e-4a55-930d-d4c77cfd1b72")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &RotationIssue{
+			Tags:    []string{"#d", "#c", "#a"},
+			Overlap: 1,
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if !reflect.DeepEqual(repository.issued, want.Tags) {
+			t.Errorf("got issued %+v want %+v", repository.issued, want.Tags)
+		}
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		if namedTagListRepository.err != nil {
+			t.Error(namedTagListRepository.err)
+		}
+	})
+
+	t.Run("next when rotation does not exist", func(t *testing.T) {
+		service := NewRotationService(
+			&stubRotationRepositoryForService{},
+			&stubNamedTagListRepositoryForRotation{},
+			&stubUUIDGenerator{},
+			&stubRandomSource{},
+		)
+
+		for _, id := range []string{"3e99aa77-615e-4a55-930d-d4c77cfd1b72", "not a uuid"} {
+			if _, gotErr := service.Next(context.Background(), id); gotErr != ErrRotationNotFound {
+				t.Errorf("got error %v want %v", gotErr, ErrRotationNotFound)
+			}
+		}
+	})
+
+	t.Run("next when repository has error", func(t *testing.T) {
+		service := NewRotationService(
+			&stubRotationRepositoryForService{willError: "FindByID"},
+			&stubNamedTagListRepositoryForRotation{},
+			&stubUUIDGenerator{},
+			&stubRandomSource{},
+		)
+
+		_, gotErr := service.Next(context.Background(), "3e99aa77-615e-4a55-930d-d4c77cfd1b72")
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
@@ -6,17 +6,59 @@ import (
 
 // Router ...
 type Router struct {
-	namedTagListController NamedTagListController
-	versionController      VersionController
+	namedTagListController  NamedTagListController
+	tagSuggestionController TagSuggestionController
+	housekeepingController  HousekeepingController
+	rotationController      RotationController
+	tagMetadataController   TagMetadataController
+	postController          PostController
+	draftController         DraftController
+	apiKeyController        APIKeyController
+	userController          UserController
+	bucketController        BucketController
+	shareController         ShareController
+	auditController         AuditController
+	batchController         BatchController
+	metricsController       MetricsController
+	healthController        HealthController
+	versionController       VersionController
 }
 
 // NewRouter ...
 func NewRouter(
 	namedTagListController NamedTagListController,
+	tagSuggestionController TagSuggestionController,
+	housekeepingController HousekeepingController,
+	rotationController RotationController,
+	tagMetadataController TagMetadataController,
+	postController PostController,
+	draftController DraftController,
+	apiKeyController APIKeyController,
+	userController UserController,
+	bucketController BucketController,
+	shareController ShareController,
+	auditController AuditController,
+	batchController BatchController,
+	metricsController MetricsController,
+	healthController HealthController,
 	versionController VersionController,
 ) *Router {
 	return &Router{
 		namedTagListController,
+		tagSuggestionController,
+		housekeepingController,
+		rotationController,
+		tagMetadataController,
+		postController,
+		draftController,
+		apiKeyController,
+		userController,
+		bucketController,
+		shareController,
+		auditController,
+		batchController,
+		metricsController,
+		healthController,
 		versionController,
 	}
 }
@@ -26,13 +68,60 @@ func (router *Router) ServeHTTP(w http.ResponseWriter, request *http.Request) {
 	switch request.Method {
 	case http.MethodGet:
 		serveMux.Handle("/namedTagLists", router.namedTagListController.GetNamedTagLists())
+		serveMux.Handle("/tags/", router.tagSuggestionController.GetRelatedTags())
+		serveMux.Handle("/housekeeping", router.housekeepingController.GetReport())
+		serveMux.Handle("/rotations", router.rotationController.GetRotations())
+		serveMux.Handle("/rotations/", router.rotationController.GetNext())
+		serveMux.Handle("/tagMetadata", router.tagMetadataController.GetTagMetadata())
+		serveMux.Handle("/posts", router.postController.GetPosts())
+		serveMux.Handle("/analytics/namedTagLists", router.postController.GetNamedTagListRanking())
+		serveMux.Handle("/analytics/tags", router.postController.GetTagRanking())
+		serveMux.Handle("/drafts", router.draftController.GetDrafts())
+		serveMux.Handle("/drafts/", router.draftController.RenderDraft())
+		serveMux.Handle("/apiKeys", router.apiKeyController.GetAPIKeys())
+		serveMux.Handle("/buckets", router.bucketController.GetBuckets())
+		serveMux.Handle("/buckets/", router.bucketController.GetMembers())
+		serveMux.Handle("/shares", router.shareController.GetShares())
+		serveMux.Handle("/shared/", router.shareController.GetShared())
+		serveMux.Handle("/audit", router.auditController.GetAuditEvents())
+		serveMux.Handle("/metrics", router.metricsController.GetMetrics())
+		serveMux.Handle("/healthz", router.healthController.GetLiveness())
+		serveMux.Handle("/readyz", router.healthController.GetReadiness())
+		serveMux.Handle("/startupz", router.healthController.GetStartup())
 		serveMux.Handle("/version", router.versionController.HandlerFunc())
+		serveMux.Handle("/debug/buildinfo", router.versionController.GetBuildInfo())
 	case http.MethodPost:
 		serveMux.Handle("/namedTagLists", router.namedTagListController.CreateNamedTagList())
+		serveMux.Handle("/suggest", router.tagSuggestionController.Suggest())
+		serveMux.Handle("/suggest/fromCaption", router.tagSuggestionController.SuggestFromCaption())
+		serveMux.Handle("/housekeeping/merges", router.housekeepingController.ApplyMerges())
+		serveMux.Handle("/rotations", router.rotationController.CreateRotation())
+		serveMux.Handle("/posts", router.postController.CreatePost())
+		serveMux.Handle("/posts/import", router.postController.ImportPosts())
+		serveMux.Handle("/drafts", router.draftController.CreateDraft())
+		serveMux.Handle("/apiKeys", router.apiKeyController.CreateAPIKey())
+		serveMux.Handle("/users", router.userController.Register())
+		serveMux.Handle("/sessions", router.userController.Login())
+		serveMux.Handle("/buckets", router.bucketController.CreateBucket())
+		serveMux.Handle("/buckets/", router.bucketController.InviteMember())
+		serveMux.Handle("/shares", router.shareController.CreateShare())
+		serveMux.Handle("/batch", router.batchController.Batch())
 	case http.MethodPut:
 		serveMux.Handle("/namedTagLists", router.namedTagListController.ReplaceNamedTagLists())
+		serveMux.Handle("/namedTagLists/", router.namedTagListController.UpsertNamedTagList())
+		serveMux.Handle("/buckets/", router.namedTagListController.SyncNamedTagLists())
+		serveMux.Handle("/tagMetadata", router.tagMetadataController.ReplaceTagMetadata())
+		serveMux.Handle("/drafts", router.draftController.ReplaceDraft())
 	case http.MethodDelete:
 		serveMux.Handle("/namedTagLists", router.namedTagListController.DeleteNamedTagLists())
+		serveMux.Handle("/rotations", router.rotationController.DeleteRotations())
+		serveMux.Handle("/tagMetadata", router.tagMetadataController.DeleteTagMetadata())
+		serveMux.Handle("/posts", router.postController.DeletePosts())
+		serveMux.Handle("/drafts", router.draftController.DeleteDrafts())
+		serveMux.Handle("/apiKeys", router.apiKeyController.RevokeAPIKeys())
+		serveMux.Handle("/sessions", router.userController.Logout())
+		serveMux.Handle("/buckets/", router.bucketController.RevokeMember())
+		serveMux.Handle("/shares", router.shareController.RevokeShares())
 	}
 	serveMux.ServeHTTP(w, request)
 }
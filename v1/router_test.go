@@ -25,6 +25,22 @@ func (c *stubNamedTagListController) CreateNamedTagList() http.Handler {
 	)
 }
 
+func (c *stubNamedTagListController) UpsertNamedTagList() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the named tag list controller body / upsert method"))
+		},
+	)
+}
+
+func (c *stubNamedTagListController) SyncNamedTagLists() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the named tag list controller body / sync method"))
+		},
+	)
+}
+
 func (c *stubNamedTagListController) ReplaceNamedTagLists() http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -33,54 +49,1228 @@ func (c *stubNamedTagListController) ReplaceNamedTagLists() http.Handler {
 	)
 }
 
-func (c *stubNamedTagListController) DeleteNamedTagLists() http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte("the named tag list controller body / delete method"))
-		},
-	)
-}
+func (c *stubNamedTagListController) DeleteNamedTagLists() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the named tag list controller body / delete method"))
+		},
+	)
+}
+
+type stubTagSuggestionController struct {
+}
+
+func (c *stubTagSuggestionController) GetRelatedTags() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag suggestion controller body / get method"))
+		},
+	)
+}
+
+func (c *stubTagSuggestionController) Suggest() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag suggestion controller body / post method"))
+		},
+	)
+}
+
+func (c *stubTagSuggestionController) SuggestFromCaption() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag suggestion controller body / post caption method"))
+		},
+	)
+}
+
+type stubHousekeepingController struct {
+}
+
+func (c *stubHousekeepingController) GetReport() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the housekeeping controller body / get method"))
+		},
+	)
+}
+
+func (c *stubHousekeepingController) ApplyMerges() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the housekeeping controller body / post method"))
+		},
+	)
+}
+
+type stubRotationController struct {
+}
+
+func (c *stubRotationController) GetRotations() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the rotation controller body / get method"))
+		},
+	)
+}
+
+func (c *stubRotationController) CreateRotation() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the rotation controller body / post method"))
+		},
+	)
+}
+
+func (c *stubRotationController) DeleteRotations() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the rotation controller body / delete method"))
+		},
+	)
+}
+
+func (c *stubRotationController) GetNext() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the rotation controller body / get next method"))
+		},
+	)
+}
+
+type stubTagMetadataController struct {
+}
+
+func (c *stubTagMetadataController) GetTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag metadata controller body / get method"))
+		},
+	)
+}
+
+func (c *stubTagMetadataController) ReplaceTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag metadata controller body / put method"))
+		},
+	)
+}
+
+func (c *stubTagMetadataController) DeleteTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the tag metadata controller body / delete method"))
+		},
+	)
+}
+
+type stubPostController struct {
+}
+
+func (c *stubPostController) GetPosts() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / get method"))
+		},
+	)
+}
+
+func (c *stubPostController) CreatePost() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / post method"))
+		},
+	)
+}
+
+func (c *stubPostController) ImportPosts() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / post import method"))
+		},
+	)
+}
+
+func (c *stubPostController) DeletePosts() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / delete method"))
+		},
+	)
+}
+
+func (c *stubPostController) GetNamedTagListRanking() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / get named tag list ranking method"))
+		},
+	)
+}
+
+func (c *stubPostController) GetTagRanking() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the post controller body / get tag ranking method"))
+		},
+	)
+}
+
+type stubDraftController struct {
+}
+
+func (c *stubDraftController) GetDrafts() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the draft controller body / get method"))
+		},
+	)
+}
+
+func (c *stubDraftController) CreateDraft() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the draft controller body / post method"))
+		},
+	)
+}
+
+func (c *stubDraftController) ReplaceDraft() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the draft controller body / put method"))
+		},
+	)
+}
+
+func (c *stubDraftController) DeleteDrafts() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the draft controller body / delete method"))
+		},
+	)
+}
+
+func (c *stubDraftController) RenderDraft() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the draft controller body / get render method"))
+		},
+	)
+}
+
+type stubAPIKeyController struct {
+}
+
+func (c *stubAPIKeyController) GetAPIKeys() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the api key controller body / get method"))
+		},
+	)
+}
+
+func (c *stubAPIKeyController) CreateAPIKey() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the api key controller body / post method"))
+		},
+	)
+}
+
+func (c *stubAPIKeyController) RevokeAPIKeys() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the api key controller body / delete method"))
+		},
+	)
+}
+
+type stubUserController struct {
+}
+
+func (c *stubUserController) Register() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the user controller body / post method"))
+		},
+	)
+}
+
+func (c *stubUserController) Login() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the user controller body / post login method"))
+		},
+	)
+}
+
+func (c *stubUserController) Logout() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the user controller body / delete method"))
+		},
+	)
+}
+
+type stubBucketController struct {
+}
+
+func (c *stubBucketController) GetBuckets() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the bucket controller body / get method"))
+		},
+	)
+}
+
+func (c *stubBucketController) CreateBucket() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the bucket controller body / post method"))
+		},
+	)
+}
+
+func (c *stubBucketController) GetMembers() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the bucket controller body / get members method"))
+		},
+	)
+}
+
+func (c *stubBucketController) InviteMember() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the bucket controller body / post members method"))
+		},
+	)
+}
+
+func (c *stubBucketController) RevokeMember() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the bucket controller body / delete members method"))
+		},
+	)
+}
+
+type stubShareController struct {
+}
+
+func (c *stubShareController) GetShares() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the share controller body / get method"))
+		},
+	)
+}
+
+func (c *stubShareController) CreateShare() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the share controller body / post method"))
+		},
+	)
+}
+
+func (c *stubShareController) RevokeShares() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the share controller body / delete method"))
+		},
+	)
+}
+
+func (c *stubShareController) GetShared() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the share controller body / get shared method"))
+		},
+	)
+}
+
+type stubAuditController struct {
+}
+
+func (c *stubAuditController) GetAuditEvents() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the audit controller body"))
+		},
+	)
+}
+
+type stubBatchController struct {
+}
+
+func (c *stubBatchController) Batch() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the batch controller body"))
+		},
+	)
+}
+
+type stubMetricsController struct {
+}
+
+func (c *stubMetricsController) GetMetrics() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the metrics controller body"))
+		},
+	)
+}
+
+type stubHealthController struct {
+}
+
+func (c *stubHealthController) GetLiveness() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the liveness body"))
+		},
+	)
+}
+
+func (c *stubHealthController) GetReadiness() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the readiness body"))
+		},
+	)
+}
+
+func (c *stubHealthController) GetStartup() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the startup body"))
+		},
+	)
+}
+
+func (c *stubHealthController) Drain() {}
+
+type stubVersionController struct {
+}
+
+func (c *stubVersionController) HandlerFunc() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the version controller body"))
+		},
+	)
+}
+
+func (c *stubVersionController) GetBuildInfo() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("the build info body"))
+		},
+	)
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter(
+		&stubNamedTagListController{},
+		&stubTagSuggestionController{},
+		&stubHousekeepingController{},
+		&stubRotationController{},
+		&stubTagMetadataController{},
+		&stubPostController{},
+		&stubDraftController{},
+		&stubAPIKeyController{},
+		&stubUserController{},
+		&stubBucketController{},
+		&stubShareController{},
+		&stubAuditController{},
+		&stubBatchController{},
+		&stubMetricsController{},
+		&stubHealthController{},
+		&stubVersionController{},
+	)
+
+	t.Run("Route not found", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 404
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "404 page not found\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /namedTagLists to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /namedTagLists to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route PUT /namedTagLists to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPut, "/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / put method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /namedTagLists to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /tags/{tag}/related to tag suggestion controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/tags/%23windy/related", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag suggestion controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /suggest to tag suggestion controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/suggest", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag suggestion controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /suggest/fromCaption to tag suggestion controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/suggest/fromCaption", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag suggestion controller body / post caption method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /housekeeping to housekeeping controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/housekeeping", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the housekeeping controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /housekeeping/merges to housekeeping controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/housekeeping/merges", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the housekeeping controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /rotations to rotation controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/rotations", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the rotation controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /rotations to rotation controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/rotations", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the rotation controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /rotations to rotation controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/rotations", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the rotation controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /rotations/{id}/next to rotation controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/rotations/f00/next", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the rotation controller body / get next method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /tagMetadata to tag metadata controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/tagMetadata", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag metadata controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route PUT /tagMetadata to tag metadata controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPut, "/tagMetadata", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag metadata controller body / put method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /tagMetadata to tag metadata controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/tagMetadata", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the tag metadata controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /posts to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/posts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /posts to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/posts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /posts/import to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/posts/import", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / post import method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /posts to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/posts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /analytics/namedTagLists to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/analytics/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / get named tag list ranking method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /analytics/tags to post controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/analytics/tags", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the post controller body / get tag ranking method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /drafts to draft controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/drafts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the draft controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /drafts to draft controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/drafts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the draft controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route PUT /drafts to draft controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPut, "/drafts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the draft controller body / put method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /drafts to draft controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/drafts", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the draft controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /drafts/{id}/render to draft controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/drafts/f00/render", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the draft controller body / get render method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /apiKeys to api key controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/apiKeys", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the api key controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /apiKeys to api key controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/apiKeys", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the api key controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /apiKeys to api key controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/apiKeys", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the api key controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /users to user controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/users", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the user controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /sessions to user controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/sessions", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the user controller body / post login method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /sessions to user controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/sessions", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the user controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /buckets to bucket controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/buckets", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the bucket controller body / get method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
 
-type stubVersionController struct {
-}
+	t.Run("Route POST /buckets to bucket controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/buckets", nil)
+		response := httptest.NewRecorder()
 
-func (c *stubVersionController) HandlerFunc() http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte("the version controller body"))
-		},
-	)
-}
+		router.ServeHTTP(response, request)
 
-func TestRouter(t *testing.T) {
-	router := NewRouter(
-		&stubNamedTagListController{},
-		&stubVersionController{},
-	)
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
 
-	t.Run("Route not found", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the bucket controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /buckets/{bucket}/members to bucket controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/buckets/blue/members", nil)
 		response := httptest.NewRecorder()
 
 		router.ServeHTTP(response, request)
 
 		gotStatusCode := response.Result().StatusCode
-		wantStatusCode := 404
+		wantStatusCode := 200
 
 		if gotStatusCode != wantStatusCode {
 			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
 		}
 
 		gotBody := string(response.Body.Bytes())
-		wantBody := "404 page not found\n"
+		wantBody := "the bucket controller body / get members method"
 
 		if gotBody != wantBody {
 			t.Errorf("got body %s want %s", gotBody, wantBody)
 		}
 	})
 
-	t.Run("Route GET /namedTagLists to named tag list controller", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists", nil)
+	t.Run("Route POST /buckets/{bucket}/members to bucket controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/buckets/blue/members", nil)
 		response := httptest.NewRecorder()
 
 		router.ServeHTTP(response, request)
@@ -93,15 +1283,15 @@ func TestRouter(t *testing.T) {
 		}
 
 		gotBody := string(response.Body.Bytes())
-		wantBody := "the named tag list controller body / get method"
+		wantBody := "the bucket controller body / post members method"
 
 		if gotBody != wantBody {
 			t.Errorf("got body %s want %s", gotBody, wantBody)
 		}
 	})
 
-	t.Run("Route POST /namedTagLists to named tag list controller", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPost, "/namedTagLists", nil)
+	t.Run("Route DELETE /buckets/{bucket}/members to bucket controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/buckets/blue/members", nil)
 		response := httptest.NewRecorder()
 
 		router.ServeHTTP(response, request)
@@ -114,15 +1304,15 @@ func TestRouter(t *testing.T) {
 		}
 
 		gotBody := string(response.Body.Bytes())
-		wantBody := "the named tag list controller body / post method"
+		wantBody := "the bucket controller body / delete members method"
 
 		if gotBody != wantBody {
 			t.Errorf("got body %s want %s", gotBody, wantBody)
 		}
 	})
 
-	t.Run("Route PUT /namedTagLists to named tag list controller", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPut, "/namedTagLists", nil)
+	t.Run("Route GET /shares to share controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/shares", nil)
 		response := httptest.NewRecorder()
 
 		router.ServeHTTP(response, request)
@@ -135,15 +1325,15 @@ func TestRouter(t *testing.T) {
 		}
 
 		gotBody := string(response.Body.Bytes())
-		wantBody := "the named tag list controller body / put method"
+		wantBody := "the share controller body / get method"
 
 		if gotBody != wantBody {
 			t.Errorf("got body %s want %s", gotBody, wantBody)
 		}
 	})
 
-	t.Run("Route DELETE /namedTagLists to named tag list controller", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/namedTagLists", nil)
+	t.Run("Route POST /shares to share controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/shares", nil)
 		response := httptest.NewRecorder()
 
 		router.ServeHTTP(response, request)
@@ -156,7 +1346,238 @@ func TestRouter(t *testing.T) {
 		}
 
 		gotBody := string(response.Body.Bytes())
-		wantBody := "the named tag list controller body / delete method"
+		wantBody := "the share controller body / post method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route DELETE /shares to share controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/shares", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the share controller body / delete method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /shared/{token} to share controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/shared/abc.def", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the share controller body / get shared method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /audit to audit controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit?bucket=blue", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the audit controller body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /metrics to metrics controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the metrics controller body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /healthz to health controller liveness", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the liveness body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /readyz to health controller readiness", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the readiness body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /startupz to health controller startup", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/startupz", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the startup body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route GET /debug/buildinfo to version controller build info", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/debug/buildinfo", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the build info body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route POST /batch to batch controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/batch", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the batch controller body"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route PUT /namedTagLists/ to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPut, "/namedTagLists/3e99aa77-615e-4a55-930d-d4c77cfd1b72?bucket=blue", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / upsert method"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %s want %s", gotBody, wantBody)
+		}
+	})
+
+	t.Run("Route PUT /buckets/ to named tag list controller", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPut, "/buckets/blue/namedTagLists", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := string(response.Body.Bytes())
+		wantBody := "the named tag list controller body / sync method"
 
 		if gotBody != wantBody {
 			t.Errorf("got body %s want %s", gotBody, wantBody)
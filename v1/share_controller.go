@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ShareController ...
+type ShareController interface {
+	GetShares() http.Handler
+	CreateShare() http.Handler
+	RevokeShares() http.Handler
+	GetShared() http.Handler
+}
+
+type shareController struct {
+	logger          Logger
+	shareRepository ShareRepository
+	shareService    ShareService
+}
+
+var sharedViewTemplate = template.Must(template.New("shared").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Bucket}}</title>
+<style>body{font-family:sans-serif;max-width:40em;margin:2em auto;padding:0 1em}p{line-height:1.6}</style>
+</head>
+<body>
+<h1>{{.Bucket}}</h1>
+{{range .NamedTagLists}}<h2>{{.Name}}</h2>
+<p>{{range .Tags}}{{.}} {{end}}</p>
+{{else}}<p>Nothing has been shared yet.</p>
+{{end}}<footer><small>This link expires {{.ExpiresAt.Format "2 January 2006"}}.</small></footer>
+</body>
+</html>
+`))
+
+func (c *shareController) GetShares() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			buckets := r.URL.Query()["bucket"]
+			if len(buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			}
+
+			shares, err := c.shareRepository.FindAll(r.Context(), buckets)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			json.NewEncoder(rw).Encode(shares)
+		},
+	)
+}
+
+func (c *shareController) CreateShare() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			var body struct {
+				NamedTagListID *string `json:"namedTagListId"`
+				ExpiresIn      int64   `json:"expiresIn"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if share, err := c.shareService.Create(r.Context(), bucket, body.NamedTagListID, time.Duration(body.ExpiresIn)*time.Second); errors.Is(err, ErrInvalidShare) {
+				writeBadRequest(rw, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(share)
+			}
+		},
+	)
+}
+
+func (c *shareController) RevokeShares() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ids := r.URL.Query()["id"]
+			if len(ids) < 1 {
+				writeBadRequest(rw, "id query parameter is required")
+				return
+			}
+
+			if err := c.shareRepository.RevokeByIds(r.Context(), ids); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+// GetShared responds with JSON unless format=text or format=html is asked
+// for, in which case the lists are rendered as captions or as a web page.
+func (c *shareController) GetShared() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.URL.Path, "/shared/")
+			if token == "" || strings.Contains(token, "/") {
+				http.NotFound(rw, r)
+				return
+			}
+
+			view, err := c.shareService.Open(r.Context(), token)
+			if errors.Is(err, ErrShareNotFound) {
+				writeError(rw, http.StatusNotFound, err.Error())
+				return
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+
+			rw.Header().Set("Cache-Control", "no-store")
+			switch r.URL.Query().Get("format") {
+			case "text":
+				rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				rw.Write([]byte(view.Text()))
+			case "html":
+				rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+				if err = sharedViewTemplate.Execute(rw, view); err != nil {
+					c.logger.Error(err)
+				}
+			default:
+				json.NewEncoder(rw).Encode(view)
+			}
+		},
+	)
+}
+
+// NewShareController ...
+func NewShareController(
+	logger Logger,
+	shareRepository ShareRepository,
+	shareService ShareService,
+) ShareController {
+	return &shareController{
+		logger,
+		shareRepository,
+		shareService,
+	}
+}
@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubShareRepositoryForController struct {
+	ShareRepository
+
+	withIds []string
+
+	err error
+}
+
+func (r *stubShareRepositoryForController) FindAll(ctx context.Context, buckets []string) ([]Share, error) {
+	return []Share{{ID: "1", Bucket: buckets[0], Views: 3}}, nil
+}
+
+func (r *stubShareRepositoryForController) RevokeByIds(ctx context.Context, ids []string) error {
+	if !reflect.DeepEqual(ids, r.withIds) {
+		r.err = fmt.Errorf("Stub got ids %v want %v", ids, r.withIds)
+	}
+	return nil
+}
+
+type stubShareService struct {
+	withNamedTagListID *string
+	withLifetime       time.Duration
+	willError          error
+
+	err error
+}
+
+func (s *stubShareService) Create(ctx context.Context, bucket string, namedTagListID *string, lifetime time.Duration) (*CreatedShare, error) {
+	if !reflect.DeepEqual(namedTagListID, s.withNamedTagListID) || lifetime != s.withLifetime {
+		s.err = fmt.Errorf("Stub got named tag list id %v want %v got lifetime %s want %s", namedTagListID, s.withNamedTagListID, lifetime, s.withLifetime)
+	}
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &CreatedShare{Share{ID: "1", Bucket: bucket}, "token", "/shared/token"}, nil
+}
+
+func (s *stubShareService) Open(ctx context.Context, token string) (*SharedView, error) {
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	if token != "token" {
+		return nil, ErrShareNotFound
+	}
+	return &SharedView{
+		Bucket:        "<blue>",
+		NamedTagLists: []NamedTagList{{ID: "1", Name: "beach", Tags: []string{"#beach", "#windy"}}},
+		ExpiresAt:     time.Date(2020, 11, 8, 12, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func TestShareController(t *testing.T) {
+	t.Run("GET /shares", func(t *testing.T) {
+		controller := NewShareController(stubLoggerNew(), &stubShareRepositoryForController{}, &stubShareService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/shares?bucket=blue", nil)
+		response := httptest.NewRecorder()
+		controller.GetShares().ServeHTTP(response, request)
+
+		gotBody := response.Body.String()
+		wantBody := "[{\"id\":\"1\",\"bucket\":\"blue\",\"namedTagListId\":null,\"createdAt\":\"0001-01-01T00:00:00Z\",\"expiresAt\":\"0001-01-01T00:00:00Z\",\"revokedAt\":null,\"views\":3}]\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("POST /shares", func(t *testing.T) {
+		id := "1"
+		service := &stubShareService{withNamedTagListID: &id, withLifetime: time.Hour}
+		controller := NewShareController(stubLoggerNew(), &stubShareRepositoryForController{}, service)
+
+		request, _ := http.NewRequest(http.MethodPost, "/shares?bucket=blue", strings.NewReader("{\"namedTagListId\":\"1\",\"expiresIn\":3600}"))
+		response := httptest.NewRecorder()
+		controller.CreateShare().ServeHTTP(response, request)
+
+		if service.err != nil {
+			t.Error(service.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /shares invalid", func(t *testing.T) {
+		controller := NewShareController(stubLoggerNew(), &stubShareRepositoryForController{}, &stubShareService{willError: fmt.Errorf("%w: expiresIn must be between 1 second and 365 days", ErrInvalidShare)})
+
+		request, _ := http.NewRequest(http.MethodPost, "/shares?bucket=blue", strings.NewReader("{}"))
+		response := httptest.NewRecorder()
+		controller.CreateShare().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE /shares", func(t *testing.T) {
+		repository := &stubShareRepositoryForController{withIds: []string{"1"}}
+		controller := NewShareController(stubLoggerNew(), repository, &stubShareService{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/shares?id=1", nil)
+		response := httptest.NewRecorder()
+		controller.RevokeShares().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	for _, scenario := range []struct {
+		url             string
+		wantStatusCode  int
+		wantContentType string
+		wantBody        string
+	}{
+		{"/shared/token", 200, "", "{\"bucket\":\"\\u003cblue\\u003e\",\"namedTagLists\":[{\"id\":\"1\",\"name\":\"beach\",\"tags\":[\"#beach\",\"#windy\"]}],\"expiresAt\":\"2020-11-08T12:00:00Z\"}\n"},
+		{"/shared/token?format=text", 200, "text/plain; charset=utf-8", "beach\n\n#beach #windy"},
+		{"/shared/other", 404, "", "{\"error\":\"share not found\"}\n"},
+	} {
+		t.Run("GET "+scenario.url, func(t *testing.T) {
+			controller := NewShareController(stubLoggerNew(), &stubShareRepositoryForController{}, &stubShareService{})
+
+			request, _ := http.NewRequest(http.MethodGet, scenario.url, nil)
+			response := httptest.NewRecorder()
+			controller.GetShared().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+
+			if gotStatusCode != scenario.wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, scenario.wantStatusCode)
+			}
+
+			if scenario.wantContentType != "" && response.Header().Get("Content-Type") != scenario.wantContentType {
+				t.Errorf("got content type %s want %s", response.Header().Get("Content-Type"), scenario.wantContentType)
+			}
+
+			gotBody := response.Body.String()
+
+			if gotBody != scenario.wantBody {
+				t.Errorf("got body %q want %q", gotBody, scenario.wantBody)
+			}
+		})
+	}
+
+	t.Run("GET /shared/{token} as html", func(t *testing.T) {
+		controller := NewShareController(stubLoggerNew(), &stubShareRepositoryForController{}, &stubShareService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/shared/token?format=html", nil)
+		response := httptest.NewRecorder()
+		controller.GetShared().ServeHTTP(response, request)
+
+		gotBody := response.Body.String()
+
+		for _, want := range []string{"<h1>&lt;blue&gt;</h1>", "<h2>beach</h2>", "#beach #windy", "expires 8 November 2020"} {
+			if !strings.Contains(gotBody, want) {
+				t.Errorf("got body %s want it to contain %s", gotBody, want)
+			}
+		}
+	})
+
+	t.Run("GET /shared/{token} when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewShareController(logger, &stubShareRepositoryForController{}, &stubShareService{willError: errors.New("there was an error")})
+
+		request, _ := http.NewRequest(http.MethodGet, "/shared/token", nil)
+		response := httptest.NewRecorder()
+		controller.GetShared().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ShareRepository ...
+type ShareRepository interface {
+	FindAll(ctx context.Context, buckets []string) ([]Share, error)
+	FindByID(ctx context.Context, id string) (*Share, error)
+	Create(ctx context.Context, share Share) error
+	RevokeByIds(ctx context.Context, ids []string) error
+	IncrementViews(ctx context.Context, id string) error
+}
+
+type shareRepository struct {
+	db database
+}
+
+const shareColumns = "\"id\", \"bucket\", \"named_tag_list_id\", \"created_at\", \"expires_at\", \"revoked_at\", \"views\""
+
+func scanShare(row pgx.Row, share *Share) error {
+	return row.Scan(
+		&share.ID,
+		&share.Bucket,
+		&share.NamedTagListID,
+		&share.CreatedAt,
+		&share.ExpiresAt,
+		&share.RevokedAt,
+		&share.Views,
+	)
+}
+
+func (r *shareRepository) FindAll(ctx context.Context, buckets []string) ([]Share, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindAll", "select "+shareColumns+" from shares where \"bucket\" = ANY($1) order by \"created_at\", \"id\"", buckets); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []Share{}
+
+	for rows.Next() {
+		var share Share
+		if err = scanShare(rows, &share); err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
+
+func (r *shareRepository) FindByID(ctx context.Context, id string) (*Share, error) {
+	var share Share
+	err := scanShare(
+		r.db.queryRow(ctx, "FindByID", "select "+shareColumns+" from shares where \"id\" = $1", id),
+		&share,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *shareRepository) Create(ctx context.Context, share Share) error {
+	return r.db.exec(
+		ctx,
+		"Create",
+		"insert into shares (\"id\", \"bucket\", \"named_tag_list_id\", \"created_at\", \"expires_at\") values ($1, $2, $3, $4, $5)",
+		share.ID,
+		share.Bucket,
+		share.NamedTagListID,
+		share.CreatedAt,
+		share.ExpiresAt,
+	)
+}
+
+func (r *shareRepository) RevokeByIds(ctx context.Context, ids []string) error {
+	return r.db.exec(
+		ctx,
+		"RevokeByIds",
+		"update shares set \"revoked_at\" = now() where \"id\" = ANY($1) and \"revoked_at\" is null",
+		ids,
+	)
+}
+
+func (r *shareRepository) IncrementViews(ctx context.Context, id string) error {
+	return r.db.exec(
+		ctx,
+		"IncrementViews",
+		"update shares set \"views\" = \"views\" + 1 where \"id\" = $1",
+		id,
+	)
+}
+
+// NewShareRepository ...
+func NewShareRepository(pool *pgxpool.Pool, timeouts Timeouts) ShareRepository {
+	return &shareRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestShareRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	namedTagListID := "39abb8d4-3ac2-4f6f-ae5c-40e4382893d4"
+	share := Share{
+		ID:             "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		Bucket:         "blue",
+		NamedTagListID: &namedTagListID,
+		CreatedAt:      time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC),
+		ExpiresAt:      time.Date(2020, 11, 8, 12, 0, 0, 0, time.UTC),
+	}
+	utc := func(share *Share) *Share {
+		share.CreatedAt = share.CreatedAt.UTC()
+		share.ExpiresAt = share.ExpiresAt.UTC()
+		return share
+	}
+
+	t.Run("create share", func(t *testing.T) {
+		assertutil.NotError(t, NewShareRepository(pool, Timeouts{}).Create(context.Background(), share))
+
+		got, err := NewShareRepository(pool, Timeouts{}).FindAll(context.Background(), []string{"blue"})
+		assertutil.NotError(t, err)
+		want := []Share{share}
+
+		if len(got) != 1 || !reflect.DeepEqual(*utc(&got[0]), share) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("increment views", func(t *testing.T) {
+		assertutil.NotError(t, NewShareRepository(pool, Timeouts{}).IncrementViews(context.Background(), share.ID))
+		assertutil.NotError(t, NewShareRepository(pool, Timeouts{}).IncrementViews(context.Background(), share.ID))
+
+		got, err := NewShareRepository(pool, Timeouts{}).FindByID(context.Background(), share.ID)
+		assertutil.NotError(t, err)
+
+		if got.Views != 2 {
+			t.Errorf("got views %d want 2", got.Views)
+		}
+	})
+
+	t.Run("revoke share by id", func(t *testing.T) {
+		assertutil.NotError(t, NewShareRepository(pool, Timeouts{}).RevokeByIds(context.Background(), []string{share.ID}))
+
+		got, err := NewShareRepository(pool, Timeouts{}).FindByID(context.Background(), share.ID)
+		assertutil.NotError(t, err)
+
+		if got.RevokedAt == nil {
+			t.Errorf("got %+v want revoked", got)
+		}
+	})
+}
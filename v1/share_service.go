@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidShare ...
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrShareNotFound covers forged, expired and revoked tokens alike so that
+	// a token holder learns nothing about why it stopped working.
+	ErrShareNotFound = errors.New("share not found")
+)
+
+const (
+	defaultShareLifetime = 7 * 24 * time.Hour
+	maxShareLifetime     = 365 * 24 * time.Hour
+)
+
+// ShareService ...
+type ShareService interface {
+	Create(ctx context.Context, bucket string, namedTagListID *string, lifetime time.Duration) (*CreatedShare, error)
+	Open(ctx context.Context, token string) (*SharedView, error)
+}
+
+type shareService struct {
+	shareRepository        ShareRepository
+	namedTagListRepository NamedTagListRepository
+	uuidGenerator          UUIDGenerator
+	secret                 []byte
+}
+
+// Create shares the whole bucket when namedTagListID is nil. A zero lifetime
+// means the default of a week.
+func (s *shareService) Create(ctx context.Context, bucket string, namedTagListID *string, lifetime time.Duration) (*CreatedShare, error) {
+	if lifetime == 0 {
+		lifetime = defaultShareLifetime
+	} else if lifetime < 0 || lifetime > maxShareLifetime {
+		return nil, fmt.Errorf("%w: expiresIn must be between 1 second and 365 days", ErrInvalidShare)
+	}
+
+	if namedTagListID != nil {
+		namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{bucket})
+		if err != nil {
+			return nil, err
+		} else if _, ok := findNamedTagList(namedTagLists, *namedTagListID); !ok {
+			return nil, fmt.Errorf("%w: named tag list %s is not in bucket %s", ErrInvalidShare, *namedTagListID, bucket)
+		}
+	}
+
+	now := time.Now().UTC()
+	share := Share{
+		ID:             s.uuidGenerator.Generate(),
+		Bucket:         bucket,
+		NamedTagListID: namedTagListID,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(lifetime).Truncate(time.Second),
+	}
+	if err := s.shareRepository.Create(ctx, share); err != nil {
+		return nil, err
+	}
+
+	token := signShareToken(s.secret, share.ID, share.ExpiresAt)
+	return &CreatedShare{share, token, "/shared/" + token}, nil
+}
+
+func (s *shareService) Open(ctx context.Context, token string) (*SharedView, error) {
+	id, err := parseShareToken(s.secret, token, time.Now())
+	if err != nil {
+		return nil, ErrShareNotFound
+	}
+
+	share, err := s.shareRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if share == nil || share.RevokedAt != nil {
+		return nil, ErrShareNotFound
+	}
+
+	namedTagLists, err := s.namedTagListRepository.FindAll(ctx, []string{share.Bucket})
+	if err != nil {
+		return nil, err
+	}
+	if share.NamedTagListID != nil {
+		namedTagList, ok := findNamedTagList(namedTagLists, *share.NamedTagListID)
+		if !ok {
+			return nil, ErrShareNotFound
+		}
+		namedTagLists = []NamedTagList{namedTagList}
+	}
+
+	if err = s.shareRepository.IncrementViews(ctx, share.ID); err != nil {
+		return nil, err
+	}
+	return &SharedView{share.Bucket, namedTagLists, share.ExpiresAt}, nil
+}
+
+func findNamedTagList(namedTagLists []NamedTagList, id string) (NamedTagList, bool) {
+	for _, namedTagList := range namedTagLists {
+		if namedTagList.ID == id {
+			return namedTagList, true
+		}
+	}
+	return NamedTagList{}, false
+}
+
+// NewShareService ...
+func NewShareService(
+	shareRepository ShareRepository,
+	namedTagListRepository NamedTagListRepository,
+	uuidGenerator UUIDGenerator,
+	secret []byte,
+) ShareService {
+	return &shareService{
+		shareRepository,
+		namedTagListRepository,
+		uuidGenerator,
+		secret,
+	}
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubShareRepositoryForService struct {
+	ShareRepository
+
+	shares map[string]Share
+}
+
+func (r *stubShareRepositoryForService) FindByID(ctx context.Context, id string) (*Share, error) {
+	if share, ok := r.shares[id]; ok {
+		return &share, nil
+	}
+	return nil, nil
+}
+
+func (r *stubShareRepositoryForService) Create(ctx context.Context, share Share) error {
+	r.shares[share.ID] = share
+	return nil
+}
+
+func (r *stubShareRepositoryForService) IncrementViews(ctx context.Context, id string) error {
+	share := r.shares[id]
+	share.Views++
+	r.shares[id] = share
+	return nil
+}
+
+func TestShareService(t *testing.T) {
+	secret := []byte("secret")
+	beach := NamedTagList{ID: "1", Name: "beach", Tags: []string{"#beach", "#windy"}}
+	code := NamedTagList{ID: "2", Name: "code", Tags: []string{"#tdd"}}
+	namedTagListRepository := &stubNamedTagListRepositoryForRotation{
+		withBucket:        "bucket",
+		withNamedTagLists: []NamedTagList{beach, code},
+	}
+
+	t.Run("share a bucket", func(t *testing.T) {
+		repository := &stubShareRepositoryForService{shares: map[string]Share{}}
+		service := NewShareService(repository, namedTagListRepository, &stubUUIDGenerator{response: "s"}, secret)
+
+		created, err := service.Create(context.Background(), "bucket", nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if lifetime := created.ExpiresAt.Sub(created.CreatedAt); lifetime < 7*24*time.Hour-time.Second || lifetime > 7*24*time.Hour {
+			t.Errorf("got lifetime %s want a week", lifetime)
+		}
+
+		if created.Path != "/shared/"+created.Token {
+			t.Errorf("got path %s want /shared/%s", created.Path, created.Token)
+		}
+
+		got, err := service.Open(context.Background(), created.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &SharedView{"bucket", []NamedTagList{beach, code}, created.ExpiresAt}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if views := repository.shares["s"].Views; views != 1 {
+			t.Errorf("got views %d want 1", views)
+		}
+
+		if text, wantText := got.Text(), "beach\n\n#beach #windy\n\ncode\n\n#tdd"; text != wantText {
+			t.Errorf("got text %q want %q", text, wantText)
+		}
+	})
+
+	t.Run("share a named tag list", func(t *testing.T) {
+		service := NewShareService(&stubShareRepositoryForService{shares: map[string]Share{}}, namedTagListRepository, &stubUUIDGenerator{response: "s"}, secret)
+		id := "2"
+
+		created, err := service.Create(context.Background(), "bucket", &id, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := service.Open(context.Background(), created.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got.NamedTagLists, []NamedTagList{code}) {
+			t.Errorf("got named tag lists %+v want %+v", got.NamedTagLists, []NamedTagList{code})
+		}
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		service := NewShareService(&stubShareRepositoryForService{shares: map[string]Share{}}, namedTagListRepository, &stubUUIDGenerator{}, secret)
+		missing := "3"
+
+		for _, scenario := range []struct {
+			namedTagListID *string
+			lifetime       time.Duration
+			wantErr        string
+		}{
+			{nil, -time.Second, "invalid share: expiresIn must be between 1 second and 365 days"},
+			{nil, 400 * 24 * time.Hour, "invalid share: expiresIn must be between 1 second and 365 days"},
+			{&missing, time.Hour, "invalid share: named tag list 3 is not in bucket bucket"},
+		} {
+			_, gotErr := service.Create(context.Background(), "bucket", scenario.namedTagListID, scenario.lifetime)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("open rejects forged, expired and revoked tokens", func(t *testing.T) {
+		revokedAt := time.Now()
+		repository := &stubShareRepositoryForService{
+			shares: map[string]Share{
+				"live":    {ID: "live", Bucket: "bucket"},
+				"revoked": {ID: "revoked", Bucket: "bucket", RevokedAt: &revokedAt},
+			},
+		}
+		service := NewShareService(repository, namedTagListRepository, &stubUUIDGenerator{}, secret)
+		live := signShareToken(secret, "live", time.Now().Add(time.Hour))
+
+		for _, token := range []string{
+			"garbage",
+			signShareToken([]byte("other secret"), "live", time.Now().Add(time.Hour)),
+			signShareToken(secret, "live", time.Now().Add(-time.Second)),
+			signShareToken(secret, "revoked", time.Now().Add(time.Hour)),
+			signShareToken(secret, "unknown", time.Now().Add(time.Hour)),
+			strings.Replace(live, ".", "x.", 1),
+		} {
+			if _, gotErr := service.Open(context.Background(), token); gotErr != ErrShareNotFound {
+				t.Errorf("got error %v for %s want %v", gotErr, token, ErrShareNotFound)
+			}
+		}
+
+		if _, err := service.Open(context.Background(), live); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package v1
+
+import "strings"
+
+// Stem reduces an English word to its stem using the plural, past tense and
+// trailing-e steps of the Porter algorithm. Words are expected in lower case.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ies"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"):
+	case strings.HasSuffix(word, "s"):
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	if strings.HasSuffix(word, "eed") {
+		if stemMeasure(strings.TrimSuffix(word, "eed")) > 0 {
+			word = strings.TrimSuffix(word, "d")
+		}
+	} else if stem, ok := trimSuffixWithVowel(word, "ed", "ing"); ok {
+		word = stem
+		switch {
+		case strings.HasSuffix(word, "at"), strings.HasSuffix(word, "bl"), strings.HasSuffix(word, "iz"):
+			word += "e"
+		case endsWithDoubleConsonant(word) && !strings.ContainsAny(word[len(word)-1:], "lsz"):
+			word = word[:len(word)-1]
+		case stemMeasure(word) == 1 && endsWithCVC(word):
+			word += "e"
+		}
+	}
+
+	if stem, ok := trimSuffixWithVowel(word, "y"); ok {
+		word = stem + "i"
+	}
+
+	if strings.HasSuffix(word, "e") {
+		stem := strings.TrimSuffix(word, "e")
+		if m := stemMeasure(stem); m > 1 || (m == 1 && !endsWithCVC(stem)) {
+			word = stem
+		}
+	}
+
+	if stemMeasure(word) > 1 && strings.HasSuffix(word, "ll") {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+func trimSuffixWithVowel(word string, suffixes ...string) (string, bool) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) {
+			stem := strings.TrimSuffix(word, suffix)
+			for i := range stem {
+				if isStemVowel(stem, i) {
+					return stem, true
+				}
+			}
+		}
+	}
+	return word, false
+}
+
+func isStemVowel(word string, i int) bool {
+	switch word[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	case 'y':
+		return i > 0 && !isStemVowel(word, i-1)
+	}
+	return false
+}
+
+func stemMeasure(word string) int {
+	m := 0
+	previousVowel := false
+	for i := range word {
+		vowel := isStemVowel(word, i)
+		if previousVowel && !vowel {
+			m++
+		}
+		previousVowel = vowel
+	}
+	return m
+}
+
+func endsWithDoubleConsonant(word string) bool {
+	n := len(word)
+	return n > 1 && word[n-1] == word[n-2] && !isStemVowel(word, n-1)
+}
+
+func endsWithCVC(word string) bool {
+	n := len(word)
+	return n > 2 &&
+		!isStemVowel(word, n-3) &&
+		isStemVowel(word, n-2) &&
+		!isStemVowel(word, n-1) &&
+		!strings.ContainsAny(word[n-1:], "wxy")
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// TagDictionary is a local list of hashtags worth proposing even when no list
+// in the bucket uses them yet.
+type TagDictionary []string
+
+// LoadTagDictionary reads one hashtag per line from a file. Blank lines and
+// lines starting with "//" are ignored. An empty path yields an empty
+// dictionary.
+func LoadTagDictionary(path string) (TagDictionary, error) {
+	dictionary := TagDictionary{}
+	if path == "" {
+		return dictionary, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			line = "#" + line
+		}
+		dictionary = append(dictionary, line)
+	}
+	return dictionary, scanner.Err()
+}
@@ -0,0 +1,299 @@
+package v1
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// TagIndex keeps a co-occurrence matrix of the tags in each bucket. Buckets
+// are loaded from the repository on first use and then kept up to date
+// incrementally as lists change.
+type TagIndex interface {
+	Related(ctx context.Context, buckets []string, tags []string, scorer TagScorer, limit int) ([]TagSuggestion, error)
+	Tags(ctx context.Context, buckets []string) (map[string]int, error)
+	Put(bucket string, namedTagList NamedTagList)
+	Replace(ids []string, namedTagList NamedTagList)
+	DeleteAll(buckets []string)
+	DeleteByIds(ids []string)
+	Move(ids []string, bucket string)
+}
+
+type bucketTagIndex struct {
+	lists map[string][]string
+	tags  map[string]int
+	pairs map[string]map[string]int
+}
+
+func newBucketTagIndex() *bucketTagIndex {
+	return &bucketTagIndex{
+		lists: map[string][]string{},
+		tags:  map[string]int{},
+		pairs: map[string]map[string]int{},
+	}
+}
+
+func (b *bucketTagIndex) put(id string, tags []string) {
+	b.remove(id)
+	tags = uniqueTags(tags)
+	b.lists[id] = tags
+	for _, tag := range tags {
+		b.tags[tag]++
+		for _, other := range tags {
+			if tag == other {
+				continue
+			}
+			if b.pairs[tag] == nil {
+				b.pairs[tag] = map[string]int{}
+			}
+			b.pairs[tag][other]++
+		}
+	}
+}
+
+func (b *bucketTagIndex) remove(id string) {
+	tags, ok := b.lists[id]
+	if !ok {
+		return
+	}
+	delete(b.lists, id)
+	for _, tag := range tags {
+		if b.tags[tag]--; b.tags[tag] == 0 {
+			delete(b.tags, tag)
+		}
+		for _, other := range tags {
+			if tag == other {
+				continue
+			}
+			if b.pairs[tag][other]--; b.pairs[tag][other] == 0 {
+				delete(b.pairs[tag], other)
+			}
+		}
+		if len(b.pairs[tag]) == 0 {
+			delete(b.pairs, tag)
+		}
+	}
+}
+
+type tagIndex struct {
+	namedTagListRepository NamedTagListRepository
+
+	mutex   sync.RWMutex
+	buckets map[string]*bucketTagIndex
+	ids     map[string]string
+	changes int
+}
+
+// load returns the index of each of buckets, fetching any not loaded yet.
+// Fetches run outside the lock so that they hold up no other reader. A
+// fetched bucket is only kept if nothing changed the index meanwhile, since
+// the fetch may have missed the change; otherwise it serves this read alone
+// and the bucket is fetched again on next use.
+func (i *tagIndex) load(ctx context.Context, buckets []string) (map[string]*bucketTagIndex, error) {
+	loaded := map[string]*bucketTagIndex{}
+	i.mutex.RLock()
+	changes := i.changes
+	for _, bucket := range buckets {
+		if index, ok := i.buckets[bucket]; ok {
+			loaded[bucket] = index
+		}
+	}
+	i.mutex.RUnlock()
+
+	for _, bucket := range uniqueTags(buckets) {
+		if _, ok := loaded[bucket]; ok {
+			continue
+		}
+		namedTagLists, err := i.namedTagListRepository.FindAll(ctx, []string{bucket})
+		if err != nil {
+			return nil, err
+		}
+		index := newBucketTagIndex()
+		for _, namedTagList := range namedTagLists {
+			index.put(namedTagList.ID, namedTagList.Tags)
+		}
+		loaded[bucket] = index
+
+		i.mutex.Lock()
+		if _, ok := i.buckets[bucket]; !ok && i.changes == changes {
+			i.buckets[bucket] = index
+			for _, namedTagList := range namedTagLists {
+				i.ids[namedTagList.ID] = bucket
+			}
+		}
+		i.mutex.Unlock()
+	}
+	return loaded, nil
+}
+
+func (i *tagIndex) Related(ctx context.Context, buckets []string, tags []string, scorer TagScorer, limit int) ([]TagSuggestion, error) {
+	indexes, err := i.load(ctx, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	var (
+		lists      int
+		tagCounts  = map[string]int{}
+		pairCounts = map[string]map[string]int{}
+		given      = map[string]bool{}
+	)
+	for _, tag := range tags {
+		given[tag] = true
+		pairCounts[tag] = map[string]int{}
+	}
+	for _, bucket := range uniqueTags(buckets) {
+		index := indexes[bucket]
+		lists += len(index.lists)
+		for tag, count := range index.tags {
+			tagCounts[tag] += count
+		}
+		for tag := range given {
+			for other, count := range index.pairs[tag] {
+				pairCounts[tag][other] += count
+			}
+		}
+	}
+
+	scores := map[string]float64{}
+	for _, tag := range uniqueTags(tags) {
+		for other, both := range pairCounts[tag] {
+			if given[other] {
+				continue
+			}
+			scores[other] += scorer(both, tagCounts[tag], tagCounts[other], lists)
+		}
+	}
+
+	suggestions := []TagSuggestion{}
+	for tag, score := range scores {
+		suggestions = append(suggestions, TagSuggestion{Tag: tag, Score: score})
+	}
+	sort.Slice(suggestions, func(a, b int) bool {
+		if suggestions[a].Score != suggestions[b].Score {
+			return suggestions[a].Score > suggestions[b].Score
+		}
+		return suggestions[a].Tag < suggestions[b].Tag
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+func (i *tagIndex) Tags(ctx context.Context, buckets []string) (map[string]int, error) {
+	indexes, err := i.load(ctx, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	tags := map[string]int{}
+	for _, bucket := range uniqueTags(buckets) {
+		for tag, count := range indexes[bucket].tags {
+			tags[tag] += count
+		}
+	}
+	return tags, nil
+}
+
+func (i *tagIndex) Put(bucket string, namedTagList NamedTagList) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.changes++
+	if index, ok := i.buckets[bucket]; ok {
+		index.put(namedTagList.ID, namedTagList.Tags)
+		i.ids[namedTagList.ID] = bucket
+	}
+}
+
+func (i *tagIndex) Replace(ids []string, namedTagList NamedTagList) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.changes++
+	for _, id := range ids {
+		if bucket, ok := i.ids[id]; ok {
+			i.buckets[bucket].put(id, namedTagList.Tags)
+		}
+	}
+}
+
+func (i *tagIndex) DeleteAll(buckets []string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.changes++
+	for _, bucket := range buckets {
+		if index, ok := i.buckets[bucket]; ok {
+			for id := range index.lists {
+				delete(i.ids, id)
+			}
+			i.buckets[bucket] = newBucketTagIndex()
+		}
+	}
+}
+
+func (i *tagIndex) DeleteByIds(ids []string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.changes++
+	for _, id := range ids {
+		if bucket, ok := i.ids[id]; ok {
+			i.buckets[bucket].remove(id)
+			delete(i.ids, id)
+		}
+	}
+}
+
+// Move carries the lists over to bucket's index. When bucket is loaded but a
+// list's old bucket is not, its tags are unknown, so bucket is dropped to be
+// loaded again on next use.
+func (i *tagIndex) Move(ids []string, bucket string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.changes++
+	target, loaded := i.buckets[bucket]
+	for _, id := range ids {
+		from, ok := i.ids[id]
+		if ok {
+			tags := i.buckets[from].lists[id]
+			i.buckets[from].remove(id)
+			delete(i.ids, id)
+			if loaded {
+				target.put(id, tags)
+				i.ids[id] = bucket
+			}
+		} else if loaded {
+			for id := range target.lists {
+				delete(i.ids, id)
+			}
+			delete(i.buckets, bucket)
+			loaded = false
+		}
+	}
+}
+
+// NewTagIndex ...
+func NewTagIndex(namedTagListRepository NamedTagListRepository) TagIndex {
+	return &tagIndex{
+		namedTagListRepository: namedTagListRepository,
+		buckets:                map[string]*bucketTagIndex{},
+		ids:                    map[string]string{},
+	}
+}
+
+func uniqueTags(tags []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,237 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type stubNamedTagListRepositoryForTagIndex struct {
+	NamedTagListRepository
+
+	withNamedTagLists map[string][]NamedTagList
+	willError         bool
+
+	findAllCalls int
+	whileFinding func()
+}
+
+func (r *stubNamedTagListRepositoryForTagIndex) FindAll(ctx context.Context, buckets []string) ([]NamedTagList, error) {
+	r.findAllCalls++
+	if r.whileFinding != nil {
+		r.whileFinding()
+	}
+	if r.willError {
+		return nil, errors.New("there was an error")
+	}
+	namedTagLists := []NamedTagList{}
+	for _, bucket := range buckets {
+		namedTagLists = append(namedTagLists, r.withNamedTagLists[bucket]...)
+	}
+	return namedTagLists, nil
+}
+
+func TestTagIndex(t *testing.T) {
+	newRepository := func() *stubNamedTagListRepositoryForTagIndex {
+		return &stubNamedTagListRepositoryForTagIndex{
+			withNamedTagLists: map[string][]NamedTagList{
+				"blue": {
+					{ID: "1", Tags: []string{"#windy", "#tdd", "#go"}},
+					{ID: "2", Tags: []string{"#windy", "#tdd"}},
+					{ID: "3", Tags: []string{"#windy", "#beach"}},
+				},
+				"red": {
+					{ID: "4", Tags: []string{"#windy", "#go"}},
+				},
+			},
+		}
+	}
+
+	t.Run("related by jaccard", func(t *testing.T) {
+		index := NewTagIndex(newRepository())
+
+		got, err := index.Related(context.Background(), []string{"blue"}, []string{"#windy"}, Jaccard, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagSuggestion{
+			{Tag: "#tdd", Score: 2.0 / 3.0},
+			{Tag: "#beach", Score: 1.0 / 3.0},
+			{Tag: "#go", Score: 1.0 / 3.0},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("related across buckets by pmi", func(t *testing.T) {
+		index := NewTagIndex(newRepository())
+
+		got, err := index.Related(context.Background(), []string{"blue", "red"}, []string{"#go"}, PMI, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagSuggestion{
+			{Tag: "#tdd", Score: math.Log(1.0 * 4.0 / (2.0 * 2.0))},
+			{Tag: "#windy", Score: math.Log(2.0 * 4.0 / (2.0 * 4.0))},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("suggest excludes given tags and applies limit", func(t *testing.T) {
+		index := NewTagIndex(newRepository())
+
+		got, err := index.Related(context.Background(), []string{"blue"}, []string{"#windy", "#tdd"}, Jaccard, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagSuggestion{
+			{Tag: "#go", Score: Jaccard(1, 3, 1, 3) + Jaccard(1, 2, 1, 3)},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("updates incrementally without reloading", func(t *testing.T) {
+		repository := newRepository()
+		index := NewTagIndex(repository)
+		if _, err := index.Related(context.Background(), []string{"blue"}, []string{"#windy"}, Jaccard, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		index.Put("blue", NamedTagList{ID: "5", Tags: []string{"#beach", "#sand"}})
+		index.Replace([]string{"2"}, NamedTagList{Tags: []string{"#beach", "#windy"}})
+		index.DeleteByIds([]string{"1"})
+
+		got, err := index.Related(context.Background(), []string{"blue"}, []string{"#beach"}, Jaccard, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagSuggestion{
+			{Tag: "#windy", Score: 2.0 / 3.0},
+			{Tag: "#sand", Score: 1.0 / 3.0},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if repository.findAllCalls != 1 {
+			t.Errorf("got %d calls to FindAll want %d", repository.findAllCalls, 1)
+		}
+	})
+
+	t.Run("delete all empties bucket", func(t *testing.T) {
+		index := NewTagIndex(newRepository())
+		if _, err := index.Related(context.Background(), []string{"blue"}, []string{"#windy"}, Jaccard, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		index.DeleteAll([]string{"blue"})
+
+		got, err := index.Related(context.Background(), []string{"blue"}, []string{"#windy"}, Jaccard, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagSuggestion{}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("move carries tags between loaded buckets", func(t *testing.T) {
+		repository := newRepository()
+		index := NewTagIndex(repository)
+		if _, err := index.Tags(context.Background(), []string{"blue", "red"}); err != nil {
+			t.Fatal(err)
+		}
+		calls := repository.findAllCalls
+
+		index.Move([]string{"3"}, "red")
+
+		got, err := index.Tags(context.Background(), []string{"red"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"#windy": 2, "#go": 1, "#beach": 1}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if repository.findAllCalls != calls {
+			t.Errorf("got %d calls to FindAll want %d", repository.findAllCalls, calls)
+		}
+	})
+
+	t.Run("a change while loading is not lost", func(t *testing.T) {
+		repository := newRepository()
+		index := NewTagIndex(repository)
+		repository.whileFinding = func() {
+			repository.whileFinding = nil
+			repository.withNamedTagLists["blue"] = append(repository.withNamedTagLists["blue"], NamedTagList{ID: "5", Tags: []string{"#sand"}})
+			index.Put("blue", NamedTagList{ID: "5", Tags: []string{"#sand"}})
+		}
+
+		index.Tags(context.Background(), []string{"blue"})
+		got, err := index.Tags(context.Background(), []string{"blue"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got["#sand"] != 1 || repository.findAllCalls != 2 {
+			t.Errorf("got tags %+v after %d loads want #sand after 2", got, repository.findAllCalls)
+		}
+	})
+
+	t.Run("a bucket moved away while reading", func(t *testing.T) {
+		repository := newRepository()
+		index := NewTagIndex(repository)
+		index.Tags(context.Background(), []string{"red"})
+		repository.whileFinding = func() {
+			index.Move([]string{"5"}, "red")
+		}
+
+		if _, err := index.Tags(context.Background(), []string{"red", "blue"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("tags across buckets", func(t *testing.T) {
+		index := NewTagIndex(newRepository())
+
+		got, err := index.Tags(context.Background(), []string{"blue", "red"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"#windy": 4, "#tdd": 2, "#go": 2, "#beach": 1}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("related when repository has error", func(t *testing.T) {
+		index := NewTagIndex(&stubNamedTagListRepositoryForTagIndex{willError: true})
+
+		_, gotErr := index.Related(context.Background(), []string{"blue"}, []string{"#windy"}, Jaccard, 10)
+		if gotErr == nil {
+			t.Fatal("got no error")
+		}
+
+		wantErr := "there was an error"
+
+		if gotErr.Error() != wantErr {
+			t.Errorf("got error %s want %s", gotErr.Error(), wantErr)
+		}
+	})
+}
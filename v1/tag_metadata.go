@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// TagMetadata ...
+type TagMetadata struct {
+	Tag       string `json:"tag"`
+	Notes     string `json:"notes"`
+	Category  string `json:"category"`
+	SizeTier  string `json:"sizeTier"`
+	Colour    string `json:"colour"`
+	Favourite bool   `json:"favourite"`
+}
+
+// NamedTagListWithTagMetadata ...
+type NamedTagListWithTagMetadata struct {
+	NamedTagList
+	TagMetadata map[string]TagMetadata `json:"tagMetadata"`
+}
+
+var colourPattern = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+// Validate ...
+func (m TagMetadata) Validate() error {
+	if m.Tag == "" {
+		return fmt.Errorf("tag must not be empty")
+	}
+	switch m.SizeTier {
+	case "", "small", "medium", "large":
+	default:
+		return fmt.Errorf("sizeTier of %s must be small, medium or large", m.Tag)
+	}
+	if m.Colour != "" && !colourPattern.MatchString(m.Colour) {
+		return fmt.Errorf("colour of %s must look like #rrggbb", m.Tag)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+// TagMetadataController ...
+type TagMetadataController interface {
+	GetTagMetadata() http.Handler
+	ReplaceTagMetadata() http.Handler
+	DeleteTagMetadata() http.Handler
+}
+
+type tagMetadataController struct {
+	logger                Logger
+	tagMetadataRepository TagMetadataRepository
+}
+
+func (c *tagMetadataController) GetTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			tagMetadata, err := c.tagMetadataRepository.FindAll(r.Context(), bucket)
+			if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+
+			if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+				wanted := map[string]bool{}
+				for _, tag := range tags {
+					wanted[tag] = true
+				}
+				filtered := []TagMetadata{}
+				for _, m := range tagMetadata {
+					if wanted[m.Tag] {
+						filtered = append(filtered, m)
+					}
+				}
+				tagMetadata = filtered
+			}
+			json.NewEncoder(rw).Encode(tagMetadata)
+		},
+	)
+}
+
+// ReplaceTagMetadata accepts either a single object or an array so that a
+// whole set of tiers can be imported in one call.
+func (c *tagMetadataController) ReplaceTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+
+			defer r.Body.Close()
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			var tagMetadata []TagMetadata
+			if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+				err = json.Unmarshal(body, &tagMetadata)
+			} else {
+				var m TagMetadata
+				err = json.Unmarshal(body, &m)
+				tagMetadata = []TagMetadata{m}
+			}
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			for _, m := range tagMetadata {
+				if err := m.Validate(); err != nil {
+					writeBadRequest(rw, err.Error())
+					return
+				}
+			}
+
+			if err := c.tagMetadataRepository.Upsert(r.Context(), bucket, tagMetadata); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+func (c *tagMetadataController) DeleteTagMetadata() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			bucket, ok := singleBucket(rw, r)
+			if !ok {
+				return
+			}
+			tags := r.URL.Query()["tag"]
+			if len(tags) < 1 {
+				writeBadRequest(rw, "tag query parameter is required")
+				return
+			}
+
+			if err := c.tagMetadataRepository.DeleteByTags(r.Context(), bucket, tags); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+// NewTagMetadataController ...
+func NewTagMetadataController(
+	logger Logger,
+	tagMetadataRepository TagMetadataRepository,
+) TagMetadataController {
+	return &tagMetadataController{
+		logger,
+		tagMetadataRepository,
+	}
+}
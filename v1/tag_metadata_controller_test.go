@@ -0,0 +1,242 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubTagMetadataRepository struct {
+	withBucket      string
+	withTagMetadata []TagMetadata
+	withTags        []string
+	willError       string
+
+	err error
+}
+
+func (r *stubTagMetadataRepository) FindAll(ctx context.Context, bucket string) ([]TagMetadata, error) {
+	if r.willError == "FindAll" {
+		return nil, errors.New("there was an error")
+	}
+	if bucket != r.withBucket {
+		return []TagMetadata{}, nil
+	}
+	return r.withTagMetadata, nil
+}
+
+func (r *stubTagMetadataRepository) Upsert(ctx context.Context, bucket string, tagMetadata []TagMetadata) error {
+	requestMatched := bucket == r.withBucket && reflect.DeepEqual(tagMetadata, r.withTagMetadata)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got bucket %s want %s got tag metadata %+v want %+v", bucket, r.withBucket, tagMetadata, r.withTagMetadata)
+	}
+	if requestMatched == (r.willError == "Upsert") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+func (r *stubTagMetadataRepository) DeleteByTags(ctx context.Context, bucket string, tags []string) error {
+	requestMatched := bucket == r.withBucket && reflect.DeepEqual(tags, r.withTags)
+	if !requestMatched {
+		r.err = fmt.Errorf("Stub got bucket %s want %s got tags %v want %v", bucket, r.withBucket, tags, r.withTags)
+	}
+	if requestMatched == (r.willError == "DeleteByTags") {
+		return errors.New("there was an error")
+	}
+	return nil
+}
+
+func TestTagMetadataController(t *testing.T) {
+	windy := TagMetadata{Tag: "#windy", Notes: "gusty", Category: "weather", SizeTier: "large", Colour: "#00aaff", Favourite: true}
+	tdd := TagMetadata{Tag: "#tdd", SizeTier: "small"}
+
+	t.Run("GET", func(t *testing.T) {
+		controller := NewTagMetadataController(
+			stubLoggerNew(),
+			&stubTagMetadataRepository{withBucket: "bucket", withTagMetadata: []TagMetadata{windy, tdd}},
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/tagMetadata?bucket=bucket&tag=%23windy", nil)
+		response := httptest.NewRecorder()
+		controller.GetTagMetadata().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotTagMetadata []TagMetadata
+		if err := json.NewDecoder(response.Body).Decode(&gotTagMetadata); err != nil {
+			t.Fatal(err)
+		}
+
+		wantTagMetadata := []TagMetadata{windy}
+
+		if !reflect.DeepEqual(gotTagMetadata, wantTagMetadata) {
+			t.Errorf("got tag metadata %+v want %+v", gotTagMetadata, wantTagMetadata)
+		}
+	})
+
+	t.Run("GET when repository has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewTagMetadataController(logger, &stubTagMetadataRepository{willError: "FindAll"})
+
+		request, _ := http.NewRequest(http.MethodGet, "/tagMetadata?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.GetTagMetadata().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+
+	t.Run("PUT one", func(t *testing.T) {
+		repository := &stubTagMetadataRepository{withBucket: "bucket", withTagMetadata: []TagMetadata{windy}}
+		controller := NewTagMetadataController(stubLoggerNew(), repository)
+
+		requestBody, err := json.Marshal(windy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request, _ := http.NewRequest(http.MethodPut, "/tagMetadata?bucket=bucket", strings.NewReader(string(requestBody)))
+		response := httptest.NewRecorder()
+		controller.ReplaceTagMetadata().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("PUT many", func(t *testing.T) {
+		repository := &stubTagMetadataRepository{withBucket: "bucket", withTagMetadata: []TagMetadata{windy, tdd}}
+		controller := NewTagMetadataController(stubLoggerNew(), repository)
+
+		requestBody, err := json.Marshal([]TagMetadata{windy, tdd})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request, _ := http.NewRequest(http.MethodPut, "/tagMetadata?bucket=bucket", strings.NewReader(string(requestBody)))
+		response := httptest.NewRecorder()
+		controller.ReplaceTagMetadata().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("PUT invalid", func(t *testing.T) {
+		for _, scenario := range []struct {
+			body             string
+			wantResponseBody map[string]string
+		}{
+			{body: "{}", wantResponseBody: map[string]string{"error": "tag must not be empty"}},
+			{body: "{\"tag\":\"#windy\",\"sizeTier\":\"huge\"}", wantResponseBody: map[string]string{"error": "sizeTier of #windy must be small, medium or large"}},
+			{body: "[{\"tag\":\"#windy\",\"colour\":\"blue\"}]", wantResponseBody: map[string]string{"error": "colour of #windy must look like #rrggbb"}},
+		} {
+			controller := NewTagMetadataController(stubLoggerNew(), &stubTagMetadataRepository{})
+
+			request, _ := http.NewRequest(http.MethodPut, "/tagMetadata?bucket=bucket", strings.NewReader(scenario.body))
+			response := httptest.NewRecorder()
+			controller.ReplaceTagMetadata().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			var gotResponseBody map[string]string
+			if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotResponseBody, scenario.wantResponseBody) {
+				t.Errorf("got response body %+v want %+v", gotResponseBody, scenario.wantResponseBody)
+			}
+		}
+	})
+
+	t.Run("PUT when request body malformed", func(t *testing.T) {
+		controller := NewTagMetadataController(stubLoggerNew(), &stubTagMetadataRepository{})
+
+		request, _ := http.NewRequest(http.MethodPut, "/tagMetadata?bucket=bucket", strings.NewReader("{\"garbalooy\":\"gook"))
+		response := httptest.NewRecorder()
+		controller.ReplaceTagMetadata().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		repository := &stubTagMetadataRepository{withBucket: "bucket", withTags: []string{"#windy"}}
+		controller := NewTagMetadataController(stubLoggerNew(), repository)
+
+		request, _ := http.NewRequest(http.MethodDelete, "/tagMetadata?bucket=bucket&tag=%23windy", nil)
+		response := httptest.NewRecorder()
+		controller.DeleteTagMetadata().ServeHTTP(response, request)
+
+		if repository.err != nil {
+			t.Error(repository.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE without tag", func(t *testing.T) {
+		controller := NewTagMetadataController(stubLoggerNew(), &stubTagMetadataRepository{})
+
+		request, _ := http.NewRequest(http.MethodDelete, "/tagMetadata?bucket=bucket", nil)
+		response := httptest.NewRecorder()
+		controller.DeleteTagMetadata().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+}
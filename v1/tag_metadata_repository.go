@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TagMetadataRepository ...
+type TagMetadataRepository interface {
+	FindAll(ctx context.Context, bucket string) ([]TagMetadata, error)
+	Upsert(ctx context.Context, bucket string, tagMetadata []TagMetadata) error
+	DeleteByTags(ctx context.Context, bucket string, tags []string) error
+}
+
+type tagMetadataRepository struct {
+	db database
+}
+
+func (r *tagMetadataRepository) FindAll(ctx context.Context, bucket string) ([]TagMetadata, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	if rows, err = r.db.query(ctx, "FindAll", "select \"tag\", \"notes\", \"category\", \"size_tier\", \"colour\", \"favourite\" from tag_metadata where \"bucket\" = $1 order by \"tag\"", bucket); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tagMetadata := []TagMetadata{}
+
+	var m TagMetadata
+	for rows.Next() {
+		if err = rows.Scan(&m.Tag, &m.Notes, &m.Category, &m.SizeTier, &m.Colour, &m.Favourite); err != nil {
+			return nil, err
+		}
+		tagMetadata = append(tagMetadata, m)
+	}
+
+	return tagMetadata, rows.Err()
+}
+
+func (r *tagMetadataRepository) Upsert(ctx context.Context, bucket string, tagMetadata []TagMetadata) error {
+	batch := &pgx.Batch{}
+	for _, m := range tagMetadata {
+		batch.Queue(
+			"upsert into tag_metadata (\"bucket\", \"tag\", \"notes\", \"category\", \"size_tier\", \"colour\", \"favourite\") values ($1, $2, $3, $4, $5, $6, $7)",
+			bucket,
+			m.Tag,
+			m.Notes,
+			m.Category,
+			m.SizeTier,
+			m.Colour,
+			m.Favourite,
+		)
+	}
+	return r.db.write(ctx, "Upsert", func(ctx context.Context, tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *tagMetadataRepository) DeleteByTags(ctx context.Context, bucket string, tags []string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteByTags",
+		"delete from tag_metadata where \"bucket\" = $1 and \"tag\" = ANY($2)",
+		bucket,
+		tags,
+	)
+}
+
+// NewTagMetadataRepository ...
+func NewTagMetadataRepository(pool *pgxpool.Pool, timeouts Timeouts) TagMetadataRepository {
+	return &tagMetadataRepository{database{pool, timeouts}}
+}
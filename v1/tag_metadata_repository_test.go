@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestTagMetadataRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	windy := TagMetadata{Tag: "#windy", Notes: "gusty", Category: "weather", SizeTier: "large", Colour: "#00aaff", Favourite: true}
+	tdd := TagMetadata{Tag: "#tdd", SizeTier: "small"}
+
+	t.Run("get empty tag metadata", func(t *testing.T) {
+		got, err := NewTagMetadataRepository(pool, Timeouts{}).FindAll(context.Background(), "blue")
+		assertutil.NotError(t, err)
+		want := []TagMetadata{}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("upsert tag metadata", func(t *testing.T) {
+		assertutil.NotError(t, NewTagMetadataRepository(pool, Timeouts{}).Upsert(context.Background(), "blue", []TagMetadata{windy, tdd}))
+		assertutil.NotError(t, NewTagMetadataRepository(pool, Timeouts{}).Upsert(context.Background(), "red", []TagMetadata{tdd}))
+
+		tdd.Favourite = true
+		assertutil.NotError(t, NewTagMetadataRepository(pool, Timeouts{}).Upsert(context.Background(), "blue", []TagMetadata{tdd}))
+
+		got, err := NewTagMetadataRepository(pool, Timeouts{}).FindAll(context.Background(), "blue")
+		assertutil.NotError(t, err)
+		want := []TagMetadata{tdd, windy}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("delete tag metadata by tag", func(t *testing.T) {
+		assertutil.NotError(t, NewTagMetadataRepository(pool, Timeouts{}).DeleteByTags(context.Background(), "blue", []string{"#tdd"}))
+
+		got, err := NewTagMetadataRepository(pool, Timeouts{}).FindAll(context.Background(), "blue")
+		assertutil.NotError(t, err)
+		want := []TagMetadata{windy}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if got, err = NewTagMetadataRepository(pool, Timeouts{}).FindAll(context.Background(), "red"); err != nil {
+			t.Fatal(err)
+		}
+		want = []TagMetadata{{Tag: "#tdd", SizeTier: "small"}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
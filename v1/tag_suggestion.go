@@ -0,0 +1,30 @@
+package v1
+
+import "math"
+
+// TagSuggestion ...
+type TagSuggestion struct {
+	Tag   string  `json:"tag"`
+	Score float64 `json:"score"`
+}
+
+// TagScorer scores how strongly two tags are related given the number of
+// lists containing both, the number containing each and the total number of
+// lists.
+type TagScorer func(both, a, b, lists int) float64
+
+// PMI ...
+func PMI(both, a, b, lists int) float64 {
+	return math.Log(float64(both) * float64(lists) / (float64(a) * float64(b)))
+}
+
+// Jaccard ...
+func Jaccard(both, a, b, lists int) float64 {
+	return float64(both) / float64(a+b-both)
+}
+
+// TagScorers ...
+var TagScorers = map[string]TagScorer{
+	"pmi":     PMI,
+	"jaccard": Jaccard,
+}
@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TagSuggestionController ...
+type TagSuggestionController interface {
+	GetRelatedTags() http.Handler
+	Suggest() http.Handler
+	SuggestFromCaption() http.Handler
+}
+
+type tagSuggestionController struct {
+	logger                   Logger
+	tagIndex                 TagIndex
+	captionSuggestionService CaptionSuggestionService
+	namedTagListService      NamedTagListService
+}
+
+// SuggestRequest ...
+type SuggestRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// SuggestFromCaptionRequest ...
+type SuggestFromCaptionRequest struct {
+	Caption string `json:"caption"`
+	Name    string `json:"name"`
+}
+
+func (c *tagSuggestionController) GetRelatedTags() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/tags/")
+			if !strings.HasSuffix(path, "/related") || path == "/related" {
+				http.NotFound(rw, r)
+				return
+			}
+			tag := strings.TrimSuffix(path, "/related")
+
+			c.writeSuggestions(rw, r, []string{tag})
+		},
+	)
+}
+
+func (c *tagSuggestionController) Suggest() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			var suggestRequest SuggestRequest
+			if json.NewDecoder(r.Body).Decode(&suggestRequest) != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			c.writeSuggestions(rw, r, suggestRequest.Tags)
+		},
+	)
+}
+
+func (c *tagSuggestionController) SuggestFromCaption() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			buckets := r.URL.Query()["bucket"]
+			create := r.URL.Query().Get("create") == "true"
+			if len(buckets) < 1 {
+				writeBadRequest(rw, "bucket query parameter is required")
+				return
+			} else if create && len(buckets) > 1 {
+				writeBadRequest(rw, "no more than one bucket must be supplied")
+				return
+			}
+
+			defer r.Body.Close()
+			var (
+				request    SuggestFromCaptionRequest
+				suggestion *CaptionSuggestion
+				err        error
+			)
+			if json.NewDecoder(r.Body).Decode(&request) != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			} else if suggestion, err = c.captionSuggestionService.Suggest(r.Context(), buckets, request.Caption); err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			if request.Name != "" {
+				suggestion.Name = request.Name
+			}
+
+			if !create {
+				json.NewEncoder(rw).Encode(suggestion)
+				return
+			}
+
+			var namedTagList *NamedTagList
+			if namedTagList, err = c.namedTagListService.Create(
+				r.Context(),
+				buckets[0],
+				NamedTagList{Name: suggestion.Name, Tags: suggestion.Tags},
+			); errors.Is(err, ErrQuotaExceeded) {
+				writeError(rw, http.StatusConflict, err.Error())
+				return
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+				return
+			}
+			suggestion.ID = namedTagList.ID
+			rw.WriteHeader(http.StatusCreated)
+			json.NewEncoder(rw).Encode(suggestion)
+		},
+	)
+}
+
+func (c *tagSuggestionController) writeSuggestions(rw http.ResponseWriter, r *http.Request, tags []string) {
+	buckets := r.URL.Query()["bucket"]
+	if len(buckets) < 1 {
+		writeBadRequest(rw, "bucket query parameter is required")
+		return
+	}
+
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			writeBadRequest(rw, "limit must be a positive integer")
+			return
+		}
+	}
+
+	score := "jaccard"
+	if value := r.URL.Query().Get("score"); value != "" {
+		score = value
+	}
+	scorer, ok := TagScorers[score]
+	if !ok {
+		writeBadRequest(rw, "score must be pmi or jaccard")
+		return
+	}
+
+	suggestions, err := c.tagIndex.Related(r.Context(), buckets, tags, scorer, limit)
+	if err != nil {
+		writeServerError(rw, r, c.logger, err)
+		return
+	}
+	json.NewEncoder(rw).Encode(suggestions)
+}
+
+// NewTagSuggestionController ...
+func NewTagSuggestionController(
+	logger Logger,
+	tagIndex TagIndex,
+	captionSuggestionService CaptionSuggestionService,
+	namedTagListService NamedTagListService,
+) TagSuggestionController {
+	return &tagSuggestionController{
+		logger,
+		tagIndex,
+		captionSuggestionService,
+		namedTagListService,
+	}
+}
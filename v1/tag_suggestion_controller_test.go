@@ -0,0 +1,344 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stubTagIndex struct {
+	TagIndex
+
+	withBuckets []string
+	withTags    []string
+	withLimit   int
+	willError   bool
+
+	err error
+}
+
+func (i *stubTagIndex) Related(ctx context.Context, buckets []string, tags []string, scorer TagScorer, limit int) ([]TagSuggestion, error) {
+	requestMatched := reflect.DeepEqual(buckets, i.withBuckets) && reflect.DeepEqual(tags, i.withTags) && limit == i.withLimit
+	if !requestMatched {
+		i.err = fmt.Errorf("Stub got buckets %v want %v got tags %v want %v got limit %d want %d", buckets, i.withBuckets, tags, i.withTags, limit, i.withLimit)
+	}
+	if requestMatched == i.willError {
+		return nil, errors.New("there was an error")
+	}
+	return []TagSuggestion{{Tag: "#tdd", Score: 0.5}}, nil
+}
+
+type stubCaptionSuggestionService struct {
+	withBuckets []string
+	withCaption string
+	willError   bool
+
+	err error
+}
+
+func (s *stubCaptionSuggestionService) Suggest(ctx context.Context, buckets []string, caption string) (*CaptionSuggestion, error) {
+	requestMatched := reflect.DeepEqual(buckets, s.withBuckets) && caption == s.withCaption
+	if !requestMatched {
+		s.err = fmt.Errorf("Stub got buckets %v want %v got caption %q want %q", buckets, s.withBuckets, caption, s.withCaption)
+	}
+	if requestMatched == s.willError {
+		return nil, errors.New("there was an error")
+	}
+	return &CaptionSuggestion{
+		Name: "Windy day",
+		Tags: []string{"#windy"},
+		Candidates: []HashtagCandidate{
+			{Tag: "#windy", Source: "bucket", Reason: "\"windy\" matches a tag used in bucket"},
+		},
+	}, nil
+}
+
+func TestTagSuggestionController(t *testing.T) {
+	t.Run("GET related", func(t *testing.T) {
+		index := &stubTagIndex{
+			withBuckets: []string{"red", "blue"},
+			withTags:    []string{"#windy"},
+			withLimit:   5,
+		}
+		controller := NewTagSuggestionController(stubLoggerNew(), index, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/tags/%23windy/related?bucket=red&bucket=blue&limit=5", nil)
+		response := httptest.NewRecorder()
+		controller.GetRelatedTags().ServeHTTP(response, request)
+
+		if index.err != nil {
+			t.Error(index.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotSuggestions []TagSuggestion
+		if err := json.NewDecoder(response.Body).Decode(&gotSuggestions); err != nil {
+			t.Fatal(err)
+		}
+
+		wantSuggestions := []TagSuggestion{{Tag: "#tdd", Score: 0.5}}
+
+		if !reflect.DeepEqual(gotSuggestions, wantSuggestions) {
+			t.Errorf("got suggestions %+v want %+v", gotSuggestions, wantSuggestions)
+		}
+	})
+
+	t.Run("GET unknown tag sub-resource", func(t *testing.T) {
+		controller := NewTagSuggestionController(stubLoggerNew(), &stubTagIndex{}, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/tags/%23windy/unrelated?bucket=red", nil)
+		response := httptest.NewRecorder()
+		controller.GetRelatedTags().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 404
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("GET related with bad query parameters", func(t *testing.T) {
+		for _, scenario := range []struct {
+			query            string
+			wantResponseBody map[string]string
+		}{
+			{query: "", wantResponseBody: map[string]string{"error": "bucket query parameter is required"}},
+			{query: "bucket=red&limit=zero", wantResponseBody: map[string]string{"error": "limit must be a positive integer"}},
+			{query: "bucket=red&score=cosine", wantResponseBody: map[string]string{"error": "score must be pmi or jaccard"}},
+		} {
+			controller := NewTagSuggestionController(stubLoggerNew(), &stubTagIndex{}, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+			request, _ := http.NewRequest(http.MethodGet, "/tags/%23windy/related?"+scenario.query, nil)
+			response := httptest.NewRecorder()
+			controller.GetRelatedTags().ServeHTTP(response, request)
+
+			gotStatusCode := response.Result().StatusCode
+			wantStatusCode := 400
+
+			if gotStatusCode != wantStatusCode {
+				t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+			}
+
+			var gotResponseBody map[string]string
+			if err := json.NewDecoder(response.Body).Decode(&gotResponseBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotResponseBody, scenario.wantResponseBody) {
+				t.Errorf("got response body %+v want %+v", gotResponseBody, scenario.wantResponseBody)
+			}
+		}
+	})
+
+	t.Run("POST suggest", func(t *testing.T) {
+		index := &stubTagIndex{
+			withBuckets: []string{"bucket"},
+			withTags:    []string{"#windy", "#beach"},
+			withLimit:   10,
+		}
+		controller := NewTagSuggestionController(stubLoggerNew(), index, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest?bucket=bucket&score=pmi", strings.NewReader("{\"tags\":[\"#windy\",\"#beach\"]}"))
+		response := httptest.NewRecorder()
+		controller.Suggest().ServeHTTP(response, request)
+
+		if index.err != nil {
+			t.Error(index.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST suggest when request body malformed", func(t *testing.T) {
+		controller := NewTagSuggestionController(stubLoggerNew(), &stubTagIndex{}, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest?bucket=bucket", strings.NewReader("{\"garbalooy\":\"gook"))
+		response := httptest.NewRecorder()
+		controller.Suggest().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST suggest when index has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		index := &stubTagIndex{
+			withBuckets: []string{"bucket"},
+			withTags:    []string{"#windy"},
+			withLimit:   10,
+			willError:   true,
+		}
+		controller := NewTagSuggestionController(logger, index, &stubCaptionSuggestionService{}, &stubNamedTagListService{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest?bucket=bucket", strings.NewReader("{\"tags\":[\"#windy\"]}"))
+		response := httptest.NewRecorder()
+		controller.Suggest().ServeHTTP(response, request)
+
+		if index.err != nil {
+			t.Error(index.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+
+	t.Run("POST suggest from caption", func(t *testing.T) {
+		captionSuggestionService := &stubCaptionSuggestionService{
+			withBuckets: []string{"bucket"},
+			withCaption: "Windy day",
+		}
+		controller := NewTagSuggestionController(
+			stubLoggerNew(),
+			&stubTagIndex{},
+			captionSuggestionService,
+			&stubNamedTagListService{},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest/fromCaption?bucket=bucket", strings.NewReader("{\"caption\":\"Windy day\"}"))
+		response := httptest.NewRecorder()
+		controller.SuggestFromCaption().ServeHTTP(response, request)
+
+		if captionSuggestionService.err != nil {
+			t.Error(captionSuggestionService.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 200
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		var gotSuggestion CaptionSuggestion
+		if err := json.NewDecoder(response.Body).Decode(&gotSuggestion); err != nil {
+			t.Fatal(err)
+		}
+
+		wantSuggestion := CaptionSuggestion{
+			Name: "Windy day",
+			Tags: []string{"#windy"},
+			Candidates: []HashtagCandidate{
+				{Tag: "#windy", Source: "bucket", Reason: "\"windy\" matches a tag used in bucket"},
+			},
+		}
+
+		if !reflect.DeepEqual(gotSuggestion, wantSuggestion) {
+			t.Errorf("got suggestion %+v want %+v", gotSuggestion, wantSuggestion)
+		}
+	})
+
+	t.Run("POST suggest from caption and create", func(t *testing.T) {
+		namedTagListService := &stubNamedTagListService{
+			withBucket: "bucket",
+			withNamedTagList: NamedTagList{
+				Name: "renamed",
+				Tags: []string{"#windy"},
+			},
+		}
+		controller := NewTagSuggestionController(
+			stubLoggerNew(),
+			&stubTagIndex{},
+			&stubCaptionSuggestionService{
+				withBuckets: []string{"bucket"},
+				withCaption: "Windy day",
+			},
+			namedTagListService,
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest/fromCaption?bucket=bucket&create=true", strings.NewReader("{\"caption\":\"Windy day\",\"name\":\"renamed\"}"))
+		response := httptest.NewRecorder()
+		controller.SuggestFromCaption().ServeHTTP(response, request)
+
+		if namedTagListService.err != nil {
+			t.Error(namedTagListService.err)
+		}
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST suggest from caption and create with more than one bucket", func(t *testing.T) {
+		controller := NewTagSuggestionController(
+			stubLoggerNew(),
+			&stubTagIndex{},
+			&stubCaptionSuggestionService{},
+			&stubNamedTagListService{},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest/fromCaption?bucket=one&bucket=two&create=true", strings.NewReader("{\"caption\":\"Windy day\"}"))
+		response := httptest.NewRecorder()
+		controller.SuggestFromCaption().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 400
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST suggest from caption when service has error", func(t *testing.T) {
+		logger := stubLoggerNew()
+		controller := NewTagSuggestionController(
+			logger,
+			&stubTagIndex{},
+			&stubCaptionSuggestionService{
+				withBuckets: []string{"bucket"},
+				withCaption: "Windy day",
+				willError:   true,
+			},
+			&stubNamedTagListService{},
+		)
+
+		request, _ := http.NewRequest(http.MethodPost, "/suggest/fromCaption?bucket=bucket", strings.NewReader("{\"caption\":\"Windy day\"}"))
+		response := httptest.NewRecorder()
+		controller.SuggestFromCaption().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 500
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		wantErrorf := []string{"there was an error"}
+		if !reflect.DeepEqual(logger.errors, wantErrorf) {
+			t.Errorf("got logger.Errorf %+v want %+v", logger.errors, wantErrorf)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrDatabaseBusy means no pool connection came free before the operation's
+// deadline, so the query never started.
+var ErrDatabaseBusy = errors.New("the database is too busy to take this request, try again shortly")
+
+// ErrQueryTimeout means a query started but did not finish before the
+// operation's deadline.
+var ErrQueryTimeout = errors.New("the database did not answer in time")
+
+// Timeouts bounds how long each repository operation may hold a connection,
+// including the wait to acquire one. A zero duration means no limit beyond
+// the caller's own context.
+type Timeouts struct {
+	Default     time.Duration
+	ByOperation map[string]time.Duration
+}
+
+// For ...
+func (t Timeouts) For(operation string) time.Duration {
+	if timeout, ok := t.ByOperation[operation]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// ParseTimeouts reads overrides such as "FindAll=2s,DeleteAll=30s" on top of
+// a default.
+func ParseTimeouts(fallback time.Duration, overrides string) (Timeouts, error) {
+	timeouts := Timeouts{fallback, map[string]time.Duration{}}
+	for _, override := range strings.Split(overrides, ",") {
+		if strings.TrimSpace(override) == "" {
+			continue
+		}
+		parts := strings.SplitN(override, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return timeouts, fmt.Errorf("timeout %q must look like Operation=duration", override)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return timeouts, fmt.Errorf("timeout for %s: %w", strings.TrimSpace(parts[0]), err)
+		}
+		timeouts.ByOperation[strings.TrimSpace(parts[0])] = timeout
+	}
+	return timeouts, nil
+}
+
+// withTimeout applies the operation's timeout to ctx.
+func (t Timeouts) withTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if timeout := t.For(operation); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timedOut wraps err in sentinel when it came about because ctx ran out of
+// time rather than for any other reason.
+func timedOut(ctx context.Context, sentinel error, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", sentinel, err)
+	}
+	return err
+}
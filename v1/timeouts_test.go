@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	t.Run("parse overrides", func(t *testing.T) {
+		got, err := ParseTimeouts(5*time.Second, "FindAll=2s, DeleteAll=30s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Timeouts{5 * time.Second, map[string]time.Duration{"FindAll": 2 * time.Second, "DeleteAll": 30 * time.Second}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		if got, want := got.For("FindAll"), 2*time.Second; got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+		if got, want := got.For("Create"), 5*time.Second; got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	})
+
+	t.Run("parse malformed overrides", func(t *testing.T) {
+		for _, overrides := range []string{"FindAll", "=2s", "FindAll=soon"} {
+			if _, err := ParseTimeouts(time.Second, overrides); err == nil {
+				t.Errorf("got no error for %q", overrides)
+			}
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type spanContextKey struct{}
+
+// SpanContext identifies a span across processes as W3C trace context does.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Sampled    bool
+	TraceState string
+}
+
+// Span times one operation. A nil span is a no-op, which is what StartSpan
+// hands out when the request is not being traced.
+type Span struct {
+	tracer *tracer
+
+	Name       string
+	Context    SpanContext
+	ParentID   [8]byte
+	Kind       int
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes []Field
+	Err        error
+}
+
+// Span kinds, numbered as in OTLP.
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+	SpanKindClient   = 3
+)
+
+// SetAttributes ...
+func (s *Span) SetAttributes(fields ...Field) {
+	if s != nil {
+		s.Attributes = append(s.Attributes, fields...)
+	}
+}
+
+// End records err, if any, as the span's status and hands the span off for
+// export when it was sampled.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.EndTime = s.tracer.now()
+	s.Err = err
+	if s.Context.Sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+// StartSpan starts a child of the span in ctx, or returns a nil span when
+// there is none.
+func StartSpan(ctx context.Context, name string, fields ...Field) (context.Context, *Span) {
+	parent, _ := ctx.Value(spanContextKey{}).(*Span)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, name, SpanKindInternal, parent.Context, parent.Context.SpanID, fields)
+}
+
+// StartClientSpan is StartSpan for calls out to another system, such as the
+// database.
+func StartClientSpan(ctx context.Context, name string, fields ...Field) (context.Context, *Span) {
+	ctx, span := StartSpan(ctx, name, fields...)
+	if span != nil {
+		span.Kind = SpanKindClient
+	}
+	return ctx, span
+}
+
+// SpanExporter ships finished spans somewhere they can be looked at.
+type SpanExporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer ...
+type Tracer interface {
+	Handler(next http.Handler) http.Handler
+	Flush() error
+	Shutdown(ctx context.Context) error
+}
+
+type tracer struct {
+	exporter SpanExporter
+	now      func() time.Time
+	stop     chan struct{}
+	stopped  chan struct{}
+
+	mutex   sync.Mutex
+	pending []*Span
+}
+
+// Handler starts a server span for every request, continuing the trace named
+// by a valid traceparent header and starting a new one otherwise.
+func (t *tracer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			parent, ok := parseTraceparent(r.Header.Get("traceparent"))
+			var parentID [8]byte
+			if ok {
+				parentID = parent.SpanID
+				parent.TraceState = r.Header.Get("tracestate")
+			} else {
+				rand.Read(parent.TraceID[:])
+				parent.Sampled = true
+			}
+
+			route := routeOf(r.URL.Path)
+			ctx, span := t.start(r.Context(), r.Method+" "+route, SpanKindServer, parent, parentID, []Field{
+				F("http.method", r.Method),
+				F("http.route", route),
+				F("http.request_id", RequestInfoFromContext(r.Context()).ID),
+			})
+			recorder := &responseRecorder{ResponseWriter: rw}
+
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			span.SetAttributes(F("http.status_code", recorder.status))
+			span.End(nil)
+		},
+	)
+}
+
+func (t *tracer) start(ctx context.Context, name string, kind int, parent SpanContext, parentID [8]byte, fields []Field) (context.Context, *Span) {
+	span := &Span{
+		tracer:     t,
+		Name:       name,
+		Context:    parent,
+		ParentID:   parentID,
+		Kind:       kind,
+		StartTime:  t.now(),
+		Attributes: fields,
+	}
+	rand.Read(span.Context.SpanID[:])
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// enqueue holds spans until the next flush, dropping the oldest beyond a
+// limit so that an unreachable exporter cannot exhaust memory.
+func (t *tracer) enqueue(span *Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.pending = append(t.pending, span)
+	if len(t.pending) > 4096 {
+		t.pending = t.pending[len(t.pending)-4096:]
+	}
+}
+
+func (t *tracer) Flush() error {
+	t.mutex.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mutex.Unlock()
+
+	if len(spans) < 1 {
+		return nil
+	}
+	return t.exporter.Export(spans)
+}
+
+// Shutdown stops exporting every interval and exports the spans left, giving
+// up when ctx is done.
+func (t *tracer) Shutdown(ctx context.Context) error {
+	close(t.stop)
+	flushed := make(chan error, 1)
+	go func() {
+		<-t.stopped
+		flushed <- t.Flush()
+	}()
+	select {
+	case err := <-flushed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseTraceparent accepts version 00 headers and, as the spec asks, the
+// first four fields of headers from later versions.
+func parseTraceparent(header string) (SpanContext, bool) {
+	var c SpanContext
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return c, false
+	}
+
+	version, err := hex.DecodeString(parts[0])
+	traceID, traceErr := hex.DecodeString(parts[1])
+	spanID, spanErr := hex.DecodeString(parts[2])
+	flags, flagsErr := hex.DecodeString(parts[3])
+	if err != nil || traceErr != nil || spanErr != nil || flagsErr != nil ||
+		len(version) != 1 || len(traceID) != 16 || len(spanID) != 8 || len(flags) != 1 ||
+		parts[1] != strings.ToLower(parts[1]) || parts[2] != strings.ToLower(parts[2]) {
+		return c, false
+	}
+	copy(c.TraceID[:], traceID)
+	copy(c.SpanID[:], spanID)
+	if c.TraceID == [16]byte{} || c.SpanID == [8]byte{} {
+		return c, false
+	}
+	c.Sampled = flags[0]&1 == 1
+	return c, true
+}
+
+// NewTracer exports spans every interval until Shutdown.
+func NewTracer(exporter SpanExporter, logger Logger, interval time.Duration) Tracer {
+	t := &tracer{
+		exporter: exporter,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go func() {
+		defer close(t.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-t.stop:
+				return
+			}
+			if err := t.Flush(); err != nil {
+				logger.Error(err)
+			}
+		}
+	}()
+	return t
+}
+
+// traceHandler runs handler in a span of its own so that traces show where a
+// controller's work starts.
+func traceHandler(name string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			ctx, span := StartSpan(r.Context(), name)
+			handler.ServeHTTP(rw, r.WithContext(ctx))
+			span.End(nil)
+		},
+	)
+}
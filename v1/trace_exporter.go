@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// otlpTraces builds an OTLP/JSON ExportTraceServiceRequest, which is both
+// what an OTLP/HTTP collector accepts and what a collector's file receiver
+// reads back, one request per line.
+func otlpTraces(serviceName string, spans []*Span) map[string]interface{} {
+	encoded := make([]map[string]interface{}, len(spans))
+	for i, span := range spans {
+		status := map[string]interface{}{"code": 1}
+		if span.Err != nil {
+			status = map[string]interface{}{"code": 2, "message": span.Err.Error()}
+		}
+		encoded[i] = map[string]interface{}{
+			"traceId":           hex.EncodeToString(span.Context.TraceID[:]),
+			"spanId":            hex.EncodeToString(span.Context.SpanID[:]),
+			"name":              span.Name,
+			"kind":              span.Kind,
+			"startTimeUnixNano": strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attributes),
+			"status":            status,
+		}
+		if span.ParentID != [8]byte{} {
+			encoded[i]["parentSpanId"] = hex.EncodeToString(span.ParentID[:])
+		}
+		if span.Context.TraceState != "" {
+			encoded[i]["traceState"] = span.Context.TraceState
+		}
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes([]Field{F("service.name", serviceName)}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "github.com/arctair/hashbang/v1"},
+						"spans": encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(fields []Field) []interface{} {
+	attributes := make([]interface{}, len(fields))
+	for i, field := range fields {
+		var value map[string]interface{}
+		switch v := plainValue(field.Value).(type) {
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		attributes[i] = map[string]interface{}{"key": field.Key, "value": value}
+	}
+	return attributes
+}
+
+type fileSpanExporter struct {
+	serviceName string
+	path        string
+	mutex       sync.Mutex
+}
+
+func (e *fileSpanExporter) Export(spans []*Span) error {
+	line, err := json.Marshal(otlpTraces(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	file, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewFileSpanExporter appends spans to a file as OTLP/JSON lines.
+func NewFileSpanExporter(serviceName string, path string) SpanExporter {
+	return &fileSpanExporter{serviceName: serviceName, path: path}
+}
+
+type otlpSpanExporter struct {
+	serviceName string
+	endpoint    string
+	client      *http.Client
+}
+
+func (e *otlpSpanExporter) Export(spans []*Span) error {
+	body, err := json.Marshal(otlpTraces(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	response, err := e.client.Post(e.endpoint+"/v1/traces", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("exporting %d spans to %s: %s", len(spans), e.endpoint, response.Status)
+	}
+	return nil
+}
+
+// NewOTLPSpanExporter posts spans to an OTLP/HTTP collector, such as
+// http://localhost:4318.
+func NewOTLPSpanExporter(serviceName string, endpoint string) SpanExporter {
+	return &otlpSpanExporter{serviceName, endpoint, &http.Client{Timeout: 10 * time.Second}}
+}
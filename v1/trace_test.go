@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+)
+
+type stubSpanExporter struct {
+	spans []*Span
+}
+
+func (e *stubSpanExporter) Export(spans []*Span) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestTracer(t *testing.T) {
+	exporter := &stubSpanExporter{}
+	tracer := &tracer{exporter: exporter, now: func() time.Time { return time.Unix(1604232000, 0) }}
+	handler := tracer.Handler(traceHandler("Controller.Get", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartSpan(r.Context(), "Service.Get", F("bucket", "blue"))
+		_, query := StartClientSpan(ctx, "SELECT")
+		query.End(errors.New("no connection"))
+		span.End(nil)
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	t.Run("continue a trace from traceparent", func(t *testing.T) {
+		exporter.spans = nil
+		request, _ := http.NewRequest(http.MethodGet, "/rotations/1/next", nil)
+		request.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		request.Header.Set("tracestate", "vendor=1")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assertutil.NotError(t, tracer.Flush())
+
+		gotNames := []string{}
+		for _, span := range exporter.spans {
+			gotNames = append(gotNames, span.Name)
+		}
+		wantNames := []string{"SELECT", "Service.Get", "Controller.Get", "GET /rotations/{id}/next"}
+		if !reflect.DeepEqual(gotNames, wantNames) {
+			t.Fatalf("got spans %v want %v", gotNames, wantNames)
+		}
+
+		for i, span := range exporter.spans {
+			if got := hex.EncodeToString(span.Context.TraceID[:]); got != "0af7651916cd43dd8448eb211c80319c" {
+				t.Errorf("got trace id %s for %s", got, span.Name)
+			}
+			if span.Context.TraceState != "vendor=1" {
+				t.Errorf("got trace state %s for %s", span.Context.TraceState, span.Name)
+			}
+			wantParent := "b7ad6b7169203331"
+			if i < 3 {
+				wantParent = hex.EncodeToString(exporter.spans[i+1].Context.SpanID[:])
+			}
+			if got := hex.EncodeToString(span.ParentID[:]); got != wantParent {
+				t.Errorf("got parent %s for %s want %s", got, span.Name, wantParent)
+			}
+		}
+
+		if got := exporter.spans[0]; got.Kind != SpanKindClient || got.Err == nil {
+			t.Errorf("got query span %+v want a failed client span", got)
+		}
+		if got := exporter.spans[3]; got.Kind != SpanKindServer || !reflect.DeepEqual(got.Attributes[3], F("http.status_code", 418)) {
+			t.Errorf("got server span %+v want status 418", got)
+		}
+	})
+
+	t.Run("start a trace without traceparent", func(t *testing.T) {
+		exporter.spans = nil
+		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists", nil)
+		request.Header.Set("traceparent", "00-00000000000000000000000000000000-b7ad6b7169203331-01")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assertutil.NotError(t, tracer.Flush())
+
+		root := exporter.spans[3]
+		if root.ParentID != [8]byte{} || root.Context.TraceID == [16]byte{} {
+			t.Errorf("got root span %+v want a new trace", root)
+		}
+	})
+
+	t.Run("drop unsampled traces", func(t *testing.T) {
+		exporter.spans = nil
+		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists", nil)
+		request.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assertutil.NotError(t, tracer.Flush())
+
+		if len(exporter.spans) != 0 {
+			t.Errorf("got %d spans want none", len(exporter.spans))
+		}
+	})
+
+	t.Run("export spans left at shutdown", func(t *testing.T) {
+		exporter := &stubSpanExporter{}
+		tracer := NewTracer(exporter, stubLoggerNew(), time.Hour)
+		request, _ := http.NewRequest(http.MethodGet, "/namedTagLists", nil)
+
+		tracer.Handler(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), request)
+		assertutil.NotError(t, tracer.Shutdown(context.Background()))
+
+		if len(exporter.spans) != 1 {
+			t.Errorf("got %d spans want 1", len(exporter.spans))
+		}
+	})
+
+	t.Run("hand out nil spans outside a trace", func(t *testing.T) {
+		ctx, span := StartSpan(context.Background(), "Service.Get")
+		span.SetAttributes(F("bucket", "blue"))
+		span.End(nil)
+
+		if span != nil || ctx != context.Background() {
+			t.Errorf("got span %+v want nil", span)
+		}
+	})
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for header, wantOk := range map[string]bool{
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01":       true,
+		"01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra": true,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra": false,
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01":       false,
+		"00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01":       false,
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01":       false,
+		"00-0af7651916cd43dd8448eb211c8031-b7ad6b7169203331-01":         false,
+		"": false,
+	} {
+		if _, gotOk := parseTraceparent(header); gotOk != wantOk {
+			t.Errorf("got %t for %q want %t", gotOk, header, wantOk)
+		}
+	}
+}
+
+func TestSpanExporters(t *testing.T) {
+	span := &Span{
+		Name:       "SELECT",
+		Kind:       SpanKindClient,
+		StartTime:  time.Unix(1604232000, 0),
+		EndTime:    time.Unix(1604232000, 5000000),
+		Attributes: []Field{F("db.statement", "select 1"), F("rows", 3), F("cached", false)},
+		Err:        errors.New("no connection"),
+	}
+	span.Context.TraceID[15] = 1
+	span.Context.SpanID[7] = 2
+	span.ParentID[7] = 3
+	want := "{\"resourceSpans\":[{\"resource\":{\"attributes\":[{\"key\":\"service.name\",\"value\":{\"stringValue\":\"hashbang\"}}]},\"scopeSpans\":[{\"scope\":{\"name\":\"github.com/arctair/hashbang/v1\"},\"spans\":[{\"attributes\":[{\"key\":\"db.statement\",\"value\":{\"stringValue\":\"select 1\"}},{\"key\":\"rows\",\"value\":{\"intValue\":\"3\"}},{\"key\":\"cached\",\"value\":{\"boolValue\":false}}],\"endTimeUnixNano\":\"1604232000005000000\",\"kind\":3,\"name\":\"SELECT\",\"parentSpanId\":\"0000000000000003\",\"spanId\":\"0000000000000002\",\"startTimeUnixNano\":\"1604232000000000000\",\"status\":{\"code\":2,\"message\":\"no connection\"},\"traceId\":\"00000000000000000000000000000001\"}]}]}]}"
+
+	t.Run("append spans to a file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "traces")
+		assertutil.NotError(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "traces.jsonl")
+
+		exporter := NewFileSpanExporter("hashbang", path)
+		assertutil.NotError(t, exporter.Export([]*Span{span}))
+		assertutil.NotError(t, exporter.Export([]*Span{span}))
+
+		got, err := ioutil.ReadFile(path)
+		assertutil.NotError(t, err)
+		if string(got) != want+"\n"+want+"\n" {
+			t.Errorf("got %s want %s twice", got, want)
+		}
+	})
+
+	t.Run("post spans to a collector", func(t *testing.T) {
+		var got map[string]interface{}
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+		defer collector.Close()
+
+		assertutil.NotError(t, NewOTLPSpanExporter("hashbang", collector.URL).Export([]*Span{span}))
+
+		var wantDecoded map[string]interface{}
+		json.Unmarshal([]byte(want), &wantDecoded)
+		if !reflect.DeepEqual(got, wantDecoded) {
+			t.Errorf("got %v want %v", got, wantDecoded)
+		}
+
+		if err := NewOTLPSpanExporter("hashbang", collector.URL+"/wrong").Export([]*Span{span}); err == nil {
+			t.Error("got no error from a collector that refused the spans")
+		}
+	})
+}
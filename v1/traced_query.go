@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// tracedRows ends the span of the query that produced them once closed.
+type tracedRows struct {
+	pgx.Rows
+	span *Span
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	if r.span != nil {
+		r.span.End(r.Rows.Err())
+		r.span = nil
+	}
+}
+
+func startQuerySpan(ctx context.Context, sql string) (context.Context, *Span) {
+	return StartClientSpan(ctx, strings.ToUpper(strings.Fields(sql)[0]), F("db.system", "cockroachdb"), F("db.statement", sql))
+}
+
+// tracedQuery runs a query in a span of its own that lasts until the rows are
+// closed.
+func tracedQuery(ctx context.Context, q queryer, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := startQuerySpan(ctx, sql)
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+	return &tracedRows{rows, span}, nil
+}
+
+func tracedExec(ctx context.Context, tx pgx.Tx, sql string, args ...interface{}) error {
+	ctx, span := startQuerySpan(ctx, sql)
+	_, err := tx.Exec(ctx, sql, args...)
+	span.End(err)
+	return err
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type transactionKey struct{}
+
+// transaction is the attempt at a unit of work in progress, along with what
+// to do once it commits.
+type transaction struct {
+	tx          pgx.Tx
+	afterCommit []func()
+}
+
+func transactionFrom(ctx context.Context) *transaction {
+	t, _ := ctx.Value(transactionKey{}).(*transaction)
+	return t
+}
+
+// executeTx runs fn in a transaction on conn, starting fn over whenever
+// CockroachDB reports a serialization failure. Work fn hands to afterCommit
+// runs once, after the commit that counts.
+func executeTx(ctx context.Context, conn crdbpgx.Conn, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	var attempt *transaction
+	err := crdbpgx.ExecuteTx(ctx, conn, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		attempt = &transaction{tx: tx}
+		return fn(context.WithValue(ctx, transactionKey{}, attempt), tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, each := range attempt.afterCommit {
+		each()
+	}
+	return nil
+}
+
+// afterCommit defers fn until the transaction in ctx commits, or runs it now
+// when there is none.
+func afterCommit(ctx context.Context, fn func()) {
+	if t := transactionFrom(ctx); t != nil {
+		t.afterCommit = append(t.afterCommit, fn)
+	} else {
+		fn()
+	}
+}
+
+// UnitOfWork lets a service make several repository calls all or nothing.
+// Repository calls made with the context Do hands to fn share one
+// transaction, and fn may run more than once when CockroachDB asks for a
+// retry, so it should not have effects outside the database.
+// Savepoint, called within Do, runs fn so that should it fail only its own
+// writes are undone. fn's error comes back as failed; err is set when the
+// writes could not be undone, after which the unit of work must give up.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	Savepoint(ctx context.Context, fn func(ctx context.Context) error) (failed error, err error)
+}
+
+type unitOfWork struct {
+	pool     *pgxpool.Pool
+	timeouts Timeouts
+}
+
+// Do holds its connection for as long as the UnitOfWork timeout allows.
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactionFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	ctx, span := StartSpan(ctx, "UnitOfWork.Do")
+	err := withConn(ctx, u.pool, u.timeouts, "UnitOfWork", func(ctx context.Context, conn *pgxpool.Conn) error {
+		return executeTx(ctx, conn, func(ctx context.Context, tx pgx.Tx) error {
+			return fn(ctx)
+		})
+	})
+	span.End(err)
+	return err
+}
+
+func (u *unitOfWork) Savepoint(ctx context.Context, fn func(ctx context.Context) error) (error, error) {
+	parent := transactionFrom(ctx)
+	if parent == nil {
+		return fn(ctx), nil
+	}
+
+	savepoint, err := parent.tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	child := &transaction{tx: savepoint}
+	if failed := fn(context.WithValue(ctx, transactionKey{}, child)); failed != nil {
+		return failed, savepoint.Rollback(ctx)
+	}
+	if err = savepoint.Commit(ctx); err != nil {
+		return nil, err
+	}
+	parent.afterCommit = append(parent.afterCommit, child.afterCommit...)
+	return nil, nil
+}
+
+// NewUnitOfWork ...
+func NewUnitOfWork(pool *pgxpool.Pool, timeouts Timeouts) UnitOfWork {
+	return &unitOfWork{
+		pool:     pool,
+		timeouts: timeouts,
+	}
+}
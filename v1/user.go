@@ -0,0 +1,45 @@
+package v1
+
+import "time"
+
+// Bucket member roles. An owner may invite and revoke members, an editor may
+// change the bucket's contents and a viewer may only read them.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+var rolePermissions = map[string]string{
+	RoleViewer: PermissionRead,
+	RoleEditor: PermissionWrite,
+	RoleOwner:  PermissionAdmin,
+}
+
+// User ...
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Session ...
+type Session struct {
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreatedSession carries the session token back to the caller exactly once.
+type CreatedSession struct {
+	Session
+	Token string `json:"token"`
+}
+
+// BucketMember ...
+type BucketMember struct {
+	Bucket string `json:"bucket"`
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
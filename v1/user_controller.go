@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// UserController ...
+type UserController interface {
+	Register() http.Handler
+	Login() http.Handler
+	Logout() http.Handler
+}
+
+type userController struct {
+	logger      Logger
+	userService UserService
+}
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (c *userController) Register() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			var body credentials
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if user, err := c.userService.Register(r.Context(), body.Email, body.Password); errors.Is(err, ErrInvalidUser) {
+				writeBadRequest(rw, err.Error())
+			} else if errors.Is(err, ErrUserExists) {
+				writeError(rw, http.StatusConflict, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(user)
+			}
+		},
+	)
+}
+
+func (c *userController) Login() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			var body credentials
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+			} else if session, err := c.userService.Login(r.Context(), body.Email, body.Password); errors.Is(err, ErrInvalidCredentials) {
+				writeError(rw, http.StatusUnauthorized, err.Error())
+			} else if err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusCreated)
+				json.NewEncoder(rw).Encode(session)
+			}
+		},
+	)
+}
+
+func (c *userController) Logout() http.Handler {
+	return http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !strings.HasPrefix(token, sessionPrefix) {
+				writeBadRequest(rw, "only session tokens can be logged out")
+			} else if err := c.userService.Logout(r.Context(), token); err != nil {
+				writeServerError(rw, r, c.logger, err)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		},
+	)
+}
+
+// NewUserController ...
+func NewUserController(
+	logger Logger,
+	userService UserService,
+) UserController {
+	return &userController{
+		logger,
+		userService,
+	}
+}
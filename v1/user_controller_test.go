@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubUserService struct {
+	withEmail    string
+	withPassword string
+	withToken    string
+	willError    error
+
+	loggedOut string
+}
+
+func (s *stubUserService) Register(ctx context.Context, email string, password string) (*User, error) {
+	if s.willError != nil {
+		return nil, s.willError
+	}
+	return &User{ID: "1", Email: email, PasswordHash: "hash"}, nil
+}
+
+func (s *stubUserService) Login(ctx context.Context, email string, password string) (*CreatedSession, error) {
+	if email != s.withEmail || password != s.withPassword {
+		return nil, ErrInvalidCredentials
+	}
+	return &CreatedSession{Session{"1", time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)}, "hs_secret"}, nil
+}
+
+func (s *stubUserService) Authenticate(ctx context.Context, token string) (*User, error) {
+	return nil, ErrUnknownSession
+}
+
+func (s *stubUserService) Logout(ctx context.Context, token string) error {
+	s.loggedOut = token
+	return s.willError
+}
+
+func TestUserController(t *testing.T) {
+	t.Run("POST /users", func(t *testing.T) {
+		controller := NewUserController(stubLoggerNew(), &stubUserService{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/users", strings.NewReader("{\"email\":\"someone@example.com\",\"password\":\"correct horse\"}"))
+		response := httptest.NewRecorder()
+		controller.Register().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := response.Body.String()
+		wantBody := "{\"id\":\"1\",\"email\":\"someone@example.com\",\"createdAt\":\"0001-01-01T00:00:00Z\"}\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("POST /users when email is taken", func(t *testing.T) {
+		controller := NewUserController(stubLoggerNew(), &stubUserService{willError: ErrUserExists})
+
+		request, _ := http.NewRequest(http.MethodPost, "/users", strings.NewReader("{}"))
+		response := httptest.NewRecorder()
+		controller.Register().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 409
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("POST /sessions", func(t *testing.T) {
+		controller := NewUserController(stubLoggerNew(), &stubUserService{withEmail: "someone@example.com", withPassword: "correct horse"})
+
+		request, _ := http.NewRequest(http.MethodPost, "/sessions", strings.NewReader("{\"email\":\"someone@example.com\",\"password\":\"correct horse\"}"))
+		response := httptest.NewRecorder()
+		controller.Login().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 201
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		gotBody := response.Body.String()
+		wantBody := "{\"userId\":\"1\",\"expiresAt\":\"2020-12-01T00:00:00Z\",\"token\":\"hs_secret\"}\n"
+
+		if gotBody != wantBody {
+			t.Errorf("got body %q want %q", gotBody, wantBody)
+		}
+	})
+
+	t.Run("POST /sessions with wrong password", func(t *testing.T) {
+		controller := NewUserController(stubLoggerNew(), &stubUserService{withEmail: "someone@example.com", withPassword: "correct horse"})
+
+		request, _ := http.NewRequest(http.MethodPost, "/sessions", strings.NewReader("{\"email\":\"someone@example.com\",\"password\":\"wrong horse\"}"))
+		response := httptest.NewRecorder()
+		controller.Login().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 401
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+	})
+
+	t.Run("DELETE /sessions", func(t *testing.T) {
+		service := &stubUserService{}
+		controller := NewUserController(stubLoggerNew(), service)
+
+		request, _ := http.NewRequest(http.MethodDelete, "/sessions", nil)
+		request.Header.Set("Authorization", "Bearer hs_secret")
+		response := httptest.NewRecorder()
+		controller.Logout().ServeHTTP(response, request)
+
+		gotStatusCode := response.Result().StatusCode
+		wantStatusCode := 204
+
+		if gotStatusCode != wantStatusCode {
+			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
+		}
+
+		if service.loggedOut != "hs_secret" {
+			t.Errorf("got logged out %s want hs_secret", service.loggedOut)
+		}
+	})
+}
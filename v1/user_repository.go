@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// UserRepository ...
+type UserRepository interface {
+	FindByID(ctx context.Context, id string) (*User, error)
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	Create(ctx context.Context, user User) error
+	FindSession(ctx context.Context, hash string) (*Session, error)
+	CreateSession(ctx context.Context, session Session, hash string) error
+	DeleteSession(ctx context.Context, hash string) error
+}
+
+type userRepository struct {
+	db database
+}
+
+func (r *userRepository) findOne(ctx context.Context, operation string, where string, value string) (*User, error) {
+	var user User
+	err := r.db.queryRow(
+		ctx,
+		operation,
+		"select \"id\", \"email\", \"password_hash\", \"created_at\" from users where "+where+" = $1",
+		value,
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	return r.findOne(ctx, "FindByID", "\"id\"", id)
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return r.findOne(ctx, "FindByEmail", "\"email\"", email)
+}
+
+// Create reports ErrUserExists when the email is taken, even by a user
+// created since the caller last looked.
+func (r *userRepository) Create(ctx context.Context, user User) error {
+	var id string
+	err := r.db.queryRow(
+		ctx,
+		"Create",
+		"insert into users (\"id\", \"email\", \"password_hash\", \"created_at\") values ($1, $2, $3, $4) on conflict (\"email\") do nothing returning \"id\"",
+		user.ID,
+		user.Email,
+		user.PasswordHash,
+		user.CreatedAt,
+	).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return ErrUserExists
+	}
+	return err
+}
+
+func (r *userRepository) FindSession(ctx context.Context, hash string) (*Session, error) {
+	var session Session
+	err := r.db.queryRow(
+		ctx,
+		"FindSession",
+		"select \"user_id\", \"expires_at\" from sessions where \"hash\" = $1 and \"expires_at\" > $2",
+		hash,
+		time.Now(),
+	).Scan(&session.UserID, &session.ExpiresAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userRepository) CreateSession(ctx context.Context, session Session, hash string) error {
+	return r.db.exec(
+		ctx,
+		"CreateSession",
+		"insert into sessions (\"hash\", \"user_id\", \"expires_at\") values ($1, $2, $3)",
+		hash,
+		session.UserID,
+		session.ExpiresAt,
+	)
+}
+
+func (r *userRepository) DeleteSession(ctx context.Context, hash string) error {
+	return r.db.exec(
+		ctx,
+		"DeleteSession",
+		"delete from sessions where \"hash\" = $1",
+		hash,
+	)
+}
+
+// NewUserRepository ...
+func NewUserRepository(pool *pgxpool.Pool, timeouts Timeouts) UserRepository {
+	return &userRepository{database{pool, timeouts}}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arctair/go-assertutil"
+	"github.com/cockroachdb/cockroach-go/v2/testserver"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func TestUserRepository(t *testing.T) {
+	testServer, err := testserver.NewTestServer()
+	defer testServer.Stop()
+	assertutil.NotError(t, err)
+
+	pool, err := pgxpool.Connect(context.Background(), testServer.PGURL().String())
+	assertutil.NotError(t, err)
+	assertutil.NotError(t, Migrate(pool, stubLoggerNew()))
+
+	user := User{
+		ID:           "7fe6ca35-d868-48a9-94d4-6e7f7db450ea",
+		Email:        "someone@example.com",
+		PasswordHash: "hash",
+		CreatedAt:    time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("create user", func(t *testing.T) {
+		assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).Create(context.Background(), user))
+
+		for _, find := range []func() (*User, error){
+			func() (*User, error) {
+				return NewUserRepository(pool, Timeouts{}).FindByID(context.Background(), user.ID)
+			},
+			func() (*User, error) {
+				return NewUserRepository(pool, Timeouts{}).FindByEmail(context.Background(), user.Email)
+			},
+		} {
+			got, err := find()
+			assertutil.NotError(t, err)
+			got.CreatedAt = got.CreatedAt.UTC()
+
+			if !reflect.DeepEqual(*got, user) {
+				t.Errorf("got %+v want %+v", got, user)
+			}
+		}
+	})
+
+	t.Run("create a user with a taken email", func(t *testing.T) {
+		other := user
+		other.ID = "5d2c1b0a-9e8f-4a7b-8c6d-5e4f3a2b1c0d"
+
+		if gotErr := NewUserRepository(pool, Timeouts{}).Create(context.Background(), other); gotErr != ErrUserExists {
+			t.Errorf("got error %v want %v", gotErr, ErrUserExists)
+		}
+	})
+
+	t.Run("create, find and delete session", func(t *testing.T) {
+		session := Session{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)}
+		expired := Session{UserID: user.ID, ExpiresAt: time.Now().Add(-time.Hour)}
+		assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).CreateSession(context.Background(), session, "live"))
+		assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).CreateSession(context.Background(), expired, "expired"))
+
+		got, err := NewUserRepository(pool, Timeouts{}).FindSession(context.Background(), "live")
+		assertutil.NotError(t, err)
+		got.ExpiresAt = got.ExpiresAt.UTC()
+
+		if !reflect.DeepEqual(*got, session) {
+			t.Errorf("got %+v want %+v", got, session)
+		}
+
+		for _, hash := range []string{"expired", "unknown"} {
+			got, err = NewUserRepository(pool, Timeouts{}).FindSession(context.Background(), hash)
+			assertutil.NotError(t, err)
+
+			if got != nil {
+				t.Errorf("got %+v for %s want nil", got, hash)
+			}
+		}
+
+		assertutil.NotError(t, NewUserRepository(pool, Timeouts{}).DeleteSession(context.Background(), "live"))
+		got, err = NewUserRepository(pool, Timeouts{}).FindSession(context.Background(), "live")
+		assertutil.NotError(t, err)
+
+		if got != nil {
+			t.Errorf("got %+v want nil", got)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidUser ...
+	ErrInvalidUser = errors.New("invalid user")
+	// ErrUserExists ...
+	ErrUserExists = errors.New("a user with that email already exists")
+	// ErrInvalidCredentials ...
+	ErrInvalidCredentials = errors.New("email or password is incorrect")
+	// ErrUnknownSession ...
+	ErrUnknownSession = errors.New("unknown or expired session")
+)
+
+const (
+	sessionPrefix   = "hs_"
+	sessionLifetime = 30 * 24 * time.Hour
+)
+
+// UserService ...
+type UserService interface {
+	Register(ctx context.Context, email string, password string) (*User, error)
+	Login(ctx context.Context, email string, password string) (*CreatedSession, error)
+	Authenticate(ctx context.Context, token string) (*User, error)
+	Logout(ctx context.Context, token string) error
+}
+
+type userService struct {
+	userRepository UserRepository
+	uuidGenerator  UUIDGenerator
+	passwordCost   int
+}
+
+func (s *userService) Register(ctx context.Context, email string, password string) (*User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: email must be an email address", ErrInvalidUser)
+	}
+	// bcrypt only looks at the first 72 bytes of a password.
+	if len(password) < 8 || len(password) > 72 {
+		return nil, fmt.Errorf("%w: password must be between 8 and 72 bytes", ErrInvalidUser)
+	}
+
+	// Checking first spares hashing a password for a taken email; Create
+	// still reports one taken in the meantime.
+	existing, err := s.userRepository.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), s.passwordCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{
+		ID:           s.uuidGenerator.Generate(),
+		Email:        email,
+		PasswordHash: string(passwordHash),
+		CreatedAt:    time.Now().UTC(),
+	}
+	return &user, s.userRepository.Create(ctx, user)
+}
+
+func (s *userService) Login(ctx context.Context, email string, password string) (*CreatedSession, error) {
+	user, err := s.userRepository.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	} else if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	token, err := randomToken(sessionPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	session := Session{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(sessionLifetime),
+	}
+	if err = s.userRepository.CreateSession(ctx, session, hashToken(token)); err != nil {
+		return nil, err
+	}
+	return &CreatedSession{session, token}, nil
+}
+
+func (s *userService) Authenticate(ctx context.Context, token string) (*User, error) {
+	if !strings.HasPrefix(token, sessionPrefix) {
+		return nil, ErrUnknownSession
+	}
+
+	session, err := s.userRepository.FindSession(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	} else if session == nil {
+		return nil, ErrUnknownSession
+	}
+
+	user, err := s.userRepository.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, ErrUnknownSession
+	}
+	return user, nil
+}
+
+func (s *userService) Logout(ctx context.Context, token string) error {
+	return s.userRepository.DeleteSession(ctx, hashToken(token))
+}
+
+// NewUserService ...
+func NewUserService(
+	userRepository UserRepository,
+	uuidGenerator UUIDGenerator,
+) UserService {
+	return &userService{
+		userRepository,
+		uuidGenerator,
+		bcrypt.DefaultCost,
+	}
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type stubUserRepositoryForService struct {
+	UserRepository
+
+	users    map[string]User
+	sessions map[string]Session
+
+	takenOnCreate bool
+}
+
+func stubUserRepositoryNew() *stubUserRepositoryForService {
+	return &stubUserRepositoryForService{users: map[string]User{}, sessions: map[string]Session{}}
+}
+
+func (r *stubUserRepositoryForService) FindByID(ctx context.Context, id string) (*User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubUserRepositoryForService) FindByEmail(ctx context.Context, email string) (*User, error) {
+	if user, ok := r.users[email]; ok {
+		return &user, nil
+	}
+	return nil, nil
+}
+
+func (r *stubUserRepositoryForService) Create(ctx context.Context, user User) error {
+	if _, ok := r.users[user.Email]; ok || r.takenOnCreate {
+		return ErrUserExists
+	}
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *stubUserRepositoryForService) FindSession(ctx context.Context, hash string) (*Session, error) {
+	if session, ok := r.sessions[hash]; ok {
+		return &session, nil
+	}
+	return nil, nil
+}
+
+func (r *stubUserRepositoryForService) CreateSession(ctx context.Context, session Session, hash string) error {
+	r.sessions[hash] = session
+	return nil
+}
+
+func (r *stubUserRepositoryForService) DeleteSession(ctx context.Context, hash string) error {
+	delete(r.sessions, hash)
+	return nil
+}
+
+func TestUserService(t *testing.T) {
+	t.Run("register then log in and out", func(t *testing.T) {
+		repository := stubUserRepositoryNew()
+		service := &userService{repository, &stubUUIDGenerator{response: "1"}, bcrypt.MinCost}
+
+		user, err := service.Register(context.Background(), " Someone@Example.com ", "correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if user.ID != "1" || user.Email != "someone@example.com" || strings.Contains(user.PasswordHash, "correct horse") {
+			t.Errorf("got user %+v want id 1 with a hashed password", user)
+		}
+
+		session, err := service.Login(context.Background(), "someone@example.com", "correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(session.Token, "hs_") || session.UserID != "1" || session.ExpiresAt.Before(time.Now()) {
+			t.Errorf("got session %+v want a live hs_ token for user 1", session)
+		}
+
+		got, err := service.Authenticate(context.Background(), session.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.ID != "1" {
+			t.Errorf("got user %+v want user 1", got)
+		}
+
+		if err = service.Logout(context.Background(), session.Token); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, gotErr := service.Authenticate(context.Background(), session.Token); gotErr != ErrUnknownSession {
+			t.Errorf("got error %v want %v", gotErr, ErrUnknownSession)
+		}
+	})
+
+	t.Run("register invalid", func(t *testing.T) {
+		repository := stubUserRepositoryNew()
+		repository.users["taken@example.com"] = User{ID: "1", Email: "taken@example.com"}
+
+		for _, scenario := range []struct {
+			email    string
+			password string
+			wantErr  string
+		}{
+			{"someone", "correct horse", "invalid user: email must be an email address"},
+			{"someone@example.com", "short", "invalid user: password must be between 8 and 72 bytes"},
+			{"taken@example.com", "correct horse", "a user with that email already exists"},
+		} {
+			service := &userService{repository, &stubUUIDGenerator{}, bcrypt.MinCost}
+
+			_, gotErr := service.Register(context.Background(), scenario.email, scenario.password)
+
+			if gotErr == nil || gotErr.Error() != scenario.wantErr {
+				t.Errorf("got error %v want %s", gotErr, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("register an email taken since it was checked", func(t *testing.T) {
+		repository := stubUserRepositoryNew()
+		repository.takenOnCreate = true
+		service := &userService{repository, &stubUUIDGenerator{response: "1"}, bcrypt.MinCost}
+
+		if _, gotErr := service.Register(context.Background(), "someone@example.com", "correct horse"); gotErr != ErrUserExists {
+			t.Errorf("got error %v want %v", gotErr, ErrUserExists)
+		}
+	})
+
+	t.Run("log in with wrong password", func(t *testing.T) {
+		service := &userService{stubUserRepositoryNew(), &stubUUIDGenerator{response: "1"}, bcrypt.MinCost}
+		if _, err := service.Register(context.Background(), "someone@example.com", "correct horse"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, email := range []string{"someone@example.com", "nobody@example.com"} {
+			if _, gotErr := service.Login(context.Background(), email, "wrong horse"); gotErr != ErrInvalidCredentials {
+				t.Errorf("got error %v for %s want %v", gotErr, email, ErrInvalidCredentials)
+			}
+		}
+	})
+}
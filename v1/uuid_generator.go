@@ -1,19 +1,129 @@
 package v1
 
-import uuid "github.com/google/uuid"
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	uuid "github.com/google/uuid"
+)
+
+// ErrInvalidID ...
+var ErrInvalidID = errors.New("id must be a uuid")
+
+// ParseID accepts any form of UUID google/uuid does and returns it in the
+// canonical lowercase form the database stores.
+func ParseID(id string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidID, id)
+	}
+	return parsed.String(), nil
+}
 
 // UUIDGenerator ...
 type UUIDGenerator interface {
 	Generate() string
 }
 
+// ID strategies for NewUUIDGeneratorFor. V7 and ULID IDs start with the
+// millisecond they were made in, so they sort in the order they were made.
+const (
+	IDStrategyV4   = "v4"
+	IDStrategyV7   = "v7"
+	IDStrategyULID = "ulid"
+)
+
 type uuidGenerator struct{}
 
 func (g *uuidGenerator) Generate() string {
 	return uuid.New().String()
 }
 
-// NewUUIDGenerator ...
+// timeOrderedGenerator makes IDs whose first 48 bits are a Unix millisecond
+// timestamp. IDs made in the same millisecond count up from the last one so
+// that they still sort in the order they were made.
+type timeOrderedGenerator struct {
+	now     func() time.Time
+	random  io.Reader
+	version byte
+
+	mutex  sync.Mutex
+	lastMs int64
+	last   [16]byte
+}
+
+func (g *timeOrderedGenerator) Generate() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ms := g.now().UnixNano() / int64(time.Millisecond)
+	if ms > g.lastMs || !g.increment() {
+		if ms <= g.lastMs {
+			ms = g.lastMs + 1
+		}
+		g.fresh(ms)
+	}
+	return uuid.UUID(g.last).String()
+}
+
+// fresh starts a new millisecond with random bits. A v7 ID leaves the top bit
+// of its 12-bit counter clear so that there is room to count up. Like
+// uuid.New, it panics when there are no random bits to be had.
+func (g *timeOrderedGenerator) fresh(ms int64) {
+	g.lastMs = ms
+	if _, err := io.ReadFull(g.random, g.last[6:]); err != nil {
+		panic(err)
+	}
+	for i := 0; i < 6; i++ {
+		g.last[i] = byte(ms >> (40 - 8*i))
+	}
+	if g.version == 7 {
+		g.last[6] = 0x70 | g.last[6]&0x07
+		g.last[8] = 0x80 | g.last[8]&0x3f
+	}
+}
+
+// increment counts up from the last ID, in the 12-bit counter of a v7 ID or
+// the 80 random bits of a ULID, and reports false when the count overflows.
+func (g *timeOrderedGenerator) increment() bool {
+	if g.version == 7 {
+		counter := uint16(g.last[6]&0x0f)<<8 | uint16(g.last[7])
+		if counter == 0x0fff {
+			return false
+		}
+		counter++
+		g.last[6] = 0x70 | byte(counter>>8)
+		g.last[7] = byte(counter)
+		return true
+	}
+	for i := 15; i >= 6; i-- {
+		g.last[i]++
+		if g.last[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NewUUIDGenerator makes random version 4 UUIDs.
 func NewUUIDGenerator() UUIDGenerator {
 	return &uuidGenerator{}
 }
+
+// NewUUIDGeneratorFor makes IDs by strategy. ULIDs come out in UUID form so
+// that they fit the same columns.
+func NewUUIDGeneratorFor(strategy string) (UUIDGenerator, error) {
+	switch strategy {
+	case IDStrategyV4:
+		return NewUUIDGenerator(), nil
+	case IDStrategyV7:
+		return &timeOrderedGenerator{now: time.Now, random: rand.Reader, version: 7}, nil
+	case IDStrategyULID:
+		return &timeOrderedGenerator{now: time.Now, random: rand.Reader}, nil
+	}
+	return nil, fmt.Errorf("id strategy must be one of %s, %s or %s, not %q", IDStrategyV4, IDStrategyV7, IDStrategyULID, strategy)
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUUIDGenerator(t *testing.T) {
+	t.Run("parse id", func(t *testing.T) {
+		for _, scenario := range []struct {
+			id      string
+			want    string
+			wantErr error
+		}{
+			{"3E99AA77-615E-4A55-930D-D4C77CFD1B72", "3e99aa77-615e-4a55-930d-d4c77cfd1b72", nil},
+			{"urn:uuid:3e99aa77-615e-4a55-930d-d4c77cfd1b72", "3e99aa77-615e-4a55-930d-d4c77cfd1b72", nil},
+			{"1", "", ErrInvalidID},
+			{"", "", ErrInvalidID},
+		} {
+			got, err := ParseID(scenario.id)
+			if got != scenario.want || !errors.Is(err, scenario.wantErr) {
+				t.Errorf("%q: got %q and error %v want %q and %v", scenario.id, got, err, scenario.want, scenario.wantErr)
+			}
+		}
+	})
+
+	t.Run("panic without random bits", func(t *testing.T) {
+		generator := &timeOrderedGenerator{now: time.Now, random: bytes.NewReader(nil), version: 7}
+		defer func() {
+			if recover() == nil {
+				t.Error("got an id want a panic")
+			}
+		}()
+		generator.Generate()
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := NewUUIDGeneratorFor("v1")
+		if err == nil || err.Error() != "id strategy must be one of v4, v7 or ulid, not \"v1\"" {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	for _, version := range []byte{7, 0} {
+		now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+		generator := &timeOrderedGenerator{
+			now:     func() time.Time { return now },
+			random:  bytes.NewReader(bytes.Repeat([]byte{0xff}, 1<<16)),
+			version: version,
+		}
+
+		ids := []string{}
+		for i := 0; i < 5000; i++ {
+			if i == 4000 {
+				now = now.Add(-time.Second)
+			}
+			ids = append(ids, generator.Generate())
+		}
+
+		if !sort.StringsAreSorted(ids) {
+			t.Errorf("version %d: got ids out of order", version)
+		}
+		seen := map[string]bool{}
+		for _, id := range ids {
+			parsed, err := uuid.Parse(id)
+			if err != nil || seen[id] {
+				t.Fatalf("version %d: got id %s repeated or unparseable: %v", version, id, err)
+			}
+			seen[id] = true
+			if version == 7 && (parsed.Version() != 7 || parsed.Variant() != uuid.RFC4122) {
+				t.Errorf("got id %s with version %d and variant %s", id, parsed.Version(), parsed.Variant())
+			}
+		}
+
+		wantPrefix := "017583ad-b200"
+		if got := ids[0][:13]; got != wantPrefix {
+			t.Errorf("version %d: got prefix %s want %s", version, got, wantPrefix)
+		}
+	}
+}
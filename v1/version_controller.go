@@ -1,34 +1,55 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 // VersionController ...
 type VersionController interface {
 	HandlerFunc() http.Handler
+	GetBuildInfo() http.Handler
+}
+
+// BuildInfo is what /version reports. The sha1 and version keys predate the
+// rest and must keep their names.
+type BuildInfo struct {
+	Sha1          string    `json:"sha1"`
+	Version       string    `json:"version"`
+	BuildTime     string    `json:"buildTime,omitempty"`
+	GoVersion     string    `json:"goVersion"`
+	Dirty         bool      `json:"dirty"`
+	StartTime     time.Time `json:"startTime"`
+	UptimeSeconds float64   `json:"uptimeSeconds"`
+	SchemaVersion *int      `json:"schemaVersion"`
+}
+
+// DebugBuildInfo adds the main module and every dependency's version.
+type DebugBuildInfo struct {
+	BuildInfo
+	Path string       `json:"path"`
+	Deps []Dependency `json:"deps"`
 }
 
 type versionController struct {
-	build *Build
+	build         *Build
+	schemaVersion func(ctx context.Context) (int, error)
+	now           func() time.Time
 }
 
-// NewVersionController ...
-func NewVersionController(b *Build) VersionController {
-	return &versionController{b}
+// NewVersionController reads the schema version with schemaVersion on every
+// request.
+func NewVersionController(b *Build, schemaVersion func(ctx context.Context) (int, error)) VersionController {
+	return &versionController{b, schemaVersion, time.Now}
 }
 
 // HandlerFunc ...
 func (c *versionController) HandlerFunc() http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			bytes, err := json.Marshal(
-				map[string]string{
-					"sha1":    c.build.getSha1(),
-					"version": c.build.getVersion(),
-				},
-			)
+			bytes, err := json.Marshal(c.buildInfo(r.Context()))
 			if err != nil {
 				panic(err)
 			}
@@ -36,3 +57,34 @@ func (c *versionController) HandlerFunc() http.Handler {
 		},
 	)
 }
+
+func (c *versionController) GetBuildInfo() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(DebugBuildInfo{
+				c.buildInfo(r.Context()),
+				c.build.path,
+				c.build.deps,
+			})
+		},
+	)
+}
+
+// buildInfo leaves the schema version null when the database cannot say, so
+// that /version answers even while the database is down.
+func (c *versionController) buildInfo(ctx context.Context) BuildInfo {
+	info := BuildInfo{
+		Sha1:          c.build.getSha1(),
+		Version:       c.build.getVersion(),
+		BuildTime:     c.build.buildTime,
+		GoVersion:     c.build.goVersion,
+		Dirty:         c.build.dirty(),
+		StartTime:     c.build.startTime.UTC(),
+		UptimeSeconds: c.now().Sub(c.build.startTime).Seconds(),
+	}
+	if schemaVersion, err := c.schemaVersion(ctx); err == nil {
+		info.SchemaVersion = &schemaVersion
+	}
+	return info
+}
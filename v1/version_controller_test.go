@@ -1,20 +1,27 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestVersionController(t *testing.T) {
+	schemaVersion := func(ctx context.Context) (int, error) { return 19, nil }
+
 	t.Run("GET returns version and sha1", func(t *testing.T) {
 		versionController := NewVersionController(
 			NewBuild(
 				"oogabooga",
 				"boogaooga",
+				"2020-11-01T12:00:00Z",
 			),
+			schemaVersion,
 		)
 
 		request, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -28,18 +35,87 @@ func TestVersionController(t *testing.T) {
 			t.Errorf("got status code %d want %d", gotStatusCode, wantStatusCode)
 		}
 
-		var gotBody map[string]string
+		var gotBody map[string]interface{}
 		if err := json.NewDecoder(response.Body).Decode(&gotBody); err != nil {
 			t.Fatal(err)
 		}
 
-		wantBody := map[string]string{
-			"sha1":    "oogabooga",
-			"version": "boogaooga",
+		wantBody := map[string]interface{}{
+			"sha1":          "oogabooga",
+			"version":       "boogaooga",
+			"buildTime":     "2020-11-01T12:00:00Z",
+			"dirty":         false,
+			"schemaVersion": float64(19),
+		}
+
+		for key, want := range wantBody {
+			if got := gotBody[key]; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s %v want %v", key, got, want)
+			}
+		}
+		for _, key := range []string{"goVersion", "startTime", "uptimeSeconds"} {
+			if _, ok := gotBody[key]; !ok {
+				t.Errorf("got no %s", key)
+			}
+		}
+	})
+
+	t.Run("GET reports dirty builds and uptime", func(t *testing.T) {
+		build := NewBuild("oogabooga", "oogabooga-dirty", "")
+		versionController := NewVersionController(build, schemaVersion).(*versionController)
+		versionController.now = func() time.Time { return build.startTime.Add(90 * time.Second) }
+
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		response := httptest.NewRecorder()
+		versionController.HandlerFunc().ServeHTTP(response, request)
+
+		var got BuildInfo
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Dirty || got.UptimeSeconds != 90 {
+			t.Errorf("got dirty %t uptime %f want true 90", got.Dirty, got.UptimeSeconds)
+		}
+	})
+
+	t.Run("GET when the schema version is unavailable", func(t *testing.T) {
+		versionController := NewVersionController(
+			NewBuild("oogabooga", "boogaooga", ""),
+			func(ctx context.Context) (int, error) { return 0, errors.New("connection refused") },
+		)
+
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		response := httptest.NewRecorder()
+		versionController.HandlerFunc().ServeHTTP(response, request)
+
+		var gotBody map[string]interface{}
+		if err := json.NewDecoder(response.Body).Decode(&gotBody); err != nil {
+			t.Fatal(err)
 		}
+		if got, ok := gotBody["schemaVersion"]; !ok || got != nil {
+			t.Errorf("got schemaVersion %v want null", got)
+		}
+		if gotBody["sha1"] != "oogabooga" {
+			t.Errorf("got sha1 %v want oogabooga", gotBody["sha1"])
+		}
+	})
+
+	t.Run("GET build info lists dependencies", func(t *testing.T) {
+		build := NewBuild("oogabooga", "boogaooga", "")
+		build.path = "github.com/arctair/hashbang"
+		build.deps = []Dependency{{Path: "github.com/jackc/pgx/v4", Version: "v4.9.2", Sum: "h1:abc="}}
+		versionController := NewVersionController(build, schemaVersion)
 
-		if !reflect.DeepEqual(gotBody, wantBody) {
-			t.Errorf("got body %q want %q", gotBody, wantBody)
+		request, _ := http.NewRequest(http.MethodGet, "/debug/buildinfo", nil)
+		response := httptest.NewRecorder()
+		versionController.GetBuildInfo().ServeHTTP(response, request)
+
+		var got DebugBuildInfo
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Sha1 != "oogabooga" || got.Path != build.path || !reflect.DeepEqual(got.Deps, build.deps) {
+			t.Errorf("got %+v want sha1 oogabooga, path %s and deps %+v", got, build.path, build.deps)
 		}
 	})
 }